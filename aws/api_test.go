@@ -213,6 +213,66 @@ func TestBuildAccessRdfGraph(t *testing.T) {
 	compareResources(t, g, resources, expected, expectedChildren, expectedAppliedOn)
 }
 
+func TestRebuildUserRelationsWithoutFetchingGroupsAndPolicies(t *testing.T) {
+	managedPolicies := []*iam.ManagedPolicyDetail{
+		{PolicyId: awssdk.String("managed_policy_1"), PolicyName: awssdk.String("nmanaged_policy_1")},
+		{PolicyId: awssdk.String("managed_policy_2"), PolicyName: awssdk.String("nmanaged_policy_2")},
+	}
+	groups := []*iam.GroupDetail{
+		{GroupId: awssdk.String("group_1"), GroupName: awssdk.String("ngroup_1"), AttachedManagedPolicies: []*iam.AttachedPolicy{{PolicyName: awssdk.String("nmanaged_policy_1")}}},
+		{GroupId: awssdk.String("group_2"), GroupName: awssdk.String("ngroup_2")},
+	}
+	mock := &mockIam{groupdetails: groups, managedpolicydetails: managedPolicies,
+		userdetails: []*iam.UserDetail{
+			{UserId: awssdk.String("usr_1"), GroupList: []*string{awssdk.String("ngroup_1")}, AttachedManagedPolicies: []*iam.AttachedPolicy{{PolicyName: awssdk.String("nmanaged_policy_1")}}},
+		},
+		users: []*iam.User{{UserId: awssdk.String("usr_1")}},
+	}
+	access := Access{IAMAPI: mock, region: "eu-west-1"}
+
+	g, err := access.FetchResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.groupdetails, mock.managedpolicydetails = nil, nil
+	mock.userdetails = []*iam.UserDetail{
+		{UserId: awssdk.String("usr_1"), GroupList: []*string{awssdk.String("ngroup_2")}},
+		{UserId: awssdk.String("usr_2"), GroupList: []*string{awssdk.String("ngroup_1")}, AttachedManagedPolicies: []*iam.AttachedPolicy{{PolicyName: awssdk.String("nmanaged_policy_2")}}},
+	}
+	mock.users = []*iam.User{{UserId: awssdk.String("usr_1")}, {UserId: awssdk.String("usr_2")}}
+
+	users, build, err := access.FetchByTypeWithRelations("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.ReplaceResources("user", users)
+	if err := g.RebuildRelations("user", build); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAppliedOn := map[string][]string{
+		"group_1":          {"usr_2"},
+		"group_2":          {"usr_1"},
+		"managed_policy_1": {"group_1"},
+		"managed_policy_2": {"usr_2"},
+	}
+	resources, err := g.GetAllResources("policy", "group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(resources), 4; got != want {
+		t.Fatalf("got %d groups and policies, want %d", got, want)
+	}
+	for _, res := range resources {
+		appliedOn := mustGetAppliedOnId(g, res)
+		sort.Strings(appliedOn)
+		if got, want := appliedOn, expectedAppliedOn[res.Id()]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s applied on: got %v, want %v", res.Id(), got, want)
+		}
+	}
+}
+
 func TestBuildInfraRdfGraph(t *testing.T) {
 	now := time.Now().UTC()
 	instances := []*ec2.Instance{
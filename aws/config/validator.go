@@ -91,6 +91,18 @@ func isValidInstanceType(given string) bool {
 	return regexp.MustCompile("\\w+\\.\\w+").MatchString(given)
 }
 
+// PublicRegions returns the regions of the standard AWS partition, excluding
+// the China and GovCloud regions requiring their own accounts
+func PublicRegions() []string {
+	var regions sort.StringSlice
+	partition := endpoints.AwsPartition()
+	for id := range partition.Regions() {
+		regions = append(regions, id)
+	}
+	sort.Sort(regions)
+	return regions
+}
+
 func allRegions() []string {
 	var regions sort.StringSlice
 	partitions := endpoints.DefaultResolver().(endpoints.EnumPartitions).Partitions()
@@ -1,11 +1,37 @@
 package awsdoc
 
+import "strings"
+
 func TemplateParamsDoc(templateDef, param string) (string, bool) {
 	if doc, ok := manualParamsDoc[templateDef][param]; ok {
 		return doc, ok
 	}
-	doc, ok := generatedParamsDoc[templateDef][param]
-	return doc, ok
+	if doc, ok := generatedParamsDoc[templateDef][param]; ok {
+		return doc, ok
+	}
+	for _, action := range []string{"check", "wait"} {
+		if strings.HasPrefix(templateDef, action) {
+			doc, ok := genericCheckParamsDoc[action][param]
+			return doc, ok
+		}
+	}
+	return "", false
+}
+
+// genericCheckParamsDoc documents the check and wait actions available for any fetched resource
+var genericCheckParamsDoc = map[string]map[string]string{
+	"check": {
+		"id":       "The ID (or name) of the resource to check",
+		"state":    "The value of the checked property to reach (or 'not-found')",
+		"property": "The resource property to check (default: State)",
+		"timeout":  "The time (in seconds) after which the check is failed",
+	},
+	"wait": {
+		"id":       "The ID (or name) of the resource to wait for",
+		"state":    "The value of the property to reach (or 'not-found')",
+		"property": "The resource property to wait for (default: State)",
+		"timeout":  "The time (in seconds) after which the wait is failed (default: 300)",
+	},
 }
 
 var manualParamsDoc = map[string]map[string]string{
@@ -95,6 +121,7 @@ var manualParamsDoc = map[string]map[string]string{
 		"subnetgroup":       "A DB subnet group to associate with this DB instance",
 		"type":              "Contains the name of the compute and memory capacity class of the DB instance (db.t1.micro | db.m1.small | db.m1.medium | db.m1.large | db.m1.xlarge | db.m2.xlarge |db.m2.2xlarge | db.m2.4xlarge | db.m3.medium | db.m3.large | db.m3.xlarge | db.m3.2xlarge | db.m4.large | db.m4.xlarge | db.m4.2xlarge | db.m4.4xlarge | db.m4.10xlarge | db.r3.large | db.r3.xlarge | db.r3.2xlarge | db.r3.4xlarge | db.r3.8xlarge | db.t2.micro | db.t2.small | db.t2.medium | db.t2.large)",
 		"vpcsecuritygroups": "A list of EC2 VPC security groups to associate with this DB instance",
+		"wait":              "Set to 'true' (or a timeout in seconds) to wait for the database to be available before continuing",
 	},
 	"createdbsubnetgroup": {
 		"description": "The description for the DB subnet group",
@@ -114,6 +141,7 @@ var manualParamsDoc = map[string]map[string]string{
 		"origin-path":     "An optional element that causes CloudFront to request your content from a directory in your Amazon S3 bucket or your custom origin. When you include this element, specify the directory name, beginning with a /",
 		"price-class":     "The price class that corresponds with the maximum price that you want to pay for CloudFront service. If you specify PriceClass_All, CloudFront responds to requests for your objects from all CloudFront edge locations",
 		"min-ttl":         "The minimum amount of time that you want objects to stay in CloudFront caches before CloudFront forwards another request to your origin to determine whether the object has been updated",
+		"wait":            "Set to 'true' (or a timeout in seconds) to wait for the distribution to be deployed before continuing",
 	},
 	"createelasticip": {
 		"domain": "Set to vpc to allocate the address for use with instances in a VPC else the address is for use with instances in EC2-Classic (vpc | ec2-classic)",
@@ -133,6 +161,7 @@ var manualParamsDoc = map[string]map[string]string{
 		"name":  "The name of the instance to launch",
 		"role":  "The name of the instance profile (role) to launch the instance with",
 		"image": "The ID of the AMI of the instance to launch, which you can get by using `awless search images`",
+		"wait":  "Set to 'true' (or a timeout in seconds) to wait for the instance to be running before continuing",
 	},
 	"createkeypair": {
 		"name":      "The name of the keypair to create (it will also be the name of the file stored in ~/.awless/keys)",
@@ -150,6 +179,7 @@ var manualParamsDoc = map[string]map[string]string{
 	"createloadbalancer": {
 		"scheme": "The routing range of the loadbalancer (internet-facing | internal)",
 		"iptype": "The type of IP addresses used by the subnets for your load balancer: IPv4 or IPv4 and IPv6 (ipv4 | dualstack)",
+		"wait":   "Set to 'true' (or a timeout in seconds) to wait for the loadbalancer to be active before continuing",
 	},
 	"createpolicy": {
 		"name":        "The friendly name of the policy",
@@ -218,6 +248,9 @@ var manualParamsDoc = map[string]map[string]string{
 	"createtargetgroup": {
 		"matcher": "The HTTP codes to use when checking for a successful response from a target",
 	},
+	"createvolume": {
+		"wait": "Set to 'true' (or a timeout in seconds) to wait for the volume to be available before continuing",
+	},
 	"createvpc": {
 		"name": "The 'Name' Tag for the VPC to create",
 	},
@@ -311,6 +344,12 @@ var manualParamsDoc = map[string]map[string]string{
 		"license":      "The license type to be used for the Amazon Machine Image (AMI) after importing (AWS | BYOL)",
 		"platform":     "The operating system of the virtual machine (Windows | Linux)",
 	},
+	"startinstance": {
+		"wait": "Set to 'true' (or a timeout in seconds) to wait for the instance to be running before continuing",
+	},
+	"stopinstance": {
+		"wait": "Set to 'true' (or a timeout in seconds) to wait for the instance to be stopped before continuing",
+	},
 	"updatebucket": {
 		"name":              "The name of the bucket to update",
 		"acl":               "The canned ACL to apply to the bucket (private | public-read | public-read-write | aws-exec-read | authenticated-read | bucket-owner-read | bucket-owner-full-control | log-delivery-write)",
@@ -347,4 +386,39 @@ var manualParamsDoc = map[string]map[string]string{
 		"policy-update-file": "The path to the file containing the temporary overriding stack policy",
 		"template-file":      "The path to the file containing the template body with a minimum size of 1 byte and a maximum size of 51,200 bytes",
 	},
+	"waitdatabase": {
+		"id":      "The ID of the RDS Database to wait for",
+		"state":   "The state of the RDS Database to reach (available | backing-up | creating | deleting | failed | maintenance | modifying | rebooting | renaming | resetting-master-credentials | restore-error | storage-full | upgrading | not-found)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitdistribution": {
+		"id":      "The ID of the CloudFront Distribution to wait for",
+		"state":   "The state of the CloudFront Distribution to reach (Deployed | InProgress | not-found)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitinstance": {
+		"id":      "The ID of the EC2 Instance to wait for",
+		"state":   "The state of the EC2 Instance to reach (pending | running | shutting-down | terminated | stopping | stopped | not-found)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitloadbalancer": {
+		"id":      "The ID of the ELBv2 Loadbalancer to wait for",
+		"state":   "The state of the ELBv2 Loadbalancer to reach (provisioning | active | failed | not-found)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitscalinggroup": {
+		"name":    "The name of the AutoScaling Group to wait for",
+		"count":   "The number of Instances + Loadbalancers + TargetGroups in the AutoScaling Group to reach",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitsecuritygroup": {
+		"id":      "The ID of the EC2 Security Group to wait for",
+		"state":   "The state of the EC2 Security Group to reach (unused)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
+	"waitvolume": {
+		"id":      "The ID of the EC2 Volume to wait for",
+		"state":   "The state of the EC2 Volume to reach (available | in-use | not-found)",
+		"timeout": "The time (in seconds) after which the wait is failed (default: 300)",
+	},
 }
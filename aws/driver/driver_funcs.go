@@ -591,6 +591,14 @@ func (d *Ec2Driver) Check_Instance(params map[string]interface{}) (interface{},
 	return nil, c.check()
 }
 
+func (d *Ec2Driver) Wait_Instance_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Instance_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *Ec2Driver) Wait_Instance(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Instance(withDefaultWaitTimeout(params))
+}
+
 func (d *Ec2Driver) Check_Securitygroup_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["id"]; !ok {
 		return nil, errors.New("check securitygroup: missing required params 'id'")
@@ -650,6 +658,14 @@ func (d *Ec2Driver) Check_Securitygroup(params map[string]interface{}) (interfac
 	return nil, c.check()
 }
 
+func (d *Ec2Driver) Wait_Securitygroup_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Securitygroup_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *Ec2Driver) Wait_Securitygroup(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Securitygroup(withDefaultWaitTimeout(params))
+}
+
 func (d *Ec2Driver) Check_Volume_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["id"]; !ok {
 		return nil, errors.New("check volume: missing required params 'id'")
@@ -714,6 +730,14 @@ func (d *Ec2Driver) Check_Volume(params map[string]interface{}) (interface{}, er
 	return nil, c.check()
 }
 
+func (d *Ec2Driver) Wait_Volume_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Volume_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *Ec2Driver) Wait_Volume(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Volume(withDefaultWaitTimeout(params))
+}
+
 func (d *RdsDriver) Check_Database_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["id"]; !ok {
 		return nil, errors.New("check database: missing required params 'id'")
@@ -792,6 +816,14 @@ func (d *RdsDriver) Check_Database(params map[string]interface{}) (interface{},
 	return nil, c.check()
 }
 
+func (d *RdsDriver) Wait_Database_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Database_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *RdsDriver) Wait_Database(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Database(withDefaultWaitTimeout(params))
+}
+
 func (d *Elbv2Driver) Check_Loadbalancer_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["id"]; !ok {
 		return nil, errors.New("check loadbalancer: missing required params 'id'")
@@ -857,6 +889,14 @@ func (d *Elbv2Driver) Check_Loadbalancer(params map[string]interface{}) (interfa
 	return nil, c.check()
 }
 
+func (d *Elbv2Driver) Wait_Loadbalancer_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Loadbalancer_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *Elbv2Driver) Wait_Loadbalancer(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Loadbalancer(withDefaultWaitTimeout(params))
+}
+
 func (d *AutoscalingDriver) Check_Scalinggroup_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["name"].(string); !ok {
 		return nil, errors.New("check scalinggroup: missing required params 'name'")
@@ -905,6 +945,14 @@ func (d *AutoscalingDriver) Check_Scalinggroup(params map[string]interface{}) (i
 	return nil, c.check()
 }
 
+func (d *AutoscalingDriver) Wait_Scalinggroup_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Scalinggroup_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *AutoscalingDriver) Wait_Scalinggroup(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Scalinggroup(withDefaultWaitTimeout(params))
+}
+
 func (d *CloudfrontDriver) Check_Distribution_DryRun(params map[string]interface{}) (interface{}, error) {
 	if _, ok := params["id"]; !ok {
 		return nil, errors.New("check distribution: missing required params 'id'")
@@ -965,6 +1013,14 @@ func (d *CloudfrontDriver) Check_Distribution(params map[string]interface{}) (in
 	return nil, c.check()
 }
 
+func (d *CloudfrontDriver) Wait_Distribution_DryRun(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Distribution_DryRun(withDefaultWaitTimeout(params))
+}
+
+func (d *CloudfrontDriver) Wait_Distribution(params map[string]interface{}) (interface{}, error) {
+	return d.Check_Distribution(withDefaultWaitTimeout(params))
+}
+
 func (d *Ec2Driver) Create_Tag_DryRun(params map[string]interface{}) (interface{}, error) {
 	input := &ec2.CreateTagsInput{}
 	input.DryRun = aws.Bool(true)
@@ -1990,6 +2046,12 @@ func (d *CloudfrontDriver) Create_Distribution(params map[string]interface{}) (i
 	}
 	d.logger.ExtraVerbosef("cloudfront.CreateDistribution call took %s", time.Since(start))
 	id := aws.StringValue(output.Distribution.Id)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Distribution(map[string]interface{}{"id": id, "state": "Deployed", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("create distribution: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("create distribution '%s' done", id)
 	return id, nil
@@ -2108,6 +2170,32 @@ func fakeDryRunId(entity string) string {
 	}
 }
 
+const defaultWaitTimeout = 300
+
+// waitTimeout returns the timeout in seconds given by the 'wait' param: either
+// a number of seconds or a boolean enabling the wait with the default timeout.
+func waitTimeout(params map[string]interface{}) (int, bool) {
+	switch w := params["wait"].(type) {
+	case int:
+		return w, w > 0
+	case nil:
+		return 0, false
+	default:
+		if b, err := strconv.ParseBool(fmt.Sprint(w)); err == nil && b {
+			return defaultWaitTimeout, true
+		}
+		return 0, false
+	}
+}
+
+func withDefaultWaitTimeout(params map[string]interface{}) map[string]interface{} {
+	withTimeout := map[string]interface{}{"timeout": defaultWaitTimeout}
+	for k, v := range params {
+		withTimeout[k] = v
+	}
+	return withTimeout
+}
+
 type checker struct {
 	description string
 	timeout     time.Duration
@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -29,6 +31,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
 	"github.com/wallix/awless/template/driver"
 )
 
@@ -253,6 +257,135 @@ type mockSNS struct {
 	snsiface.SNSAPI
 }
 
+func TestWaitTimeout(t *testing.T) {
+	tcases := []struct {
+		wait    interface{}
+		timeout int
+		ok      bool
+	}{
+		{wait: nil, ok: false},
+		{wait: "false", ok: false},
+		{wait: "true", timeout: defaultWaitTimeout, ok: true},
+		{wait: 0, ok: false},
+		{wait: 600, timeout: 600, ok: true},
+		{wait: "anything", ok: false},
+	}
+	for _, tcase := range tcases {
+		params := map[string]interface{}{}
+		if tcase.wait != nil {
+			params["wait"] = tcase.wait
+		}
+		timeout, ok := waitTimeout(params)
+		if got, want := ok, tcase.ok; got != want {
+			t.Fatalf("%v: got %t, want %t", tcase.wait, got, want)
+		}
+		if got, want := timeout, tcase.timeout; got != want {
+			t.Fatalf("%v: got %d, want %d", tcase.wait, got, want)
+		}
+	}
+
+	params := withDefaultWaitTimeout(map[string]interface{}{"id": "i-1234", "state": "running"})
+	if got, want := params["timeout"], defaultWaitTimeout; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	params = withDefaultWaitTimeout(map[string]interface{}{"id": "i-1234", "state": "running", "timeout": 20})
+	if got, want := params["timeout"], 20; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFetcherCheckDriver(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Subnet("sub-1234").Prop("State", "available").Prop("Name", "mysubnet").Build(),
+		resourcetest.Subnet("sub-2345").Prop("State", "pending").Prop("Default", true).Build(),
+		resourcetest.VPC("vpc-1234").Prop("State", "available").Build(),
+	)
+	driv := NewFetcherCheckDriver(func(string) (*graph.Graph, error) { return g, nil }, "subnet")
+	driv.(*FetcherCheckDriver).frequency = 10 * time.Millisecond
+
+	t.Run("lookup", func(t *testing.T) {
+		for _, action := range []string{"check", "wait"} {
+			if _, err := driv.Lookup(action, "subnet"); err != nil {
+				t.Fatalf("%s subnet: %s", action, err)
+			}
+			if _, err := driv.Lookup(action, "vpc"); err != driver.ErrDriverFnNotFound {
+				t.Fatalf("%s vpc: got %v, want %v", action, err, driver.ErrDriverFnNotFound)
+			}
+		}
+		if _, err := driv.Lookup("create", "subnet"); err != driver.ErrDriverFnNotFound {
+			t.Fatalf("got %v, want %v", err, driver.ErrDriverFnNotFound)
+		}
+	})
+
+	t.Run("fetched property state", func(t *testing.T) {
+		tcases := []struct {
+			id, property, expect string
+		}{
+			{id: "sub-1234", property: "State", expect: "available"},
+			{id: "mysubnet", property: "State", expect: "available"},
+			{id: "sub-2345", property: "Default", expect: "true"},
+			{id: "vpc-1234", property: "State", expect: notFoundState},
+			{id: "sub-9999", property: "State", expect: notFoundState},
+		}
+		for _, tcase := range tcases {
+			state, err := fetchedPropertyState(g, "subnet", tcase.id, tcase.property)
+			if err != nil {
+				t.Fatalf("%s: %s", tcase.id, err)
+			}
+			if got, want := state, tcase.expect; got != want {
+				t.Fatalf("%s: got %s, want %s", tcase.id, got, want)
+			}
+		}
+		if state, err := fetchedPropertyState(g, "subnet", "sub-1234", "Unknown"); err != nil || state != unknownState {
+			t.Fatalf("got %s (err %v), want %s for missing property", state, err, unknownState)
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		fn, _ := driv.Lookup("check", "subnet")
+		if _, err := fn(map[string]interface{}{"id": "sub-1234", "state": "available", "timeout": 1}); err != nil {
+			t.Fatal(err)
+		}
+		fn, _ = driv.Lookup("wait", "subnet")
+		if _, err := fn(map[string]interface{}{"id": "sub-9999", "state": "not-found"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("wait for missing property", func(t *testing.T) {
+		var fetches int
+		polling := NewFetcherCheckDriver(func(string) (*graph.Graph, error) {
+			fetches++
+			transitional := graph.NewGraph()
+			if fetches < 3 {
+				transitional.AddResource(resourcetest.Subnet("sub-3456").Build())
+			} else {
+				transitional.AddResource(resourcetest.Subnet("sub-3456").Prop("State", "available").Build())
+			}
+			return transitional, nil
+		}, "subnet")
+		polling.(*FetcherCheckDriver).frequency = 10 * time.Millisecond
+
+		fn, _ := polling.Lookup("wait", "subnet")
+		if _, err := fn(map[string]interface{}{"id": "sub-3456", "state": "available", "timeout": 1}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fetches, 3; got != want {
+			t.Fatalf("got %d fetches, want %d", got, want)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		driv.SetDryRun(true)
+		defer driv.SetDryRun(false)
+		fn, _ := driv.Lookup("wait", "subnet")
+		if _, err := fn(map[string]interface{}{"state": "available"}); err == nil || !strings.HasPrefix(err.Error(), "wait subnet:") {
+			t.Fatalf("got %v, want wait error", err)
+		}
+	})
+}
+
 type mockSQS struct {
 	sqsiface.SQSAPI
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsdriver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/template/driver"
+)
+
+const (
+	defaultCheckProperty = "State"
+	// unknownState is the state of resources not having the checked property yet
+	// (ex: in transitional states), polled again until timeout
+	unknownState = "unknown"
+)
+
+// FetcherCheckDriver implements the check and wait actions for resource types
+// having no dedicated check driver, by polling the resources fetched by type.
+type FetcherCheckDriver struct {
+	dryRun    bool
+	logger    *logger.Logger
+	fetch     func(string) (*graph.Graph, error)
+	types     map[string]struct{}
+	frequency time.Duration
+}
+
+func NewFetcherCheckDriver(fetch func(string) (*graph.Graph, error), resourceTypes ...string) driver.Driver {
+	d := &FetcherCheckDriver{logger: logger.DiscardLogger, fetch: fetch, types: make(map[string]struct{}), frequency: 5 * time.Second}
+	for _, t := range resourceTypes {
+		d.types[t] = struct{}{}
+	}
+	return d
+}
+
+func (d *FetcherCheckDriver) SetDryRun(dry bool)         { d.dryRun = dry }
+func (d *FetcherCheckDriver) SetLogger(l *logger.Logger) { d.logger = l }
+
+func (d *FetcherCheckDriver) Lookup(lookups ...string) (driver.DriverFn, error) {
+	if len(lookups) != 2 {
+		return nil, driver.ErrDriverFnNotFound
+	}
+	action, entity := lookups[0], lookups[1]
+	if _, ok := d.types[entity]; !ok {
+		return nil, driver.ErrDriverFnNotFound
+	}
+
+	switch action {
+	case "check":
+		if d.dryRun {
+			return d.checkDryRunFn(action, entity), nil
+		}
+		return d.checkFn(entity), nil
+	case "wait":
+		if d.dryRun {
+			return withDefaultWaitTimeoutFn(d.checkDryRunFn(action, entity)), nil
+		}
+		return withDefaultWaitTimeoutFn(d.checkFn(entity)), nil
+	default:
+		return nil, driver.ErrDriverFnNotFound
+	}
+}
+
+func (d *FetcherCheckDriver) checkDryRunFn(action, entity string) driver.DriverFn {
+	return func(params map[string]interface{}) (interface{}, error) {
+		if _, ok := params["id"]; !ok {
+			return nil, fmt.Errorf("%s %s: missing required params 'id'", action, entity)
+		}
+
+		if _, ok := params["state"]; !ok {
+			return nil, fmt.Errorf("%s %s: missing required params 'state'", action, entity)
+		}
+
+		if _, ok := params["timeout"].(int); !ok {
+			return nil, fmt.Errorf("%s %s: missing required int param 'timeout'", action, entity)
+		}
+
+		d.logger.Verbosef("params dry run: %s %s ok", action, entity)
+		return nil, nil
+	}
+}
+
+func (d *FetcherCheckDriver) checkFn(entity string) driver.DriverFn {
+	return func(params map[string]interface{}) (interface{}, error) {
+		id := fmt.Sprint(params["id"])
+		property := defaultCheckProperty
+		if p, ok := params["property"]; ok {
+			property = fmt.Sprint(p)
+		}
+
+		c := &checker{
+			description: fmt.Sprintf("%s %s", entity, id),
+			timeout:     time.Duration(params["timeout"].(int)) * time.Second,
+			frequency:   d.frequency,
+			checkName:   property,
+			fetchFunc: func() (string, error) {
+				g, err := d.fetch(entity)
+				if err != nil {
+					return "", err
+				}
+				return fetchedPropertyState(g, entity, id, property)
+			},
+			expect: fmt.Sprint(params["state"]),
+			logger: d.logger,
+		}
+		return nil, c.check()
+	}
+}
+
+func fetchedPropertyState(g *graph.Graph, entity, id, property string) (string, error) {
+	resources, err := g.ResolveResources(&graph.And{Resolvers: []graph.Resolver{&graph.ById{Id: id}, &graph.ByType{Typ: entity}}})
+	if err != nil {
+		return "", err
+	}
+	if len(resources) == 0 {
+		resources, err = g.ResolveResources(&graph.And{Resolvers: []graph.Resolver{&graph.ByProperty{Key: "Name", Value: id}, &graph.ByType{Typ: entity}}})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch len(resources) {
+	case 0:
+		return notFoundState, nil
+	case 1:
+		val, ok := resources[0].Properties[property]
+		if !ok {
+			return unknownState, nil
+		}
+		return fmt.Sprint(val), nil
+	default:
+		return "", errors.New("multiple resources found")
+	}
+}
+
+func withDefaultWaitTimeoutFn(fn driver.DriverFn) driver.DriverFn {
+	return func(params map[string]interface{}) (interface{}, error) {
+		return fn(withDefaultWaitTimeout(params))
+	}
+}
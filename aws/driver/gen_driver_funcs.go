@@ -500,6 +500,12 @@ func (d *Ec2Driver) Create_Instance(params map[string]interface{}) (interface{},
 	if err != nil {
 		return nil, fmt.Errorf("create instance: adding tags: %s", err)
 	}
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Instance(map[string]interface{}{"id": id, "state": "running", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("create instance: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("create instance '%s' done", id)
 	return id, nil
@@ -674,6 +680,12 @@ func (d *Ec2Driver) Start_Instance(params map[string]interface{}) (interface{},
 	}
 	d.logger.ExtraVerbosef("ec2.StartInstances call took %s", time.Since(start))
 	id := aws.StringValue(output.StartingInstances[0].InstanceId)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Instance(map[string]interface{}{"id": id, "state": "running", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("start instance: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("start instance '%s' done", id)
 	return id, nil
@@ -724,6 +736,12 @@ func (d *Ec2Driver) Stop_Instance(params map[string]interface{}) (interface{}, e
 	}
 	d.logger.ExtraVerbosef("ec2.StopInstances call took %s", time.Since(start))
 	id := aws.StringValue(output.StoppingInstances[0].InstanceId)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Instance(map[string]interface{}{"id": id, "state": "stopped", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("stop instance: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("stop instance '%s' done", id)
 	return id, nil
@@ -1140,6 +1158,12 @@ func (d *Ec2Driver) Create_Volume(params map[string]interface{}) (interface{}, e
 	}
 	d.logger.ExtraVerbosef("ec2.CreateVolume call took %s", time.Since(start))
 	id := aws.StringValue(output.VolumeId)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Volume(map[string]interface{}{"id": id, "state": "available", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("create volume: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("create volume '%s' done", id)
 	return id, nil
@@ -2433,6 +2457,12 @@ func (d *Elbv2Driver) Create_Loadbalancer(params map[string]interface{}) (interf
 	}
 	d.logger.ExtraVerbosef("elbv2.CreateLoadBalancer call took %s", time.Since(start))
 	id := aws.StringValue(output.LoadBalancers[0].LoadBalancerArn)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Loadbalancer(map[string]interface{}{"id": id, "state": "active", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("create loadbalancer: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("create loadbalancer '%s' done", id)
 	return id, nil
@@ -3490,6 +3520,12 @@ func (d *RdsDriver) Create_Database(params map[string]interface{}) (interface{},
 	}
 	d.logger.ExtraVerbosef("rds.CreateDBInstance call took %s", time.Since(start))
 	id := aws.StringValue(output.DBInstance.DBInstanceIdentifier)
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_Database(map[string]interface{}{"id": id, "state": "available", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("create database: wait: %s", err)
+		}
+	}
 
 	d.logger.Infof("create database '%s' done", id)
 	return id, nil
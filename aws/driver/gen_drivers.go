@@ -119,6 +119,12 @@ func (d *Ec2Driver) Lookup(lookups ...string) (driverFn driver.DriverFn, err err
 		}
 		return d.Check_Instance, nil
 
+	case "waitinstance":
+		if d.dryRun {
+			return d.Wait_Instance_DryRun, nil
+		}
+		return d.Wait_Instance, nil
+
 	case "createsecuritygroup":
 		if d.dryRun {
 			return d.Create_Securitygroup_DryRun, nil
@@ -143,6 +149,12 @@ func (d *Ec2Driver) Lookup(lookups ...string) (driverFn driver.DriverFn, err err
 		}
 		return d.Check_Securitygroup, nil
 
+	case "waitsecuritygroup":
+		if d.dryRun {
+			return d.Wait_Securitygroup_DryRun, nil
+		}
+		return d.Wait_Securitygroup, nil
+
 	case "attachsecuritygroup":
 		if d.dryRun {
 			return d.Attach_Securitygroup_DryRun, nil
@@ -185,6 +197,12 @@ func (d *Ec2Driver) Lookup(lookups ...string) (driverFn driver.DriverFn, err err
 		}
 		return d.Check_Volume, nil
 
+	case "waitvolume":
+		if d.dryRun {
+			return d.Wait_Volume_DryRun, nil
+		}
+		return d.Wait_Volume, nil
+
 	case "deletevolume":
 		if d.dryRun {
 			return d.Delete_Volume_DryRun, nil
@@ -367,6 +385,12 @@ func (d *Elbv2Driver) Lookup(lookups ...string) (driverFn driver.DriverFn, err e
 		}
 		return d.Check_Loadbalancer, nil
 
+	case "waitloadbalancer":
+		if d.dryRun {
+			return d.Wait_Loadbalancer_DryRun, nil
+		}
+		return d.Wait_Loadbalancer, nil
+
 	case "createlistener":
 		if d.dryRun {
 			return d.Create_Listener_DryRun, nil
@@ -459,6 +483,12 @@ func (d *AutoscalingDriver) Lookup(lookups ...string) (driverFn driver.DriverFn,
 		}
 		return d.Check_Scalinggroup, nil
 
+	case "waitscalinggroup":
+		if d.dryRun {
+			return d.Wait_Scalinggroup_DryRun, nil
+		}
+		return d.Wait_Scalinggroup, nil
+
 	case "createscalingpolicy":
 		if d.dryRun {
 			return d.Create_Scalingpolicy_DryRun, nil
@@ -509,6 +539,12 @@ func (d *RdsDriver) Lookup(lookups ...string) (driverFn driver.DriverFn, err err
 		}
 		return d.Check_Database, nil
 
+	case "waitdatabase":
+		if d.dryRun {
+			return d.Wait_Database_DryRun, nil
+		}
+		return d.Wait_Database, nil
+
 	case "createdbsubnetgroup":
 		if d.dryRun {
 			return d.Create_Dbsubnetgroup_DryRun, nil
@@ -983,6 +1019,12 @@ func (d *CloudfrontDriver) Lookup(lookups ...string) (driverFn driver.DriverFn,
 		}
 		return d.Check_Distribution, nil
 
+	case "waitdistribution":
+		if d.dryRun {
+			return d.Wait_Distribution_DryRun, nil
+		}
+		return d.Wait_Distribution, nil
+
 	case "updatedistribution":
 		if d.dryRun {
 			return d.Update_Distribution_DryRun, nil
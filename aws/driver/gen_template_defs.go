@@ -33,10 +33,12 @@ var APIPerTemplateDefName = map[string]string{
 	"startinstance":             "ec2",
 	"stopinstance":              "ec2",
 	"checkinstance":             "ec2",
+	"waitinstance":              "ec2",
 	"createsecuritygroup":       "ec2",
 	"updatesecuritygroup":       "ec2",
 	"deletesecuritygroup":       "ec2",
 	"checksecuritygroup":        "ec2",
+	"waitsecuritygroup":         "ec2",
 	"attachsecuritygroup":       "ec2",
 	"detachsecuritygroup":       "ec2",
 	"copyimage":                 "ec2",
@@ -44,6 +46,7 @@ var APIPerTemplateDefName = map[string]string{
 	"deleteimage":               "ec2",
 	"createvolume":              "ec2",
 	"checkvolume":               "ec2",
+	"waitvolume":                "ec2",
 	"deletevolume":              "ec2",
 	"attachvolume":              "ec2",
 	"detachvolume":              "ec2",
@@ -71,6 +74,7 @@ var APIPerTemplateDefName = map[string]string{
 	"createloadbalancer":        "elbv2",
 	"deleteloadbalancer":        "elbv2",
 	"checkloadbalancer":         "elbv2",
+	"waitloadbalancer":          "elbv2",
 	"createlistener":            "elbv2",
 	"deletelistener":            "elbv2",
 	"createtargetgroup":         "elbv2",
@@ -83,11 +87,13 @@ var APIPerTemplateDefName = map[string]string{
 	"updatescalinggroup":        "autoscaling",
 	"deletescalinggroup":        "autoscaling",
 	"checkscalinggroup":         "autoscaling",
+	"waitscalinggroup":          "autoscaling",
 	"createscalingpolicy":       "autoscaling",
 	"deletescalingpolicy":       "autoscaling",
 	"createdatabase":            "rds",
 	"deletedatabase":            "rds",
 	"checkdatabase":             "rds",
+	"waitdatabase":              "rds",
 	"createdbsubnetgroup":       "rds",
 	"deletedbsubnetgroup":       "rds",
 	"createuser":                "iam",
@@ -137,11 +143,68 @@ var APIPerTemplateDefName = map[string]string{
 	"detachalarm":               "cloudwatch",
 	"createdistribution":        "cloudfront",
 	"checkdistribution":         "cloudfront",
+	"waitdistribution":          "cloudfront",
 	"updatedistribution":        "cloudfront",
 	"deletedistribution":        "cloudfront",
 	"createstack":               "cloudformation",
 	"updatestack":               "cloudformation",
 	"deletestack":               "cloudformation",
+	"checksubnet":               "ec2",
+	"waitsubnet":                "ec2",
+	"checkvpc":                  "ec2",
+	"waitvpc":                   "ec2",
+	"checkkeypair":              "ec2",
+	"waitkeypair":               "ec2",
+	"checkinternetgateway":      "ec2",
+	"waitinternetgateway":       "ec2",
+	"checkroutetable":           "ec2",
+	"waitroutetable":            "ec2",
+	"checkimage":                "ec2",
+	"waitimage":                 "ec2",
+	"checkelasticip":            "ec2",
+	"waitelasticip":             "ec2",
+	"checksnapshot":             "ec2",
+	"waitsnapshot":              "ec2",
+	"checktargetgroup":          "elbv2",
+	"waittargetgroup":           "elbv2",
+	"checklistener":             "elbv2",
+	"waitlistener":              "elbv2",
+	"checkdbsubnetgroup":        "rds",
+	"waitdbsubnetgroup":         "rds",
+	"checklaunchconfiguration":  "autoscaling",
+	"waitlaunchconfiguration":   "autoscaling",
+	"checkscalingpolicy":        "autoscaling",
+	"waitscalingpolicy":         "autoscaling",
+	"checkuser":                 "iam",
+	"waituser":                  "iam",
+	"checkgroup":                "iam",
+	"waitgroup":                 "iam",
+	"checkrole":                 "iam",
+	"waitrole":                  "iam",
+	"checkpolicy":               "iam",
+	"waitpolicy":                "iam",
+	"checkaccesskey":            "iam",
+	"waitaccesskey":             "iam",
+	"checkbucket":               "s3",
+	"waitbucket":                "s3",
+	"checks3object":             "s3",
+	"waits3object":              "s3",
+	"checksubscription":         "sns",
+	"waitsubscription":          "sns",
+	"checktopic":                "sns",
+	"waittopic":                 "sns",
+	"checkqueue":                "sqs",
+	"waitqueue":                 "sqs",
+	"checkzone":                 "route53",
+	"waitzone":                  "route53",
+	"checkrecord":               "route53",
+	"waitrecord":                "route53",
+	"checkfunction":             "lambda",
+	"waitfunction":              "lambda",
+	"checkalarm":                "cloudwatch",
+	"waitalarm":                 "cloudwatch",
+	"checkstack":                "cloudformation",
+	"waitstack":                 "cloudformation",
 }
 
 var AWSTemplatesDefinitions = map[string]template.Definition{
@@ -185,7 +248,7 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "instance",
 		Api:            "ec2",
 		RequiredParams: []string{"count", "image", "name", "subnet", "type"},
-		ExtraParams:    []string{"ip", "keypair", "lock", "role", "securitygroup", "userdata"},
+		ExtraParams:    []string{"ip", "keypair", "lock", "role", "securitygroup", "userdata", "wait"},
 	},
 	"updateinstance": {
 		Action:         "update",
@@ -206,14 +269,14 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "instance",
 		Api:            "ec2",
 		RequiredParams: []string{"id"},
-		ExtraParams:    []string{},
+		ExtraParams:    []string{"wait"},
 	},
 	"stopinstance": {
 		Action:         "stop",
 		Entity:         "instance",
 		Api:            "ec2",
 		RequiredParams: []string{"id"},
-		ExtraParams:    []string{},
+		ExtraParams:    []string{"wait"},
 	},
 	"checkinstance": {
 		Action:         "check",
@@ -222,6 +285,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitinstance": {
+		Action:         "wait",
+		Entity:         "instance",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"createsecuritygroup": {
 		Action:         "create",
 		Entity:         "securitygroup",
@@ -250,6 +320,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitsecuritygroup": {
+		Action:         "wait",
+		Entity:         "securitygroup",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"attachsecuritygroup": {
 		Action:         "attach",
 		Entity:         "securitygroup",
@@ -290,7 +367,7 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "volume",
 		Api:            "ec2",
 		RequiredParams: []string{"availabilityzone", "size"},
-		ExtraParams:    []string{},
+		ExtraParams:    []string{"wait"},
 	},
 	"checkvolume": {
 		Action:         "check",
@@ -299,6 +376,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitvolume": {
+		Action:         "wait",
+		Entity:         "volume",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"deletevolume": {
 		Action:         "delete",
 		Entity:         "volume",
@@ -472,7 +556,7 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "loadbalancer",
 		Api:            "elbv2",
 		RequiredParams: []string{"name", "subnets"},
-		ExtraParams:    []string{"iptype", "scheme", "securitygroups"},
+		ExtraParams:    []string{"iptype", "scheme", "securitygroups", "wait"},
 	},
 	"deleteloadbalancer": {
 		Action:         "delete",
@@ -488,6 +572,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitloadbalancer": {
+		Action:         "wait",
+		Entity:         "loadbalancer",
+		Api:            "elbv2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"createlistener": {
 		Action:         "create",
 		Entity:         "listener",
@@ -572,6 +663,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"count", "name", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitscalinggroup": {
+		Action:         "wait",
+		Entity:         "scalinggroup",
+		Api:            "autoscaling",
+		RequiredParams: []string{"count", "name"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"createscalingpolicy": {
 		Action:         "create",
 		Entity:         "scalingpolicy",
@@ -591,7 +689,7 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "database",
 		Api:            "rds",
 		RequiredParams: []string{"engine", "id", "password", "size", "type", "username"},
-		ExtraParams:    []string{"autoupgrade", "availabilityzone", "backupretention", "backupwindow", "cluster", "dbname", "dbsecuritygroups", "domain", "encrypted", "iamrole", "iops", "license", "maintenancewindow", "multiaz", "optiongroup", "parametergroup", "port", "public", "storagetype", "subnetgroup", "timezone", "version", "vpcsecuritygroups"},
+		ExtraParams:    []string{"autoupgrade", "availabilityzone", "backupretention", "backupwindow", "cluster", "dbname", "dbsecuritygroups", "domain", "encrypted", "iamrole", "iops", "license", "maintenancewindow", "multiaz", "optiongroup", "parametergroup", "port", "public", "storagetype", "subnetgroup", "timezone", "version", "vpcsecuritygroups", "wait"},
 	},
 	"deletedatabase": {
 		Action:         "delete",
@@ -607,6 +705,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitdatabase": {
+		Action:         "wait",
+		Entity:         "database",
+		Api:            "rds",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"createdbsubnetgroup": {
 		Action:         "create",
 		Entity:         "dbsubnetgroup",
@@ -941,7 +1046,7 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		Entity:         "distribution",
 		Api:            "cloudfront",
 		RequiredParams: []string{"origin-domain"},
-		ExtraParams:    []string{"certificate", "comment", "default-file", "domain-aliases", "enable", "forward-cookies", "forward-queries", "https-behaviour", "min-ttl", "origin-path", "price-class"},
+		ExtraParams:    []string{"certificate", "comment", "default-file", "domain-aliases", "enable", "forward-cookies", "forward-queries", "https-behaviour", "min-ttl", "origin-path", "price-class", "wait"},
 	},
 	"checkdistribution": {
 		Action:         "check",
@@ -950,6 +1055,13 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"id", "state", "timeout"},
 		ExtraParams:    []string{},
 	},
+	"waitdistribution": {
+		Action:         "wait",
+		Entity:         "distribution",
+		Api:            "cloudfront",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"timeout"},
+	},
 	"updatedistribution": {
 		Action:         "update",
 		Entity:         "distribution",
@@ -985,6 +1097,398 @@ var AWSTemplatesDefinitions = map[string]template.Definition{
 		RequiredParams: []string{"name"},
 		ExtraParams:    []string{"retain-resources"},
 	},
+	"checksubnet": {
+		Action:         "check",
+		Entity:         "subnet",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitsubnet": {
+		Action:         "wait",
+		Entity:         "subnet",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkvpc": {
+		Action:         "check",
+		Entity:         "vpc",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitvpc": {
+		Action:         "wait",
+		Entity:         "vpc",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkkeypair": {
+		Action:         "check",
+		Entity:         "keypair",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitkeypair": {
+		Action:         "wait",
+		Entity:         "keypair",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkinternetgateway": {
+		Action:         "check",
+		Entity:         "internetgateway",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitinternetgateway": {
+		Action:         "wait",
+		Entity:         "internetgateway",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkroutetable": {
+		Action:         "check",
+		Entity:         "routetable",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitroutetable": {
+		Action:         "wait",
+		Entity:         "routetable",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkimage": {
+		Action:         "check",
+		Entity:         "image",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitimage": {
+		Action:         "wait",
+		Entity:         "image",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkelasticip": {
+		Action:         "check",
+		Entity:         "elasticip",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitelasticip": {
+		Action:         "wait",
+		Entity:         "elasticip",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checksnapshot": {
+		Action:         "check",
+		Entity:         "snapshot",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitsnapshot": {
+		Action:         "wait",
+		Entity:         "snapshot",
+		Api:            "ec2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checktargetgroup": {
+		Action:         "check",
+		Entity:         "targetgroup",
+		Api:            "elbv2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waittargetgroup": {
+		Action:         "wait",
+		Entity:         "targetgroup",
+		Api:            "elbv2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checklistener": {
+		Action:         "check",
+		Entity:         "listener",
+		Api:            "elbv2",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitlistener": {
+		Action:         "wait",
+		Entity:         "listener",
+		Api:            "elbv2",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkdbsubnetgroup": {
+		Action:         "check",
+		Entity:         "dbsubnetgroup",
+		Api:            "rds",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitdbsubnetgroup": {
+		Action:         "wait",
+		Entity:         "dbsubnetgroup",
+		Api:            "rds",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checklaunchconfiguration": {
+		Action:         "check",
+		Entity:         "launchconfiguration",
+		Api:            "autoscaling",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitlaunchconfiguration": {
+		Action:         "wait",
+		Entity:         "launchconfiguration",
+		Api:            "autoscaling",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkscalingpolicy": {
+		Action:         "check",
+		Entity:         "scalingpolicy",
+		Api:            "autoscaling",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitscalingpolicy": {
+		Action:         "wait",
+		Entity:         "scalingpolicy",
+		Api:            "autoscaling",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkuser": {
+		Action:         "check",
+		Entity:         "user",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waituser": {
+		Action:         "wait",
+		Entity:         "user",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkgroup": {
+		Action:         "check",
+		Entity:         "group",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitgroup": {
+		Action:         "wait",
+		Entity:         "group",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkrole": {
+		Action:         "check",
+		Entity:         "role",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitrole": {
+		Action:         "wait",
+		Entity:         "role",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkpolicy": {
+		Action:         "check",
+		Entity:         "policy",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitpolicy": {
+		Action:         "wait",
+		Entity:         "policy",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkaccesskey": {
+		Action:         "check",
+		Entity:         "accesskey",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitaccesskey": {
+		Action:         "wait",
+		Entity:         "accesskey",
+		Api:            "iam",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkbucket": {
+		Action:         "check",
+		Entity:         "bucket",
+		Api:            "s3",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitbucket": {
+		Action:         "wait",
+		Entity:         "bucket",
+		Api:            "s3",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checks3object": {
+		Action:         "check",
+		Entity:         "s3object",
+		Api:            "s3",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waits3object": {
+		Action:         "wait",
+		Entity:         "s3object",
+		Api:            "s3",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checksubscription": {
+		Action:         "check",
+		Entity:         "subscription",
+		Api:            "sns",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitsubscription": {
+		Action:         "wait",
+		Entity:         "subscription",
+		Api:            "sns",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checktopic": {
+		Action:         "check",
+		Entity:         "topic",
+		Api:            "sns",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waittopic": {
+		Action:         "wait",
+		Entity:         "topic",
+		Api:            "sns",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkqueue": {
+		Action:         "check",
+		Entity:         "queue",
+		Api:            "sqs",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitqueue": {
+		Action:         "wait",
+		Entity:         "queue",
+		Api:            "sqs",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkzone": {
+		Action:         "check",
+		Entity:         "zone",
+		Api:            "route53",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitzone": {
+		Action:         "wait",
+		Entity:         "zone",
+		Api:            "route53",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkrecord": {
+		Action:         "check",
+		Entity:         "record",
+		Api:            "route53",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitrecord": {
+		Action:         "wait",
+		Entity:         "record",
+		Api:            "route53",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkfunction": {
+		Action:         "check",
+		Entity:         "function",
+		Api:            "lambda",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitfunction": {
+		Action:         "wait",
+		Entity:         "function",
+		Api:            "lambda",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkalarm": {
+		Action:         "check",
+		Entity:         "alarm",
+		Api:            "cloudwatch",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitalarm": {
+		Action:         "wait",
+		Entity:         "alarm",
+		Api:            "cloudwatch",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
+	"checkstack": {
+		Action:         "check",
+		Entity:         "stack",
+		Api:            "cloudformation",
+		RequiredParams: []string{"id", "state", "timeout"},
+		ExtraParams:    []string{"property"},
+	},
+	"waitstack": {
+		Action:         "wait",
+		Entity:         "stack",
+		Api:            "cloudformation",
+		RequiredParams: []string{"id", "state"},
+		ExtraParams:    []string{"property", "timeout"},
+	},
 }
 
 func DriverSupportedActions() map[string][]string {
@@ -1000,10 +1504,12 @@ func DriverSupportedActions() map[string][]string {
 	supported["start"] = append(supported["start"], "instance")
 	supported["stop"] = append(supported["stop"], "instance")
 	supported["check"] = append(supported["check"], "instance")
+	supported["wait"] = append(supported["wait"], "instance")
 	supported["create"] = append(supported["create"], "securitygroup")
 	supported["update"] = append(supported["update"], "securitygroup")
 	supported["delete"] = append(supported["delete"], "securitygroup")
 	supported["check"] = append(supported["check"], "securitygroup")
+	supported["wait"] = append(supported["wait"], "securitygroup")
 	supported["attach"] = append(supported["attach"], "securitygroup")
 	supported["detach"] = append(supported["detach"], "securitygroup")
 	supported["copy"] = append(supported["copy"], "image")
@@ -1011,6 +1517,7 @@ func DriverSupportedActions() map[string][]string {
 	supported["delete"] = append(supported["delete"], "image")
 	supported["create"] = append(supported["create"], "volume")
 	supported["check"] = append(supported["check"], "volume")
+	supported["wait"] = append(supported["wait"], "volume")
 	supported["delete"] = append(supported["delete"], "volume")
 	supported["attach"] = append(supported["attach"], "volume")
 	supported["detach"] = append(supported["detach"], "volume")
@@ -1038,6 +1545,7 @@ func DriverSupportedActions() map[string][]string {
 	supported["create"] = append(supported["create"], "loadbalancer")
 	supported["delete"] = append(supported["delete"], "loadbalancer")
 	supported["check"] = append(supported["check"], "loadbalancer")
+	supported["wait"] = append(supported["wait"], "loadbalancer")
 	supported["create"] = append(supported["create"], "listener")
 	supported["delete"] = append(supported["delete"], "listener")
 	supported["create"] = append(supported["create"], "targetgroup")
@@ -1050,11 +1558,13 @@ func DriverSupportedActions() map[string][]string {
 	supported["update"] = append(supported["update"], "scalinggroup")
 	supported["delete"] = append(supported["delete"], "scalinggroup")
 	supported["check"] = append(supported["check"], "scalinggroup")
+	supported["wait"] = append(supported["wait"], "scalinggroup")
 	supported["create"] = append(supported["create"], "scalingpolicy")
 	supported["delete"] = append(supported["delete"], "scalingpolicy")
 	supported["create"] = append(supported["create"], "database")
 	supported["delete"] = append(supported["delete"], "database")
 	supported["check"] = append(supported["check"], "database")
+	supported["wait"] = append(supported["wait"], "database")
 	supported["create"] = append(supported["create"], "dbsubnetgroup")
 	supported["delete"] = append(supported["delete"], "dbsubnetgroup")
 	supported["create"] = append(supported["create"], "user")
@@ -1104,10 +1614,67 @@ func DriverSupportedActions() map[string][]string {
 	supported["detach"] = append(supported["detach"], "alarm")
 	supported["create"] = append(supported["create"], "distribution")
 	supported["check"] = append(supported["check"], "distribution")
+	supported["wait"] = append(supported["wait"], "distribution")
 	supported["update"] = append(supported["update"], "distribution")
 	supported["delete"] = append(supported["delete"], "distribution")
 	supported["create"] = append(supported["create"], "stack")
 	supported["update"] = append(supported["update"], "stack")
 	supported["delete"] = append(supported["delete"], "stack")
+	supported["check"] = append(supported["check"], "subnet")
+	supported["wait"] = append(supported["wait"], "subnet")
+	supported["check"] = append(supported["check"], "vpc")
+	supported["wait"] = append(supported["wait"], "vpc")
+	supported["check"] = append(supported["check"], "keypair")
+	supported["wait"] = append(supported["wait"], "keypair")
+	supported["check"] = append(supported["check"], "internetgateway")
+	supported["wait"] = append(supported["wait"], "internetgateway")
+	supported["check"] = append(supported["check"], "routetable")
+	supported["wait"] = append(supported["wait"], "routetable")
+	supported["check"] = append(supported["check"], "image")
+	supported["wait"] = append(supported["wait"], "image")
+	supported["check"] = append(supported["check"], "elasticip")
+	supported["wait"] = append(supported["wait"], "elasticip")
+	supported["check"] = append(supported["check"], "snapshot")
+	supported["wait"] = append(supported["wait"], "snapshot")
+	supported["check"] = append(supported["check"], "targetgroup")
+	supported["wait"] = append(supported["wait"], "targetgroup")
+	supported["check"] = append(supported["check"], "listener")
+	supported["wait"] = append(supported["wait"], "listener")
+	supported["check"] = append(supported["check"], "dbsubnetgroup")
+	supported["wait"] = append(supported["wait"], "dbsubnetgroup")
+	supported["check"] = append(supported["check"], "launchconfiguration")
+	supported["wait"] = append(supported["wait"], "launchconfiguration")
+	supported["check"] = append(supported["check"], "scalingpolicy")
+	supported["wait"] = append(supported["wait"], "scalingpolicy")
+	supported["check"] = append(supported["check"], "user")
+	supported["wait"] = append(supported["wait"], "user")
+	supported["check"] = append(supported["check"], "group")
+	supported["wait"] = append(supported["wait"], "group")
+	supported["check"] = append(supported["check"], "role")
+	supported["wait"] = append(supported["wait"], "role")
+	supported["check"] = append(supported["check"], "policy")
+	supported["wait"] = append(supported["wait"], "policy")
+	supported["check"] = append(supported["check"], "accesskey")
+	supported["wait"] = append(supported["wait"], "accesskey")
+	supported["check"] = append(supported["check"], "bucket")
+	supported["wait"] = append(supported["wait"], "bucket")
+	supported["check"] = append(supported["check"], "s3object")
+	supported["wait"] = append(supported["wait"], "s3object")
+	supported["check"] = append(supported["check"], "subscription")
+	supported["wait"] = append(supported["wait"], "subscription")
+	supported["check"] = append(supported["check"], "topic")
+	supported["wait"] = append(supported["wait"], "topic")
+	supported["check"] = append(supported["check"], "queue")
+	supported["wait"] = append(supported["wait"], "queue")
+	supported["check"] = append(supported["check"], "zone")
+	supported["wait"] = append(supported["wait"], "zone")
+	supported["check"] = append(supported["check"], "record")
+	supported["wait"] = append(supported["wait"], "record")
+	supported["check"] = append(supported["check"], "function")
+	supported["wait"] = append(supported["wait"], "function")
+	supported["check"] = append(supported["check"], "alarm")
+	supported["wait"] = append(supported["wait"], "alarm")
+	supported["check"] = append(supported["check"], "stack")
+	supported["wait"] = append(supported["wait"], "stack")
 	return supported
 }
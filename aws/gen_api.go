@@ -251,6 +251,7 @@ func (s *Infra) Drivers() []driver.Driver {
 		awsdriver.NewElbv2Driver(s.ELBV2API),
 		awsdriver.NewRdsDriver(s.RDSAPI),
 		awsdriver.NewAutoscalingDriver(s.AutoScalingAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "subnet", "vpc", "keypair", "internetgateway", "routetable", "image", "elasticip", "snapshot", "targetgroup", "listener", "dbsubnetgroup", "launchconfiguration", "scalingpolicy"),
 	}
 }
 
@@ -1005,72 +1006,79 @@ func (s *Infra) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Infra) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Infra) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "instance":
-		graph, _, err := s.fetch_all_instance_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_instance_graph()
+		return graph, relationsBuilder("instance", resources), err
 	case "subnet":
-		graph, _, err := s.fetch_all_subnet_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_subnet_graph()
+		return graph, relationsBuilder("subnet", resources), err
 	case "vpc":
-		graph, _, err := s.fetch_all_vpc_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_vpc_graph()
+		return graph, relationsBuilder("vpc", resources), err
 	case "keypair":
-		graph, _, err := s.fetch_all_keypair_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_keypair_graph()
+		return graph, relationsBuilder("keypair", resources), err
 	case "securitygroup":
-		graph, _, err := s.fetch_all_securitygroup_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_securitygroup_graph()
+		return graph, relationsBuilder("securitygroup", resources), err
 	case "volume":
-		graph, _, err := s.fetch_all_volume_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_volume_graph()
+		return graph, relationsBuilder("volume", resources), err
 	case "internetgateway":
-		graph, _, err := s.fetch_all_internetgateway_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_internetgateway_graph()
+		return graph, relationsBuilder("internetgateway", resources), err
 	case "routetable":
-		graph, _, err := s.fetch_all_routetable_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_routetable_graph()
+		return graph, relationsBuilder("routetable", resources), err
 	case "availabilityzone":
-		graph, _, err := s.fetch_all_availabilityzone_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_availabilityzone_graph()
+		return graph, relationsBuilder("availabilityzone", resources), err
 	case "image":
-		graph, _, err := s.fetch_all_image_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_image_graph()
+		return graph, relationsBuilder("image", resources), err
 	case "importimagetask":
-		graph, _, err := s.fetch_all_importimagetask_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_importimagetask_graph()
+		return graph, relationsBuilder("importimagetask", resources), err
 	case "elasticip":
-		graph, _, err := s.fetch_all_elasticip_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_elasticip_graph()
+		return graph, relationsBuilder("elasticip", resources), err
 	case "snapshot":
-		graph, _, err := s.fetch_all_snapshot_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_snapshot_graph()
+		return graph, relationsBuilder("snapshot", resources), err
 	case "loadbalancer":
-		graph, _, err := s.fetch_all_loadbalancer_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_loadbalancer_graph()
+		return graph, relationsBuilder("loadbalancer", resources), err
 	case "targetgroup":
-		graph, _, err := s.fetch_all_targetgroup_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_targetgroup_graph()
+		return graph, relationsBuilder("targetgroup", resources), err
 	case "listener":
-		graph, _, err := s.fetch_all_listener_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_listener_graph()
+		return graph, relationsBuilder("listener", resources), err
 	case "database":
-		graph, _, err := s.fetch_all_database_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_database_graph()
+		return graph, relationsBuilder("database", resources), err
 	case "dbsubnetgroup":
-		graph, _, err := s.fetch_all_dbsubnetgroup_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_dbsubnetgroup_graph()
+		return graph, relationsBuilder("dbsubnetgroup", resources), err
 	case "launchconfiguration":
-		graph, _, err := s.fetch_all_launchconfiguration_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_launchconfiguration_graph()
+		return graph, relationsBuilder("launchconfiguration", resources), err
 	case "scalinggroup":
-		graph, _, err := s.fetch_all_scalinggroup_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_scalinggroup_graph()
+		return graph, relationsBuilder("scalinggroup", resources), err
 	case "scalingpolicy":
-		graph, _, err := s.fetch_all_scalingpolicy_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_scalingpolicy_graph()
+		return graph, relationsBuilder("scalingpolicy", resources), err
 	default:
-		return nil, fmt.Errorf("aws infra: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws infra: unsupported fetch for type %s", t)
 	}
 }
 
@@ -1624,6 +1632,7 @@ func (s *Access) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewIamDriver(s.IAMAPI),
 		awsdriver.NewStsDriver(s.STSAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "user", "group", "role", "policy", "accesskey"),
 	}
 }
 
@@ -1850,24 +1859,31 @@ func (s *Access) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Access) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Access) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "user":
-		graph, _, err := s.fetch_all_user_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_user_graph()
+		return graph, relationsBuilder("user", resources), err
 	case "group":
-		graph, _, err := s.fetch_all_group_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_group_graph()
+		return graph, relationsBuilder("group", resources), err
 	case "role":
-		graph, _, err := s.fetch_all_role_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_role_graph()
+		return graph, relationsBuilder("role", resources), err
 	case "policy":
-		graph, _, err := s.fetch_all_policy_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_policy_graph()
+		return graph, relationsBuilder("policy", resources), err
 	case "accesskey":
-		graph, _, err := s.fetch_all_accesskey_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_accesskey_graph()
+		return graph, relationsBuilder("accesskey", resources), err
 	default:
-		return nil, fmt.Errorf("aws access: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws access: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2012,6 +2028,7 @@ func (s *Storage) Name() string {
 func (s *Storage) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewS3Driver(s.S3API),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "bucket", "s3object"),
 	}
 }
 
@@ -2139,15 +2156,22 @@ func (s *Storage) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Storage) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Storage) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "bucket":
-		graph, _, err := s.fetch_all_bucket_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_bucket_graph()
+		return graph, relationsBuilder("bucket", resources), err
 	case "s3object":
-		graph, _, err := s.fetch_all_s3object_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_s3object_graph()
+		return graph, relationsBuilder("s3object", resources), err
 	default:
-		return nil, fmt.Errorf("aws storage: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws storage: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2180,6 +2204,7 @@ func (s *Notification) Name() string {
 func (s *Notification) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewSnsDriver(s.SNSAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "subscription", "topic"),
 	}
 }
 
@@ -2307,15 +2332,22 @@ func (s *Notification) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Notification) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Notification) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "subscription":
-		graph, _, err := s.fetch_all_subscription_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_subscription_graph()
+		return graph, relationsBuilder("subscription", resources), err
 	case "topic":
-		graph, _, err := s.fetch_all_topic_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_topic_graph()
+		return graph, relationsBuilder("topic", resources), err
 	default:
-		return nil, fmt.Errorf("aws notification: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws notification: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2404,6 +2436,7 @@ func (s *Queue) Name() string {
 func (s *Queue) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewSqsDriver(s.SQSAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "queue"),
 	}
 }
 
@@ -2498,12 +2531,19 @@ func (s *Queue) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Queue) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Queue) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "queue":
-		graph, _, err := s.fetch_all_queue_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_queue_graph()
+		return graph, relationsBuilder("queue", resources), err
 	default:
-		return nil, fmt.Errorf("aws queue: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws queue: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2536,6 +2576,7 @@ func (s *Dns) Name() string {
 func (s *Dns) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewRoute53Driver(s.Route53API),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "zone", "record"),
 	}
 }
 
@@ -2663,15 +2704,22 @@ func (s *Dns) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Dns) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Dns) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "zone":
-		graph, _, err := s.fetch_all_zone_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_zone_graph()
+		return graph, relationsBuilder("zone", resources), err
 	case "record":
-		graph, _, err := s.fetch_all_record_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_record_graph()
+		return graph, relationsBuilder("record", resources), err
 	default:
-		return nil, fmt.Errorf("aws dns: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws dns: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2732,6 +2780,7 @@ func (s *Lambda) Name() string {
 func (s *Lambda) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewLambdaDriver(s.LambdaAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "function"),
 	}
 }
 
@@ -2826,12 +2875,19 @@ func (s *Lambda) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Lambda) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Lambda) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "function":
-		graph, _, err := s.fetch_all_function_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_function_graph()
+		return graph, relationsBuilder("function", resources), err
 	default:
-		return nil, fmt.Errorf("aws lambda: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws lambda: unsupported fetch for type %s", t)
 	}
 }
 
@@ -2892,6 +2948,7 @@ func (s *Monitoring) Name() string {
 func (s *Monitoring) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewCloudwatchDriver(s.CloudWatchAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "alarm"),
 	}
 }
 
@@ -3019,15 +3076,22 @@ func (s *Monitoring) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Monitoring) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Monitoring) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "metric":
-		graph, _, err := s.fetch_all_metric_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_metric_graph()
+		return graph, relationsBuilder("metric", resources), err
 	case "alarm":
-		graph, _, err := s.fetch_all_alarm_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_alarm_graph()
+		return graph, relationsBuilder("alarm", resources), err
 	default:
-		return nil, fmt.Errorf("aws monitoring: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws monitoring: unsupported fetch for type %s", t)
 	}
 }
 
@@ -3210,12 +3274,19 @@ func (s *Cdn) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Cdn) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Cdn) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "distribution":
-		graph, _, err := s.fetch_all_distribution_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_distribution_graph()
+		return graph, relationsBuilder("distribution", resources), err
 	default:
-		return nil, fmt.Errorf("aws cdn: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws cdn: unsupported fetch for type %s", t)
 	}
 }
 
@@ -3276,6 +3347,7 @@ func (s *Cloudformation) Name() string {
 func (s *Cloudformation) Drivers() []driver.Driver {
 	return []driver.Driver{
 		awsdriver.NewCloudformationDriver(s.CloudFormationAPI),
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, "stack"),
 	}
 }
 
@@ -3370,12 +3442,19 @@ func (s *Cloudformation) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *Cloudformation) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *Cloudformation) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
 	switch t {
 	case "stack":
-		graph, _, err := s.fetch_all_stack_graph()
-		return graph, err
+		graph, resources, err := s.fetch_all_stack_graph()
+		return graph, relationsBuilder("stack", resources), err
 	default:
-		return nil, fmt.Errorf("aws cloudformation: unsupported fetch for type %s", t)
+		return nil, nil, fmt.Errorf("aws cloudformation: unsupported fetch for type %s", t)
 	}
 }
 
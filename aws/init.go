@@ -25,6 +25,8 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/wallix/awless/aws/config"
 	"github.com/wallix/awless/cloud"
 	"github.com/wallix/awless/logger"
@@ -72,6 +74,39 @@ func InitServices(conf map[string]interface{}, log *logger.Logger) error {
 	return nil
 }
 
+// NewServicesForRegion returns the services of the given region, using the other
+// settings (profile, ...) of the given config. Unlike InitServices, it does not
+// register them in cloud.ServiceRegistry
+func NewServicesForRegion(region string, conf map[string]interface{}, log *logger.Logger) ([]cloud.Service, error) {
+	if !awsconfig.IsValidRegion(region) {
+		return nil, fmt.Errorf("invalid region '%s' provided", region)
+	}
+
+	awsconf := make(config)
+	for k, v := range conf {
+		awsconf[k] = v
+	}
+	awsconf["aws.region"] = region
+
+	sess, err := initAWSSession(region, awsconf.profile())
+	if err != nil {
+		return nil, err
+	}
+
+	return []cloud.Service{
+		NewInfra(sess, awsconf, log),
+		NewAccess(sess, awsconf, log),
+		NewStorage(sess, awsconf, log),
+		NewNotification(sess, awsconf, log),
+		NewQueue(sess, awsconf, log),
+		NewDns(sess, awsconf, log),
+		NewLambda(sess, awsconf, log),
+		NewMonitoring(sess, awsconf, log),
+		NewCdn(sess, awsconf, log),
+		NewCloudformation(sess, awsconf, log),
+	}, nil
+}
+
 func NewDriver(region, profile string, log ...*logger.Logger) (driver.Driver, error) {
 	if !awsconfig.IsValidRegion(region) {
 		return nil, fmt.Errorf("invalid region '%s' provided", region)
@@ -106,6 +141,23 @@ func NewDriver(region, profile string, log ...*logger.Logger) (driver.Driver, er
 	return driver.NewMultiDriver(drivers...), nil
 }
 
+// NewS3API returns a S3 client for the given region and profile, sending its requests
+// to the given endpoint if any (ex: http://localhost:9000 for a S3 compatible storage)
+func NewS3API(region, profile, endpoint string) (s3iface.S3API, error) {
+	sess, err := initAWSSession(region, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// the remote inventory transfers whole graphs and revisions: its client gets
+	// its own HTTP client, free from the short timeout of the session credentials check
+	conf := awssdk.NewConfig().WithHTTPClient(&http.Client{})
+	if endpoint != "" {
+		conf = conf.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	return s3.New(sess, conf), nil
+}
+
 func initAWSSession(region, profile string) (*session.Session, error) {
 	session, err := session.NewSessionWithOptions(session.Options{
 		Config:                  awssdk.Config{Region: awssdk.String(region), HTTPClient: &http.Client{Timeout: 2 * time.Second}},
@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestS3APIHasNoRequestTimeout(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY_ID", "id")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	api, err := NewS3API("eu-west-1", "", "http://localhost:9000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeout := api.(*s3.S3).Client.Config.HTTPClient.Timeout; timeout != 0 {
+		t.Fatalf("got %s timeout, want none", timeout)
+	}
+	if got, want := *api.(*s3.S3).Client.Config.Endpoint, "http://localhost:9000"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -129,6 +129,26 @@ var addParentsFns = map[string][]addParentFn{
 	cloud.Stack:            {addRegionParent},
 }
 
+// relationsBuilder returns the func adding the relations of the fetched resources
+// of the given type to a graph holding the resources they relate to, or nil if they have none
+func relationsBuilder(resourceType string, resources interface{}) func(*graph.Graph) error {
+	fns := addParentsFns[resourceType]
+	if len(fns) == 0 {
+		return nil
+	}
+	return func(g *graph.Graph) error {
+		list := reflect.ValueOf(resources)
+		for i := 0; i < list.Len(); i++ {
+			for _, fn := range fns {
+				if err := fn(g, list.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
 func (fb funcBuilder) build() addParentFn {
 	switch {
 	case fb.listName != "":
@@ -95,6 +95,13 @@ type Service interface {
 	FetchByType(t string) (*graph.Graph, error)
 }
 
+// RelationsFetcher is implemented by the services whose resources of a type, fetched with FetchByType,
+// miss the relations built when fetching the whole service: the returned func builds them in the graph
+// of the service the resources are merged into (see graph.RebuildRelations)
+type RelationsFetcher interface {
+	FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error)
+}
+
 type Services []Service
 
 func (srvs Services) Names() (names []string) {
@@ -18,6 +18,7 @@ limitations under the License.
 package properties
 
 const (
+	Account                   = "Account"
 	Actions                   = "Actions"
 	ActionsEnabled            = "ActionsEnabled"
 	ACMCertificate            = "ACMCertificate"
@@ -20,6 +20,7 @@ package rdf
 import "github.com/wallix/awless/cloud/properties"
 
 const (
+	Account                   = "cloud:account"
 	Actions                   = "cloud:actions"
 	ActionsEnabled            = "cloud:actionsEnabled"
 	ACMCertificate            = "cloud:acmCertificate"
@@ -197,6 +198,7 @@ const (
 )
 
 var Labels = map[string]string{
+	properties.Account:                   Account,
 	properties.Actions:                   Actions,
 	properties.ActionsEnabled:            ActionsEnabled,
 	properties.ACMCertificate:            ACMCertificate,
@@ -374,6 +376,7 @@ var Labels = map[string]string{
 }
 
 var Properties = RDFProperties{
+	Account:                 {ID: Account, RdfType: "rdf:Property", RdfsLabel: "Account", RdfsDefinedBy: "rdfs:list", RdfsDataType: "xsd:string"},
 	Actions:                 {ID: Actions, RdfType: "rdf:Property", RdfsLabel: "Actions", RdfsDefinedBy: "rdfs:list", RdfsDataType: "xsd:string"},
 	ActionsEnabled:          {ID: ActionsEnabled, RdfType: "rdf:Property", RdfsLabel: "ActionsEnabled", RdfsDefinedBy: "rdfs:Literal", RdfsDataType: "xsd:boolean"},
 	ACMCertificate:          {ID: ACMCertificate, RdfType: "rdf:Property", RdfsLabel: "ACMCertificate", RdfsDefinedBy: "rdfs:Literal", RdfsDataType: "xsd:string"},
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wallix/awless/aws"
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/config"
+	"github.com/wallix/awless/console"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync"
+)
+
+var (
+	accountsFlag    []string
+	allAccountsFlag bool
+
+	accountColumn = console.SliceColumnDefinition{StringColumnDefinition: console.StringColumnDefinition{Prop: properties.Account}}
+)
+
+func acrossAccounts() bool {
+	return len(accountsFlag) > 0 || allAccountsFlag
+}
+
+// selectedAccounts returns the accounts given with --accounts, or all the accounts synced with --all-accounts
+func selectedAccounts() ([]string, error) {
+	if allAccountsFlag && len(accountsFlag) > 0 {
+		return nil, errors.New("--accounts and --all-accounts are mutually exclusive")
+	}
+	if allAccountsFlag {
+		accounts := sync.SyncedAccounts()
+		if len(accounts) == 0 {
+			return nil, errors.New("no account synced: see `awless sync --profiles`")
+		}
+		return accounts, nil
+	}
+	return accountsFlag, nil
+}
+
+// loadAccountsGraph returns the local graphs of the given services of the selected accounts
+func loadAccountsGraph(serviceNames ...string) (*graph.Graph, error) {
+	accounts, err := selectedAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return sync.LoadAccountGraphs(accounts, serviceNames...)
+}
+
+// loadLocalGraphs returns all the local graphs, of the selected accounts when listing across accounts
+// or at the selected sync revision with --at
+func loadLocalGraphs() (*graph.Graph, error) {
+	if atRevision() {
+		return loadRevisionGraph()
+	}
+	if acrossAccounts() {
+		return loadAccountsGraph()
+	}
+	return sync.LoadAllGraphs()
+}
+
+// newServicesPerAccount returns the services of each given profile, in the configured region,
+// keyed by the id of the account of the profile
+func newServicesPerAccount(profiles []string) (map[string][]cloud.Service, error) {
+	region := config.GetAWSRegion()
+	servicesPerAccount := make(map[string][]cloud.Service)
+	for _, profile := range profiles {
+		awsConf := config.GetConfigWithPrefix("aws.")
+		awsConf[config.ProfileConfigKey] = profile
+		logger.Verbosef("loading AWS session with profile '%v' and region '%v'", profile, region)
+		services, err := aws.NewServicesForRegion(region, awsConf, logger.DefaultLogger)
+		if err != nil {
+			return servicesPerAccount, fmt.Errorf("profile '%s': %s", profile, err)
+		}
+		var account string
+		for _, srv := range services {
+			if access, ok := srv.(*aws.Access); ok {
+				me, err := access.GetIdentity()
+				if err != nil {
+					return servicesPerAccount, fmt.Errorf("profile '%s': cannot get account: %s", profile, err)
+				}
+				account = me.Account
+			}
+		}
+		if account == "" {
+			return servicesPerAccount, fmt.Errorf("profile '%s': cannot get account", profile)
+		}
+		if _, ok := servicesPerAccount[account]; ok {
+			logger.Warningf("profile '%s': account %s already synced with another profile", profile, account)
+			continue
+		}
+		servicesPerAccount[account] = services
+	}
+	return servicesPerAccount, nil
+}
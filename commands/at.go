@@ -0,0 +1,81 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync"
+	"github.com/wallix/awless/sync/repo"
+)
+
+var (
+	atFlag string
+
+	atRev *repo.Rev
+)
+
+func atRevision() bool {
+	return atFlag != ""
+}
+
+// selectedRevision returns the sync revision given with --at
+func selectedRevision() (*repo.Rev, error) {
+	if atRev != nil {
+		return atRev, nil
+	}
+	if acrossAccounts() {
+		return nil, errors.New("--at cannot be used along with --accounts or --all-accounts")
+	}
+	revs, err := sync.DefaultSyncer.List()
+	if err != nil {
+		return nil, err
+	}
+	found, err := repo.FindRev(revs, atFlag, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	logger.Verbosef("loading sync revision %s of %s", found.Id[:7], found.DateString())
+	if atRev, err = sync.DefaultSyncer.LoadRev(found.Id); err != nil {
+		return nil, err
+	}
+	return atRev, nil
+}
+
+// loadRevisionGraph returns the graphs of the given services (all of them if none given)
+// at the sync revision given with --at
+func loadRevisionGraph(serviceNames ...string) (*graph.Graph, error) {
+	rev, err := selectedRevision()
+	if err != nil {
+		return nil, err
+	}
+	if len(serviceNames) == 0 {
+		for name := range rev.Graphs {
+			serviceNames = append(serviceNames, name)
+		}
+		sort.Strings(serviceNames)
+	}
+	g := graph.NewGraph()
+	for _, name := range serviceNames {
+		g.AddGraph(rev.Graph(name))
+	}
+	return g, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wallix/awless/aws"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/sync"
+)
+
+var (
+	exportFormatFlag   string
+	exportServicesFlag []string
+	exportTypesFlag    []string
+	exportOutputFlag   string
+)
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFormatFlag, "format", "json", fmt.Sprintf("Export format: %s", strings.Join(graph.ExportFormats, ", ")))
+	exportCmd.Flags().StringSliceVar(&exportServicesFlag, "service", []string{}, fmt.Sprintf("Export only the resources of the given services: %s", strings.Join(aws.ServiceNames, ", ")))
+	exportCmd.Flags().StringSliceVar(&exportTypesFlag, "type", []string{}, "Export only the resources of the given types. Ex: --type instance,subnet")
+	exportCmd.Flags().StringVarP(&exportOutputFlag, "output", "o", "", "Write the export to the given file instead of the standard output")
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export your local cloud resources and their relations (see `awless sync`) to standard formats",
+	Long: fmt.Sprintf(`Export your local cloud resources and their relations (see 'awless sync') to:
+  json      nested inventory of resources following their parents
+  turtle    RDF triples in Turtle
+  jsonld    RDF triples in JSON-LD
+  graphml   resources and relations as GraphML nodes and edges
+  cypher    Neo4j Cypher statements merging resources and relations
+
+Only the relations between exported resources are kept.
+RDF namespaces are declared under %s`, graph.ExportBaseIRI),
+	Example: `  awless export > inventory.json
+  awless export --format turtle --service infra -o infra.ttl
+  awless export --format cypher --type instance,subnet,vpc | cypher-shell
+  awless export --format graphml --service infra,access`,
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		services := exportServicesFlag
+		if len(services) == 0 {
+			services = aws.ServiceNames
+		}
+
+		g := graph.NewGraph()
+		for _, srv := range services {
+			if _, ok := aws.ResourceTypesPerServiceName()[srv]; !ok {
+				exitOn(fmt.Errorf("export: unknown service '%s', expecting any of %s", srv, strings.Join(aws.ServiceNames, ", ")))
+			}
+			srvGraph, err := sync.LoadCurrentLocalGraph(srv)
+			exitOn(err)
+			g.AddGraph(srvGraph)
+		}
+
+		var types []string
+		for _, typ := range exportTypesFlag {
+			resolved, err := resolveResourceTypeName(typ)
+			exitOn(err)
+			types = append(types, resolved)
+		}
+		if len(types) == 0 {
+			for _, srv := range services {
+				types = append(types, aws.ResourceTypesPerServiceName()[srv]...)
+			}
+		}
+
+		out := os.Stdout
+		if exportOutputFlag != "" {
+			f, err := os.Create(exportOutputFlag)
+			exitOn(err)
+			defer f.Close()
+			out = f
+		}
+
+		exitOn(g.Export(out, exportFormatFlag, types...))
+		return nil
+	},
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wallix/awless/aws/driver"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync"
+	"github.com/wallix/awless/template"
+)
+
+func init() {
+	RootCmd.AddCommand(generateCmd)
+
+	generateCmd.AddCommand(generateTeardownCmd)
+	generateCmd.AddCommand(generateTemplateCmd)
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate templates from your local cloud resources (see `awless sync`)",
+}
+
+var generateTeardownCmd = &cobra.Command{
+	Use:   "teardown REFERENCE",
+	Short: "Generate the template deleting a resource and everything beneath it in a safe order",
+	Long: `Generate the template deleting a resource and everything beneath it in a safe order:
+children before their parents, resources before the ones applying on them,
+with the checks waiting for the deletions to complete.
+
+Resources applying only on the given one (ex: the internet gateway of a vpc) are deleted too.
+Shared resources (keypairs, images, policies) and resources with no delete action are skipped.
+Review the generated template before running it.`,
+	Example: `  awless generate teardown vpc-12345678 > teardown.awls
+  awless generate teardown @my-subnet
+  awless run teardown.awls`,
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing REFERENCE")
+		}
+
+		g, err := sync.LoadAllGraphs()
+		exitOn(err)
+
+		root, err := resolveListingReference(g, args[0])
+		exitOn(err)
+
+		resources, err := g.TeardownOrder(root)
+		exitOn(err)
+
+		tpl, skipped, err := template.Teardown(resources, awsdriver.AWSLookupDefinitions)
+		for _, res := range skipped {
+			logger.Warningf("skipping %s: not deleted by the template", res)
+		}
+		exitOn(err)
+
+		fmt.Println(tpl)
+		return nil
+	},
+}
+
+var generateTemplateCmd = &cobra.Command{
+	Use:   "template REFERENCE",
+	Short: "Generate the template creating again a resource and everything beneath it (ex: vpc, subnet, scalinggroup)",
+	Long: `Generate the template creating again a resource and everything beneath it, parents before their children.
+
+Created resources are declared as variables referenced by the resources created after them,
+and their params are mapped back from their properties through the template definitions.
+Required params with no known value are left as holes to fill in when running the template.
+Security group rules, routes, route table associations and internet gateway attachments follow.
+Resources created along with their vpc and resources with no create action are skipped.`,
+	Example: `  awless generate template vpc-12345678 > vpc.awls
+  awless generate template @my-scalinggroup
+  awless run vpc.awls`,
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing REFERENCE")
+		}
+
+		g, err := sync.LoadAllGraphs()
+		exitOn(err)
+
+		root, err := resolveListingReference(g, args[0])
+		exitOn(err)
+
+		teardown, err := g.TeardownOrder(root)
+		exitOn(err)
+
+		var resources []*graph.Resource
+		for i := len(teardown) - 1; i >= 0; i-- {
+			resources = append(resources, teardown[i])
+		}
+
+		tpl, skipped, err := template.Generate(g, resources, awsdriver.AWSLookupDefinitions)
+		for _, res := range skipped {
+			logger.Warningf("skipping %s: not created by the template", res)
+		}
+		exitOn(err)
+
+		fmt.Println(tpl)
+		return nil
+	},
+}
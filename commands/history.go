@@ -19,13 +19,17 @@ package commands
 import (
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wallix/awless/aws"
 	"github.com/wallix/awless/cloud"
 	"github.com/wallix/awless/config"
 	"github.com/wallix/awless/console"
+	"github.com/wallix/awless/database"
 	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
 	"github.com/wallix/awless/sync"
 	"github.com/wallix/awless/sync/repo"
 )
@@ -37,20 +41,27 @@ var (
 func init() {
 	RootCmd.AddCommand(historyCmd)
 
-	historyCmd.Flags().BoolVarP(&showProperties, "properties", "p", false, "Full diff with resources properties")
+	historyCmd.Flags().BoolVar(&showProperties, "properties", false, "Full diff with resources properties")
 }
 
 var historyCmd = &cobra.Command{
-	Use:               "history",
-	Hidden:            true,
-	Short:             "(in progress) Show a infra resource history & changes using your locally sync snapshots",
+	Use:    "history [REFERENCE]",
+	Hidden: true,
+	Short:  "(in progress) Show the history & changes of the resources of all services using your locally sync snapshots",
+	Long: `Show the history & changes of the resources of all services using your locally sync snapshots.
+
+Given a REFERENCE (id or name), show the timeline of a single resource across the sync revisions:
+when it appeared, its property and relation changes, when it disappeared, along with
+the template runs (see 'awless log') referencing it.`,
+	Example: `  awless history
+  awless history i-8d43b21b
+  awless history @my-instance`,
 	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
 	PersistentPostRun: applyHooks(verifyNewVersionHook),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !repo.IsGitInstalled() {
-			fmt.Printf("No history available. You need to install git")
-			os.Exit(0)
+		if len(args) > 0 {
+			return showResourceHistory(args[0])
 		}
 
 		region := config.GetAWSRegion()
@@ -76,7 +87,9 @@ var historyCmd = &cobra.Command{
 		}
 
 		for _, diff := range diffs {
-			displayRevisionDiff(diff, aws.InfraService.Name(), root, verboseGlobalFlag)
+			for _, name := range aws.ServiceNames {
+				displayRevisionDiff(diff, name, root, verboseGlobalFlag)
+			}
 		}
 
 		return nil
@@ -89,9 +102,9 @@ func displayRevisionDiff(diff *sync.Diff, cloudService string, root *graph.Resou
 		fromRevision = diff.From.Id[:7] + " on " + diff.From.Date.Format("Monday January 2, 15:04")
 	}
 
-	var graphdiff *graph.Diff
-	if cloudService == aws.InfraService.Name() {
-		graphdiff = diff.InfraDiff
+	graphdiff, ok := diff.Diffs[cloudService]
+	if !ok {
+		return
 	}
 
 	if showProperties {
@@ -128,3 +141,106 @@ func displayRevisionDiff(diff *sync.Diff, cloudService string, root *graph.Resou
 		}
 	}
 }
+
+// showResourceHistory prints the timeline of a resource: its changes across the sync revisions
+// and the template runs referencing its id or name
+func showResourceHistory(ref string) error {
+	id := deprefix(ref)
+	refs := []string{id}
+	switch resources := resolveResourceFromRef(ref); len(resources) {
+	case 0:
+	case 1:
+		id = resources[0].Id()
+		refs = []string{id}
+	default:
+		return fmt.Errorf("%d resources found with name '%s': use the id", len(resources), deprefix(ref))
+	}
+
+	all, err := sync.DefaultSyncer.List()
+	if err != nil {
+		return err
+	}
+	var revs []*repo.Rev
+	for _, r := range all {
+		rev, err := sync.DefaultSyncer.LoadRev(r.Id)
+		if err != nil {
+			return err
+		}
+		revs = append(revs, rev)
+	}
+
+	changes, err := sync.ResourceHistory(revs, id)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if name, ok := change.Resource.Properties["Name"].(string); ok && name != "" && !containsString(refs, name) {
+			refs = append(refs, name)
+		}
+	}
+
+	type event struct {
+		date  time.Time
+		lines []string
+	}
+	var events []event
+
+	for _, change := range changes {
+		title := fmt.Sprintf("revision %s: %s %s", change.Rev.Id[:7], change.Resource, change.Kind)
+		switch change.Kind {
+		case sync.ResourceAppeared:
+			title = renderGreenFn(title)
+		case sync.ResourceDisappeared:
+			title = renderRedFn(title)
+		}
+		lines := []string{title}
+		for _, prop := range change.Properties {
+			lines = append(lines, fmt.Sprintf("\t%s", prop))
+		}
+		for _, rel := range change.Relations {
+			sign := "-"
+			if rel.Added {
+				sign = "+"
+			}
+			lines = append(lines, fmt.Sprintf("\t%s %s %s", sign, rel.Relation, rel.Resource))
+		}
+		events = append(events, event{date: change.Rev.Date, lines: lines})
+	}
+
+	var templates []*database.LoadedTemplate
+	err = database.Execute(func(db *database.DB) (dberr error) {
+		templates, dberr = db.ListTemplates()
+		return
+	})
+	if err != nil {
+		return err
+	}
+	for _, loaded := range templates {
+		if loaded.Err != nil {
+			continue
+		}
+		commands := loaded.TplExec.CommandsReferencing(refs...)
+		if len(commands) == 0 {
+			continue
+		}
+		lines := []string{renderCyanBoldFn(fmt.Sprintf("template %s", loaded.TplExec.ID))}
+		for _, cmd := range commands {
+			lines = append(lines, fmt.Sprintf("\t%s", cmd))
+		}
+		events = append(events, event{date: loaded.TplExec.Date(), lines: lines})
+	}
+
+	if len(events) == 0 {
+		logger.Infof("no history found for %s", ref)
+		return nil
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].date.Before(events[j].date) })
+	for _, e := range events {
+		fmt.Printf("%s  %s\n", e.date.Format("Mon Jan 2 15:04:05"), e.lines[0])
+		for _, line := range e.lines[1:] {
+			fmt.Println(line)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wallix/awless/aws"
@@ -63,7 +64,7 @@ func initAwlessEnvHook(cmd *cobra.Command, args []string) error {
 }
 
 func initCloudServicesHook(cmd *cobra.Command, args []string) error {
-	if localGlobalFlag {
+	if localGlobalFlag || acrossAccounts() || atRevision() {
 		return nil
 	}
 	awsConf := config.GetConfigWithPrefix("aws.")
@@ -80,10 +81,21 @@ func initCloudServicesHook(cmd *cobra.Command, args []string) error {
 }
 
 func initSyncerHook(cmd *cobra.Command, args []string) error {
-	sync.DefaultSyncer = sync.NewSyncer(logger.DefaultLogger)
+	sync.DefaultSyncer = sync.NewIncrementalSyncer(syncScope(), config.GetSyncTTL, config.GetSyncRetention(), logger.DefaultLogger)
 	return nil
 }
 
+// forcedSyncer returns a syncer fetching again all the resources of the synced services, regardless
+// of their TTL, for the commands needing them as they are in the cloud (ex: after running a template)
+func forcedSyncer() sync.Syncer {
+	return sync.NewIncrementalSyncer(syncScope(), func(string) time.Duration { return 0 }, config.GetSyncRetention(), logger.DefaultLogger)
+}
+
+// syncScope is the scope (profile and region) of the last sync times of the local graphs
+func syncScope() string {
+	return fmt.Sprintf("%s/%s", config.GetAWSProfile(), config.GetAWSRegion())
+}
+
 func initLoggerHook(cmd *cobra.Command, args []string) error {
 	var flag int
 	if verboseGlobalFlag {
@@ -0,0 +1,119 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wallix/awless/aws"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync"
+)
+
+var (
+	importFormatFlag  string
+	importReplaceFlag bool
+
+	importNameRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+)
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFormatFlag, "format", "", fmt.Sprintf("Import format: %s (default guessed from the file extension: .json, .ttl, .nt)", strings.Join(graph.ImportFormats, ", ")))
+	importCmd.Flags().BoolVar(&importReplaceFlag, "replace", false, "Replace the named local graph instead of merging into it")
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import NAME FILE",
+	Short: "Import resources from other sources into a named local graph, queried along your synced resources",
+	Long: `Import resources and relations from other sources (on-premise inventory, CMDB, ...) into a named local graph.
+Named local graphs are loaded along the synced services graphs (see 'awless query', 'awless list --in').
+
+FILE ('-' for the standard input) is either:
+  json              inventory as given by 'awless export': resources with id, type, properties (by awless property name), appliesOn and children
+  turtle, ntriples  RDF triples using the awless types and predicates (cloud-owl:Instance, cloud:name, cloud-rel:parentOf, ...)
+
+Resource types are the awless ones and property values are converted to their awless datatypes.`,
+	Example: `  awless import cmdb servers.json
+  awless import onprem inventory.ttl --replace
+  curl -s https://cmdb.local/export.nt | awless import cmdb - --format ntriples`,
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("missing NAME or FILE")
+		}
+		name, path := args[0], args[1]
+
+		if !importNameRegex.MatchString(name) {
+			exitOn(fmt.Errorf("invalid graph name '%s': expecting lowercase letters, digits, '-' or '_'", name))
+		}
+		for _, srv := range aws.ServiceNames {
+			if name == srv {
+				exitOn(fmt.Errorf("invalid graph name '%s': reserved for the synced service", name))
+			}
+		}
+
+		format := importFormatFlag
+		if format == "" {
+			format = importFormatFromExtension(path)
+		}
+
+		var r io.Reader = os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			exitOn(err)
+			defer f.Close()
+			r = f
+		}
+
+		g := graph.NewGraph()
+		if !importReplaceFlag {
+			var err error
+			g, err = sync.LoadCurrentLocalGraph(name)
+			exitOn(err)
+		}
+		exitOn(g.Import(r, format, aws.ResourceTypes...))
+		exitOn(sync.SaveLocalGraph(name, g))
+
+		resources, err := g.GetAllResources(aws.ResourceTypes...)
+		exitOn(err)
+		logger.Infof("local graph '%s' now holds %d resources", name, len(resources))
+		return nil
+	},
+}
+
+func importFormatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ttl":
+		return "turtle"
+	case ".nt":
+		return "ntriples"
+	default:
+		return "json"
+	}
+}
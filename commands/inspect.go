@@ -43,7 +43,7 @@ var inspectCmd = &cobra.Command{
 	Short: fmt.Sprintf(
 		"Inspecting your infrastructure using available inspectors: %s", allInspectors(),
 	),
-	Example:           "  awless inspect -i bucket_sizer\n  awless inspect -i pricer\n  awless inspect -i port_scanner",
+	Example:           "  awless inspect -i bucket_sizer\n  awless inspect -i pricer\n  awless inspect -i port_scanner\n  awless inspect -i consistency --local",
 	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
 	PersistentPostRun: applyHooks(verifyNewVersionHook),
 
@@ -53,14 +53,14 @@ var inspectCmd = &cobra.Command{
 			return fmt.Errorf("command needs a valid inspector: %s", allInspectors())
 		}
 
-		if !localGlobalFlag {
+		if !localGlobalFlag && !skipSyncForDaemon() {
 			logger.Info("Running full sync before inspection (disable it with --local flag)\n")
 			var services []cloud.Service
 			for _, srv := range cloud.ServiceRegistry {
 				services = append(services, srv)
 			}
 
-			if _, err := sync.DefaultSyncer.Sync(services...); err != nil {
+			if _, err := forcedSyncer().Sync(services...); err != nil {
 				logger.Verbose(err)
 			}
 		}
@@ -17,16 +17,20 @@ limitations under the License.
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	gosync "sync"
 
 	"github.com/spf13/cobra"
 	"github.com/wallix/awless/aws"
 	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/cloud/properties"
 	"github.com/wallix/awless/console"
 	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
 	"github.com/wallix/awless/sync"
 )
 
@@ -37,6 +41,10 @@ var (
 	listingTagKeyFiltersFlag   []string
 	listingTagValueFiltersFlag []string
 	listOnlyIDs                bool
+	listingInFlag              string
+	listingRelatedToFlag       string
+	listingRegionsFlag         []string
+	listingAllRegionsFlag      bool
 	sortBy                     []string
 )
 
@@ -61,10 +69,17 @@ func init() {
 	}
 
 	listCmd.PersistentFlags().StringVar(&listingFormat, "format", "table", "Output format: table, csv, tsv, json (default to table)")
-	listCmd.PersistentFlags().StringSliceVar(&listingFiltersFlag, "filter", []string{}, "Filter resources given key/values fields (case insensitive). Ex: --filter type=t2.micro")
+	listCmd.PersistentFlags().StringSliceVar(&listingFiltersFlag, "filter", []string{}, "Filter resources given key/values fields (case insensitive). Operators: =, !=, =~, >, >=, <, <=, in, exists, not, or. Times compare with dates ('>' meaning after) or with durations as ages ('>' meaning older). Ex: --filter type=t2.micro, --filter 'launched>30d' (more than 30 days ago), --filter 'launched<2017-07-01' (before July 2017)")
 	listCmd.PersistentFlags().StringSliceVar(&listingTagFiltersFlag, "tag", []string{}, "Filter EC2 resources given tags (case sensitive!). Ex: --tag Env=Production")
 	listCmd.PersistentFlags().StringSliceVar(&listingTagKeyFiltersFlag, "tag-key", []string{}, "Filter EC2 resources given a tag key only (case sensitive!). Ex: --tag-key Env")
 	listCmd.PersistentFlags().StringSliceVar(&listingTagValueFiltersFlag, "tag-value", []string{}, "Filter EC2 resources given a tag value only (case sensitive!). Ex: --tag-value Staging")
+	listCmd.PersistentFlags().StringVar(&listingInFlag, "in", "", "List only resources contained in the given resource (id, name or @name), walking parent relations. Ex: --in vpc-12345")
+	listCmd.PersistentFlags().StringVar(&listingRelatedToFlag, "related-to", "", "List only resources related to the given resource (id, name or @name): parents, children, resources applying on or depending on it. Ex: --related-to @my-sg")
+	listCmd.PersistentFlags().StringSliceVar(&listingRegionsFlag, "regions", []string{}, "List resources of the given regions, merged with a Region column. Ex: --regions eu-west-1,us-east-1")
+	listCmd.PersistentFlags().BoolVar(&listingAllRegionsFlag, "all-regions", false, "List resources of all regions (all regions synced locally when --local), merged with a Region column")
+	listCmd.PersistentFlags().StringSliceVar(&accountsFlag, "accounts", []string{}, "List local resources of the given accounts synced with `awless sync --profiles`, merged with an Account column. Ex: --accounts 123456789012")
+	listCmd.PersistentFlags().BoolVar(&allAccountsFlag, "all-accounts", false, "List local resources of all the accounts synced with `awless sync --profiles`, merged with an Account column")
+	listCmd.PersistentFlags().StringVar(&atFlag, "at", "", "List local resources as synced at the given date, duration ago or sync revision. Ex: --at 2017-06-20, --at 2d, --at 3f2a9c1")
 	listCmd.PersistentFlags().BoolVar(&listOnlyIDs, "ids", false, "List only ids")
 	listCmd.PersistentFlags().StringSliceVar(&sortBy, "sort", []string{"Id"}, "Sort tables by column(s) name(s)")
 }
@@ -72,8 +87,8 @@ func init() {
 var listCmd = &cobra.Command{
 	Use:               "list",
 	Aliases:           []string{"ls"},
-	Example:           "  awless list instances --sort uptime\n  awless list users --format csv\n  awless list volumes --filter state=use --filter type=gp2\n  awless list volumes --tag-value Purchased\n  awless list vpcs --tag-key Dept --tag-key Internal\n  awless list instances --tag Env=Production,Dept=Marketing\n  awless list instances --filter state=running,type=micro\n  awless list s3objects --filter bucket=pdf-bucket ",
-	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook),
+	Example:           "  awless list instances --sort uptime\n  awless list users --format csv\n  awless list volumes --filter state=use --filter type=gp2\n  awless list volumes --tag-value Purchased\n  awless list vpcs --tag-key Dept --tag-key Internal\n  awless list instances --tag Env=Production,Dept=Marketing\n  awless list instances --filter state=running,type=micro\n  awless list s3objects --filter bucket=pdf-bucket\n  awless list instances --filter 'launched>30d' --filter 'privateip in 10.0.0.0/16'\n  awless list instances --filter 'state=running or state=pending' --filter 'not name=~^prod'\n  awless list volumes --filter 'size>=100G' --filter 'exists name'\n  awless list instances --in vpc-12345\n  awless list volumes --related-to @my-instance\n  awless list instances --regions eu-west-1,us-east-1\n  awless list vpcs --all-regions --local\n  awless list users --all-accounts\n  awless list instances --at 2017-06-20",
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
 	PersistentPostRun: applyHooks(verifyNewVersionHook),
 	Short:             "List various type of resources",
 }
@@ -86,9 +101,60 @@ var listSpecificResourceCmd = func(resType string) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			var g *graph.Graph
 
+			if atRevision() {
+				if len(listingRegionsFlag) > 0 || listingAllRegionsFlag {
+					exitOn(errors.New("--at cannot be used along with --regions or --all-regions"))
+				}
+				srvName, ok := aws.ServicePerResourceType[resType]
+				if !ok {
+					exitOn(fmt.Errorf("cannot find service for resource type %s", resType))
+				}
+				var err error
+				g, err = loadRevisionGraph(srvName)
+				exitOn(err)
+				if listingInFlag != "" || listingRelatedToFlag != "" {
+					g, err = filterLocalRelatedResources(g, resType)
+					exitOn(err)
+				}
+				printResources(g, resType)
+				return
+			}
+
+			if acrossAccounts() {
+				if len(listingRegionsFlag) > 0 || listingAllRegionsFlag {
+					exitOn(errors.New("listing across accounts and regions at once is not supported"))
+				}
+				var err error
+				g, err = loadAccountsGraph(aws.ServicePerResourceType[resType])
+				exitOn(err)
+				if listingInFlag != "" || listingRelatedToFlag != "" {
+					g, err = filterLocalRelatedResources(g, resType)
+					exitOn(err)
+				}
+				printResourcesWithHeaders(g, resType, console.ExtendColumns(console.DefaultsColumnDefinitions[resType], accountColumn))
+				return
+			}
+
+			if len(listingRegionsFlag) > 0 || listingAllRegionsFlag {
+				regions, err := selectedRegions(listingRegionsFlag, listingAllRegionsFlag, localGlobalFlag)
+				exitOn(err)
+				g, err = loadRegionsGraph(regions, resType)
+				exitOn(err)
+				if listingInFlag != "" || listingRelatedToFlag != "" {
+					all, err := loadLocalRegionsGraph(regions)
+					exitOn(err)
+					g, err = filterRelatedResources(g, all, resType)
+					exitOn(err)
+				}
+				printResourcesWithHeaders(g, resType, console.ExtendColumns(console.DefaultsColumnDefinitions[resType], console.StringColumnDefinition{Prop: properties.Region}))
+				return
+			}
+
 			if localGlobalFlag {
 				if srvName, ok := aws.ServicePerResourceType[resType]; ok {
-					g = sync.LoadCurrentLocalGraph(srvName)
+					var err error
+					g, err = sync.LoadCurrentLocalGraph(srvName)
+					exitOn(err)
 				} else {
 					exitOn(fmt.Errorf("cannot find service for resource type %s", resType))
 				}
@@ -99,6 +165,12 @@ var listSpecificResourceCmd = func(resType string) *cobra.Command {
 				exitOn(err)
 			}
 
+			if listingInFlag != "" || listingRelatedToFlag != "" {
+				var err error
+				g, err = filterLocalRelatedResources(g, resType)
+				exitOn(err)
+			}
+
 			printResources(g, resType)
 		},
 	}
@@ -111,7 +183,17 @@ var listAllResourceInServiceCmd = func(srvName string) *cobra.Command {
 		Hidden: true,
 
 		Run: func(cmd *cobra.Command, args []string) {
-			g := sync.LoadCurrentLocalGraph(srvName)
+			g, err := sync.LoadCurrentLocalGraph(srvName)
+			exitOn(err)
+			if atRevision() {
+				g, err = loadRevisionGraph(srvName)
+				exitOn(err)
+			}
+			if listingInFlag != "" || listingRelatedToFlag != "" {
+				var err error
+				g, err = filterLocalRelatedResources(g, aws.ResourceTypesPerServiceName()[srvName]...)
+				exitOn(err)
+			}
 			displayer, err := console.BuildOptions(
 				console.WithFormat(listingFormat),
 				console.WithMaxWidth(console.GetTerminalWidth()),
@@ -123,10 +205,141 @@ var listAllResourceInServiceCmd = func(srvName string) *cobra.Command {
 	}
 }
 
+// loadRegionsGraph returns the resources of the given type of all regions,
+// fetched concurrently or loaded from the local graphs of the regions
+func loadRegionsGraph(regions []string, resType string) (*graph.Graph, error) {
+	srvName, ok := aws.ServicePerResourceType[resType]
+	if !ok {
+		return nil, fmt.Errorf("cannot find service for resource type %s", resType)
+	}
+
+	graphs := make(map[string]*graph.Graph)
+	if localGlobalFlag {
+		for _, region := range regions {
+			g, err := loadLocalRegionGraph(region, srvName)
+			if err != nil {
+				return nil, err
+			}
+			graphs[region] = g
+		}
+		return sync.MergeRegionGraphs(graphs, resType)
+	}
+
+	servicesPerRegion, err := newServicesPerRegion(regions)
+	if err != nil {
+		return nil, err
+	}
+
+	var workers gosync.WaitGroup
+	var mu gosync.Mutex
+	for region, services := range servicesPerRegion {
+		for _, srv := range services {
+			if srv.Name() != srvName {
+				continue
+			}
+			workers.Add(1)
+			go func(region string, srv cloud.Service) {
+				defer workers.Done()
+				g, err := srv.FetchByType(resType)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					logger.Warningf("listing %s in %s: %s", cloud.PluralizeResource(resType), region, err)
+					return
+				}
+				graphs[region] = g
+			}(region, srv)
+		}
+	}
+	workers.Wait()
+
+	return sync.MergeRegionGraphs(graphs, resType)
+}
+
+// loadLocalRegionsGraph returns the local graphs of all the services of the given regions
+func loadLocalRegionsGraph(regions []string) (*graph.Graph, error) {
+	all := graph.NewGraph()
+	for _, region := range regions {
+		for _, srvName := range aws.ServiceNames {
+			g, err := loadLocalRegionGraph(region, srvName)
+			if err != nil {
+				return all, err
+			}
+			all.AddGraph(g)
+		}
+	}
+	return all, nil
+}
+
+// filterLocalRelatedResources keeps the resources of the given types matching --in and --related-to
+// in the local graphs (of the revision or accounts listed if any)
+func filterLocalRelatedResources(g *graph.Graph, resTypes ...string) (*graph.Graph, error) {
+	all, err := loadLocalGraphs()
+	if err != nil {
+		return g, err
+	}
+	return filterRelatedResources(g, all, resTypes...)
+}
+
+// filterRelatedResources keeps the resources of the given types matching --in and --related-to,
+// the references and their relations being resolved in the given graph
+func filterRelatedResources(g, all *graph.Graph, resTypes ...string) (*graph.Graph, error) {
+	var filters []graph.FilterFn
+	if listingInFlag != "" {
+		ref, err := resolveListingReference(all, listingInFlag)
+		if err != nil {
+			return g, err
+		}
+		in, err := all.ListResourcesIn(ref)
+		if err != nil {
+			return g, err
+		}
+		filters = append(filters, graph.BuildResourcesFilterFunc(in...))
+	}
+	if listingRelatedToFlag != "" {
+		ref, err := resolveListingReference(all, listingRelatedToFlag)
+		if err != nil {
+			return g, err
+		}
+		related, err := all.ListRelatedResources(ref)
+		if err != nil {
+			return g, err
+		}
+		filters = append(filters, graph.BuildResourcesFilterFunc(related...))
+	}
+
+	filtered := graph.NewGraph()
+	for _, resType := range resTypes {
+		typed, err := g.Filter(resType, filters...)
+		if err != nil {
+			return g, err
+		}
+		filtered.AddGraph(typed)
+	}
+	return filtered, nil
+}
+
+func resolveListingReference(g *graph.Graph, ref string) (*graph.Resource, error) {
+	resources := resolveResourceFromRefIn(g, ref)
+	switch len(resources) {
+	case 0:
+		return nil, fmt.Errorf("resource with reference %s not found", deprefix(ref))
+	case 1:
+		return resources[0], nil
+	default:
+		all := graph.Resources(resources).Map(func(r *graph.Resource) string { return r.String() })
+		return nil, fmt.Errorf("%d resources found with reference '%s': %s. Use the id instead", len(resources), deprefix(ref), strings.Join(all, ", "))
+	}
+}
+
 func printResources(g *graph.Graph, resType string) {
+	printResourcesWithHeaders(g, resType, console.DefaultsColumnDefinitions[resType])
+}
+
+func printResourcesWithHeaders(g *graph.Graph, resType string, headers []console.ColumnDefinition) {
 	displayer, err := console.BuildOptions(
 		console.WithRdfType(resType),
-		console.WithHeaders(console.DefaultsColumnDefinitions[resType]),
+		console.WithHeaders(headers),
 		console.WithFilters(listingFiltersFlag),
 		console.WithTagFilters(listingTagFiltersFlag),
 		console.WithTagKeyFilters(listingTagKeyFiltersFlag),
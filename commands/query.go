@@ -0,0 +1,113 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wallix/awless/aws"
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/console"
+	"github.com/wallix/awless/graph"
+)
+
+func init() {
+	RootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringVar(&listingFormat, "format", "table", "Output format: table, csv, tsv, json (default to table)")
+	queryCmd.Flags().BoolVar(&listOnlyIDs, "ids", false, "List only ids")
+	queryCmd.Flags().StringSliceVar(&sortBy, "sort", []string{"Id"}, "Sort tables by column(s) name(s)")
+	queryCmd.Flags().StringSliceVar(&accountsFlag, "accounts", []string{}, "Query local resources of the given accounts synced with `awless sync --profiles`, with an Account column")
+	queryCmd.Flags().StringVar(&atFlag, "at", "", "Query local resources as synced at the given date, duration ago or sync revision. Ex: --at 2017-06-20, --at 2d, --at 3f2a9c1")
+	queryCmd.Flags().BoolVar(&allAccountsFlag, "all-accounts", false, "Query local resources of all the accounts synced with `awless sync --profiles`, with an Account column")
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query QUERY",
+	Short: "Query your local cloud resources with the awless query language",
+	Long: `Query your local cloud resources (see 'awless sync') with the awless query language:
+
+  TYPE [where CONDITION]
+
+Conditions are combined with 'and', 'or', 'not' and parentheses:
+  PROPERTY = VALUE, !=, ~ (contains), !~, >, >=, <, <=
+  exists PROPERTY
+  PROPERTY in (SUBQUERY)                 property referencing the resources of the subquery
+  PROPERTY allows PORT [from CIDR]       firewall rules allowing the port (from the whole CIDR)
+
+Times compare with dates ('>' meaning after) or with durations as ages ('>' meaning older, ex: launched > 30d)`,
+	Example: `  awless query "instance where state = running and name ~ prod"
+  awless query "volumes where size >= 100"
+  awless query "instance where subnet = subnet-12345 and securitygroups in (securitygroup where inboundrules allows 22 from 0.0.0.0/0)"
+  awless query "instances where state = running" --all-accounts
+  awless query "instances where state = running" --at 2017-06-20`,
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing QUERY")
+		}
+
+		q, err := graph.ParseQuery(strings.Join(args, " "))
+		exitOn(err)
+
+		for _, sub := range q.Queries() {
+			sub.Type, err = resolveResourceTypeName(sub.Type)
+			exitOn(err)
+		}
+
+		g, err := loadLocalGraphs()
+		exitOn(err)
+
+		resources, err := g.ResolveResources(q)
+		exitOn(err)
+
+		result := graph.NewGraph()
+		exitOn(result.AddResource(resources...))
+
+		headers := console.DefaultsColumnDefinitions[q.Type]
+		if acrossAccounts() {
+			headers = console.ExtendColumns(headers, accountColumn)
+		}
+		displayer, err := console.BuildOptions(
+			console.WithRdfType(q.Type),
+			console.WithHeaders(headers),
+			console.WithMaxWidth(console.GetTerminalWidth()),
+			console.WithFormat(listingFormat),
+			console.WithIDsOnly(listOnlyIDs),
+			console.WithSortBy(sortBy...),
+		).SetSource(result).Build()
+		exitOn(err)
+
+		exitOn(displayer.Print(os.Stdout))
+		return nil
+	},
+}
+
+func resolveResourceTypeName(typ string) (string, error) {
+	for _, rt := range aws.ResourceTypes {
+		if typ == rt || typ == cloud.PluralizeResource(rt) {
+			return rt, nil
+		}
+	}
+	return typ, fmt.Errorf("unknown resource type '%s'", typ)
+}
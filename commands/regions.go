@@ -0,0 +1,90 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wallix/awless/aws"
+	awsconfig "github.com/wallix/awless/aws/config"
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/config"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync"
+)
+
+// selectedRegions returns the regions given with --regions, or with --all-regions:
+// the regions synced locally (along with the configured one) when local, all the public regions otherwise
+func selectedRegions(regions []string, allRegions, local bool) ([]string, error) {
+	if allRegions && len(regions) > 0 {
+		return nil, errors.New("--regions and --all-regions are mutually exclusive")
+	}
+	if allRegions {
+		if !local {
+			return awsconfig.PublicRegions(), nil
+		}
+		regions = sync.SyncedRegions()
+		if current := config.GetAWSRegion(); current != "" && !containsString(regions, current) {
+			regions = append(regions, current)
+		}
+		return regions, nil
+	}
+	for _, region := range regions {
+		if !awsconfig.IsValidRegion(region) {
+			return nil, fmt.Errorf("invalid region '%s' provided", region)
+		}
+	}
+	return regions, nil
+}
+
+func newServicesPerRegion(regions []string) (map[string][]cloud.Service, error) {
+	awsConf := config.GetConfigWithPrefix("aws.")
+	if _, ok := awsConf[config.ProfileConfigKey]; !ok {
+		awsConf[config.ProfileConfigKey] = "default"
+	}
+
+	servicesPerRegion := make(map[string][]cloud.Service)
+	for _, region := range regions {
+		logger.Verbosef("loading AWS session with profile '%v' and region '%v'", awsConf[config.ProfileConfigKey], region)
+		services, err := aws.NewServicesForRegion(region, awsConf, logger.DefaultLogger)
+		if err != nil {
+			return servicesPerRegion, err
+		}
+		servicesPerRegion[region] = services
+	}
+	return servicesPerRegion, nil
+}
+
+// loadLocalRegionGraph returns the local graph of a service for the region,
+// falling back on the graph synced by default when the region is the configured one
+func loadLocalRegionGraph(region, srvName string) (*graph.Graph, error) {
+	if region == config.GetAWSRegion() && !containsString(sync.SyncedRegions(), region) {
+		return sync.LoadCurrentLocalGraph(srvName)
+	}
+	return sync.LoadRegionGraph(region, srvName)
+}
+
+func containsString(arr []string, s string) bool {
+	for _, a := range arr {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wallix/awless/logger"
+)
+
+const (
+	PINNED_CHECKSUM_SEP = "@sha256:"
+	SIGNATURE_EXT       = ".sig"
+)
+
+// splitPinnedChecksum splits a template path as 'path@sha256:checksum'
+func splitPinnedChecksum(path string) (string, string) {
+	if i := strings.LastIndex(path, PINNED_CHECKSUM_SEP); i > -1 {
+		return path[:i], strings.ToLower(path[i+len(PINNED_CHECKSUM_SEP):])
+	}
+	return path, ""
+}
+
+func templateChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func verifyTemplateChecksum(content []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if got := templateChecksum(content); got != expected {
+		return fmt.Errorf("template checksum mismatch: got sha256:%s, expected sha256:%s", got, expected)
+	}
+	return nil
+}
+
+// remoteTemplateFetcher fetches remote templates, verifying their pinned checksum
+// and their signature when a public key is given. Verified templates are cached
+// so that they can be reused when offline.
+type remoteTemplateFetcher struct {
+	cache     *templatesCache
+	publicKey crypto.PublicKey
+	fetch     func(string) ([]byte, error)
+}
+
+func newRemoteTemplateFetcher(cacheDir, publicKeyPath string) (*remoteTemplateFetcher, error) {
+	f := &remoteTemplateFetcher{cache: &templatesCache{dir: cacheDir}, fetch: readHttpContent}
+	if publicKeyPath != "" {
+		key, err := loadPublicKey(publicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		f.publicKey = key
+	}
+	return f, nil
+}
+
+func (f *remoteTemplateFetcher) get(url, checksum string) ([]byte, error) {
+	if checksum != "" {
+		if content, sig, ok := f.cache.getByChecksum(checksum); ok {
+			logger.ExtraVerbosef("using cached template sha256:%s for '%s'", checksum, url)
+			return content, f.verify(url, content, sig, checksum)
+		}
+	}
+
+	content, err := f.fetch(url)
+	if err != nil {
+		cached, sig, ok := f.cache.getByURL(url)
+		if !ok {
+			return nil, err
+		}
+		logger.Warningf("cannot fetch '%s' (%s): using cached version", url, err)
+		return cached, f.verify(url, cached, sig, checksum)
+	}
+
+	var sig []byte
+	if f.publicKey != nil {
+		if sig, err = f.fetch(url + SIGNATURE_EXT); err != nil {
+			return nil, fmt.Errorf("template signature required: %s", err)
+		}
+	}
+
+	if err = f.verify(url, content, sig, checksum); err != nil {
+		return nil, err
+	}
+
+	if checksum == "" {
+		logger.Verbosef("fetched template '%s' with sha256:%s (pin it with '@sha256:%[2]s')", url, templateChecksum(content))
+	}
+
+	if err = f.cache.put(url, content, sig); err != nil {
+		logger.Warningf("cannot cache template '%s': %s", url, err)
+	}
+
+	return content, nil
+}
+
+func (f *remoteTemplateFetcher) verify(url string, content, sig []byte, checksum string) error {
+	if err := verifyTemplateChecksum(content, checksum); err != nil {
+		return fmt.Errorf("%s: %s", url, err)
+	}
+	if f.publicKey == nil {
+		return nil
+	}
+	if len(sig) == 0 {
+		return fmt.Errorf("%s: no template signature found", url)
+	}
+	if err := verifySignature(f.publicKey, content, sig); err != nil {
+		return fmt.Errorf("%s: %s", url, err)
+	}
+	logger.ExtraVerbosef("verified signature of template '%s'", url)
+	return nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("template signature public key: %s", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("template signature public key: no PEM data in '%s'", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("template signature public key: %s", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("template signature public key: unsupported key type %T", key)
+	}
+}
+
+// verifySignature verifies a SHA256 signature (raw or base64 encoded),
+// as given by `openssl dgst -sha256 -sign private.pem`
+func verifySignature(key crypto.PublicKey, content, sig []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err == nil {
+		sig = decoded
+	}
+	hashed := sha256.Sum256(content)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], sig); err != nil {
+			return errors.New("invalid template signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		var ecdsaSig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil || !ecdsa.Verify(k, hashed[:], ecdsaSig.R, ecdsaSig.S) {
+			return errors.New("invalid template signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// templatesCache stores verified templates by checksum ('<checksum>.awls' with
+// its optional '<checksum>.awls.sig') and indexes the last checksum fetched per URL
+type templatesCache struct {
+	dir string
+}
+
+func (c *templatesCache) put(url string, content, sig []byte) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	checksum := templateChecksum(content)
+	if err := ioutil.WriteFile(c.contentPath(checksum), content, 0600); err != nil {
+		return err
+	}
+	if len(sig) > 0 {
+		if err := ioutil.WriteFile(c.contentPath(checksum)+SIGNATURE_EXT, sig, 0600); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(c.urlPath(url), []byte(checksum), 0600)
+}
+
+func (c *templatesCache) getByChecksum(checksum string) ([]byte, []byte, bool) {
+	content, err := ioutil.ReadFile(c.contentPath(checksum))
+	if err != nil || templateChecksum(content) != checksum {
+		return nil, nil, false
+	}
+	sig, _ := ioutil.ReadFile(c.contentPath(checksum) + SIGNATURE_EXT)
+	return content, sig, true
+}
+
+func (c *templatesCache) getByURL(url string) ([]byte, []byte, bool) {
+	checksum, err := ioutil.ReadFile(c.urlPath(url))
+	if err != nil {
+		return nil, nil, false
+	}
+	return c.getByChecksum(strings.TrimSpace(string(checksum)))
+}
+
+func (c *templatesCache) contentPath(checksum string) string {
+	return filepath.Join(c.dir, checksum+".awls")
+}
+
+func (c *templatesCache) urlPath(url string) string {
+	return filepath.Join(c.dir, "url-"+templateChecksum([]byte(url)))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitPinnedChecksum(t *testing.T) {
+	tcases := []struct {
+		in, path, checksum string
+	}{
+		{in: "repo:create_vpc", path: "repo:create_vpc"},
+		{in: "repo:create_vpc@sha256:ABCD", path: "repo:create_vpc", checksum: "abcd"},
+		{in: "https://host/tpl.awls@sha256:1234", path: "https://host/tpl.awls", checksum: "1234"},
+	}
+	for _, tcase := range tcases {
+		path, checksum := splitPinnedChecksum(tcase.in)
+		if got, want := path, tcase.path; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+		if got, want := checksum, tcase.checksum; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRemoteTemplateFetcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "awless-templates-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tpl := []byte("create vpc cidr=10.0.0.0/16")
+	checksum := templateChecksum(tpl)
+	remote := map[string][]byte{"http://host/vpc.awls": tpl}
+	fetcher := &remoteTemplateFetcher{cache: &templatesCache{dir: dir}, fetch: func(url string) ([]byte, error) {
+		if b, ok := remote[url]; ok {
+			return b, nil
+		}
+		return nil, errors.New("not found")
+	}}
+
+	t.Run("pinned checksum", func(t *testing.T) {
+		content, err := fetcher.get("http://host/vpc.awls", checksum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(content), string(tpl); got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+		if _, err = fetcher.get("http://host/vpc.awls", strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("expected checksum mismatch, got %v", err)
+		}
+	})
+
+	t.Run("offline cache", func(t *testing.T) {
+		delete(remote, "http://host/vpc.awls")
+		defer func() { remote["http://host/vpc.awls"] = tpl }()
+
+		for _, sum := range []string{"", checksum} {
+			content, err := fetcher.get("http://host/vpc.awls", sum)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(content), string(tpl); got != want {
+				t.Fatalf("got %s, want %s", got, want)
+			}
+		}
+		if _, err := fetcher.get("http://host/unknown.awls", ""); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubBytes, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyPath := filepath.Join(dir, "key.pub")
+		if err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if fetcher.publicKey, err = loadPublicKey(keyPath); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { fetcher.publicKey = nil }()
+
+		if _, err = fetcher.get("http://host/vpc.awls", ""); err == nil {
+			t.Fatal("expected error on unsigned template")
+		}
+
+		hashed := sha256.Sum256(tpl)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := asn1.Marshal(struct{ R, S interface{} }{r, s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		remote["http://host/vpc.awls.sig"] = []byte(base64.StdEncoding.EncodeToString(sig))
+		if _, err = fetcher.get("http://host/vpc.awls", ""); err != nil {
+			t.Fatal(err)
+		}
+
+		remote["http://host/vpc.awls"] = []byte("create vpc cidr=10.0.0.0/8")
+		defer func() { remote["http://host/vpc.awls"] = tpl }()
+		if _, err = fetcher.get("http://host/vpc.awls", ""); err == nil || !strings.Contains(err.Error(), "invalid template signature") {
+			t.Fatalf("expected invalid signature, got %v", err)
+		}
+	})
+}
@@ -86,7 +86,7 @@ func init() {
 var runCmd = &cobra.Command{
 	Use:               "run PATH",
 	Short:             "Run a template given a filepath or a URL (prefixed with http)",
-	Example:           "  awless run ~/templates/my-infra.txt\n  awless run https://raw.githubusercontent.com/wallix/awless-templates/master/create_vpc.awls\n  awless run repo:create_vpc",
+	Example:           "  awless run ~/templates/my-infra.txt\n  awless run https://raw.githubusercontent.com/wallix/awless-templates/master/create_vpc.awls\n  awless run repo:create_vpc\n  awless run repo:create_vpc@sha256:<checksum>\n  awless run lib:my-vpc",
 	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
 	PersistentPostRun: applyHooks(verifyNewVersionHook),
 
@@ -351,7 +351,11 @@ func runTemplate(tplExec *template.TemplateExecution, fillers ...map[string]inte
 
 func validateTemplate(tpl *template.Template) {
 	unicityRule := &template.UniqueNameValidator{LookupGraph: func(key string) (*graph.Graph, bool) {
-		g := sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[key])
+		g, err := sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[key])
+		if err != nil {
+			logger.Warningf("cannot check the unicity of names: %s", err)
+			return nil, false
+		}
 		return g, true
 	}}
 
@@ -489,7 +493,7 @@ func runSyncFor(tpl *template.Template) {
 		func(d template.Definition) string { return d.Api },
 	)...)
 
-	if _, err := sync.DefaultSyncer.Sync(services...); err != nil {
+	if _, err := forcedSyncer().Sync(services...); err != nil {
 		logger.Error(err.Error())
 	} else {
 		logger.Verbosef("performed sync for %s", strings.Join(cloud.Services(services).Names(), ", "))
@@ -497,7 +501,11 @@ func runSyncFor(tpl *template.Template) {
 }
 
 func resolveAliasFunc(entity, key, alias string) string {
-	gph := sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[entity])
+	gph, err := sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[entity])
+	if err != nil {
+		logger.Errorf("resolving alias %s: %s", alias, err)
+		return ""
+	}
 	resType := key
 	if strings.Contains(key, "id") {
 		resType = entity
@@ -554,6 +562,17 @@ type templateMetadata struct {
 }
 
 func getTemplateText(path string) ([]byte, error) {
+	if strings.HasPrefix(path, LIBRARY_PREFIX) {
+		tpl, err := getLibraryTemplate(strings.TrimPrefix(path, LIBRARY_PREFIX))
+		if err != nil {
+			return nil, err
+		}
+		logger.ExtraVerbosef("loaded library template '%s' version %d", tpl.Name, tpl.Version)
+		return []byte(tpl.Content), nil
+	}
+
+	path, checksum := splitPinnedChecksum(path)
+
 	if strings.HasPrefix(path, "repo:") {
 		path = fmt.Sprintf("%s/%s", DEFAULT_REPO_PREFIX, strings.TrimPrefix(path[5:], "/"))
 		path = fmt.Sprintf("%s%s", strings.TrimSuffix(path, FILE_EXT), FILE_EXT)
@@ -561,9 +580,18 @@ func getTemplateText(path string) ([]byte, error) {
 
 	if strings.HasPrefix(path, "http") {
 		logger.ExtraVerbosef("fetching remote template at '%s'", path)
-		return readHttpContent(path)
+		fetcher, err := newRemoteTemplateFetcher(config.TemplatesCacheDir, config.GetTemplateSignaturePublicKey())
+		if err != nil {
+			return nil, err
+		}
+		return fetcher.get(path, checksum)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	return ioutil.ReadFile(path)
+	return content, verifyTemplateChecksum(content, checksum)
 }
 
 func removeComments(b []byte) []byte {
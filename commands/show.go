@@ -41,6 +41,9 @@ var (
 func init() {
 	RootCmd.AddCommand(showCmd)
 	showCmd.Flags().BoolVar(&listAllSiblingsFlag, "siblings", false, "List all the resource's siblings")
+	showCmd.Flags().StringSliceVar(&accountsFlag, "accounts", []string{}, "Show a local resource of the given accounts synced with `awless sync --profiles`")
+	showCmd.Flags().StringVar(&atFlag, "at", "", "Show a local resource as synced at the given date, duration ago or sync revision. Ex: --at 2017-06-20, --at 2d, --at 3f2a9c1")
+	showCmd.Flags().BoolVar(&allAccountsFlag, "all-accounts", false, "Show a local resource of all the accounts synced with `awless sync --profiles`")
 }
 
 var showCmd = &cobra.Command{
@@ -49,7 +52,9 @@ var showCmd = &cobra.Command{
 	Example: `  awless show i-8d43b21b            # show an instance via its ref
   awless show AIDAJ3Z24GOKHTZO4OIX6 # show a user via its ref
   awless show jsmith                # show a user via its ref,
-  awless show @jsmith               # forcing search by name`,
+  awless show @jsmith               # forcing search by name
+  awless show @jsmith --all-accounts # among the accounts synced with 'awless sync --profiles'
+  awless show i-8d43b21b --at 2d     # as synced 2 days ago`,
 	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook, initCloudServicesHook, initSyncerHook),
 	PersistentPostRun: applyHooks(verifyNewVersionHook),
 
@@ -66,11 +71,11 @@ var showCmd = &cobra.Command{
 
 		resource, gph = findResourceInLocalGraphs(ref)
 
-		if resource == nil && localGlobalFlag {
+		if resource == nil && (localGlobalFlag || acrossAccounts() || atRevision()) {
 			logger.Info(notFound)
 			return nil
 		} else if resource == nil {
-			runFullSync()
+			runSyncForMissing(ref)
 
 			if resource, gph = findResourceInLocalGraphs(ref); resource == nil {
 				logger.Info(notFound)
@@ -78,11 +83,11 @@ var showCmd = &cobra.Command{
 			}
 		}
 
-		if !localGlobalFlag && config.GetAutosync() {
+		if !localGlobalFlag && !acrossAccounts() && !atRevision() && config.GetAutosync() && !skipSyncForDaemon() {
 			srv, err := cloud.GetServiceForType(resource.Type())
 			exitOn(err)
 			logger.Verbosef("syncing service for %s type", resource.Type())
-			if _, err = sync.DefaultSyncer.Sync(srv); err != nil {
+			if _, err = forcedSyncer().Sync(srv); err != nil {
 				logger.Error(err)
 			}
 			resource, gph = findResourceInLocalGraphs(ref)
@@ -97,8 +102,12 @@ var showCmd = &cobra.Command{
 }
 
 func showResource(resource *graph.Resource, gph *graph.Graph) {
+	headers := console.DefaultsColumnDefinitions[resource.Type()]
+	if acrossAccounts() {
+		headers = console.ExtendColumns(headers, accountColumn)
+	}
 	displayer, err := console.BuildOptions(
-		console.WithHeaders(console.DefaultsColumnDefinitions[resource.Type()]),
+		console.WithHeaders(headers),
 		console.WithFormat(listingFormat),
 		console.WithMaxWidth(console.GetTerminalWidth()),
 	).SetSource(resource).Build()
@@ -162,20 +171,55 @@ func showResource(resource *graph.Resource, gph *graph.Graph) {
 	printResourceList(renderCyanBoldFn("Siblings"), siblings, "display all with flag --siblings")
 }
 
-func runFullSync() {
+// resourceTypePerIdPrefix gives the type of the resources whose ids have a known prefix
+var resourceTypePerIdPrefix = map[string]string{
+	"i-":        cloud.Instance,
+	"subnet-":   cloud.Subnet,
+	"vpc-":      cloud.Vpc,
+	"sg-":       cloud.SecurityGroup,
+	"vol-":      cloud.Volume,
+	"igw-":      cloud.InternetGateway,
+	"rtb-":      cloud.RouteTable,
+	"ami-":      cloud.Image,
+	"snap-":     cloud.Snapshot,
+	"eipalloc-": cloud.ElasticIP,
+	"AIDA":      cloud.User,
+	"AGPA":      cloud.Group,
+	"AROA":      cloud.Role,
+	"ANPA":      cloud.Policy,
+	"AKIA":      cloud.AccessKey,
+}
+
+// runSyncForMissing syncs the service of a resource not found in the local graphs, when the
+// prefix of its id gives its type, even if a sync daemon is running as it may not have synced it yet.
+// Otherwise, all the services are synced, unless a daemon keeps them fresh.
+func runSyncForMissing(ref string) {
 	if !config.GetAutosync() {
 		logger.Info("autosync disabled")
 		return
 	}
 
-	logger.Info("cannot resolve resource - running full sync")
-
 	var services []cloud.Service
-	for _, srv := range cloud.ServiceRegistry {
-		services = append(services, srv)
+	for prefix, resourceType := range resourceTypePerIdPrefix {
+		if strings.HasPrefix(ref, prefix) {
+			if srv, err := cloud.GetServiceForType(resourceType); err == nil {
+				services = append(services, srv)
+			}
+		}
+	}
+	if len(services) > 0 {
+		logger.Infof("cannot resolve resource - running sync of %s", strings.Join(cloud.Services(services).Names(), ", "))
+	} else {
+		if skipSyncForDaemon() {
+			return
+		}
+		logger.Info("cannot resolve resource - running full sync")
+		for _, srv := range cloud.ServiceRegistry {
+			services = append(services, srv)
+		}
 	}
 
-	if _, err := sync.DefaultSyncer.Sync(services...); err != nil {
+	if _, err := forcedSyncer().Sync(services...); err != nil {
 		logger.Verbose(err)
 	}
 }
@@ -187,7 +231,19 @@ func findResourceInLocalGraphs(ref string) (*graph.Resource, *graph.Graph) {
 		return nil, nil
 	case 1:
 		res := resources[0]
-		return res, sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[res.Type()])
+		if atRevision() {
+			g, err := loadRevisionGraph(aws.ServicePerResourceType[res.Type()])
+			exitOn(err)
+			return res, g
+		}
+		if acrossAccounts() {
+			g, err := loadAccountsGraph()
+			exitOn(err)
+			return res, g
+		}
+		g, err := sync.LoadCurrentLocalGraph(aws.ServicePerResourceType[res.Type()])
+		exitOn(err)
+		return res, g
 	default:
 		all := graph.Resources(resources).Map(func(r *graph.Resource) string { return r.String() })
 		logger.Infof("%d resources found with name '%s': %s", len(resources), deprefix(ref), strings.Join(all, ", "))
@@ -203,9 +259,13 @@ func findResourceInLocalGraphs(ref string) (*graph.Resource, *graph.Graph) {
 }
 
 func resolveResourceFromRef(ref string) []*graph.Resource {
-	g, err := sync.LoadAllGraphs()
+	g, err := loadLocalGraphs()
 	exitOn(err)
+	return resolveResourceFromRefIn(g, ref)
+}
 
+// resolveResourceFromRefIn returns the resources of the graph with the given id, or else name or arn
+func resolveResourceFromRefIn(g *graph.Graph, ref string) []*graph.Resource {
 	name := deprefix(ref)
 	byName := &graph.ByProperty{Key: "Name", Value: name}
 
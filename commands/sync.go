@@ -17,20 +17,36 @@ limitations under the License.
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wallix/awless/aws"
 	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/config"
 	"github.com/wallix/awless/graph"
 	"github.com/wallix/awless/logger"
 	"github.com/wallix/awless/sync"
+	"github.com/wallix/awless/sync/remote"
 )
 
 var (
 	servicesToSyncFlags map[string]*bool
+	syncRegionsFlag     []string
+	syncAllRegionsFlag  bool
+	syncProfilesFlag    []string
+	syncFullFlag        bool
+	syncDaemonFlag      bool
+	syncIntervalFlag    time.Duration
+	syncPushFlag        bool
+	syncPullFlag        bool
+	syncForceFlag       bool
 )
 
 func init() {
@@ -41,6 +57,15 @@ func init() {
 		servicesToSyncFlags[service] = new(bool)
 		syncCmd.Flags().BoolVar(servicesToSyncFlags[service], service, false, fmt.Sprintf("Sync '%s' service only", service))
 	}
+	syncCmd.Flags().StringSliceVar(&syncRegionsFlag, "regions", []string{}, "Sync concurrently the given regions into local graphs partitioned by region (see `awless list --regions`). Ex: --regions eu-west-1,us-east-1")
+	syncCmd.Flags().BoolVar(&syncAllRegionsFlag, "all-regions", false, "Sync concurrently all regions into local graphs partitioned by region")
+	syncCmd.Flags().BoolVar(&syncFullFlag, "full", false, "Fetch again all resources, even the ones synced for less than their TTL (see `awless config set aws.sync.ttl`)")
+	syncCmd.Flags().BoolVar(&syncDaemonFlag, "daemon", false, "Keep syncing the local graphs at the given interval until interrupted, commands reading them then skipping their autosync")
+	syncCmd.Flags().DurationVar(&syncIntervalFlag, "interval", 5*time.Minute, "Interval between the syncs of the daemon (see --daemon). Ex: --interval 10m")
+	syncCmd.Flags().BoolVar(&syncPushFlag, "push", false, "Upload the synced graphs and revisions to the remote inventory shared by your team (see `awless config set aws.sync.remote`)")
+	syncCmd.Flags().BoolVar(&syncPullFlag, "pull", false, "Download the graphs and revisions of the remote inventory shared by your team instead of fetching them from AWS (refused if pushed from another profile or region, or if discarding local revisions: see --force)")
+	syncCmd.Flags().BoolVar(&syncForceFlag, "force", false, "With --pull, discard the local revisions missing from the history of the remote inventory instead of refusing the pull")
+	syncCmd.Flags().StringSliceVar(&syncProfilesFlag, "profiles", []string{}, "Sync concurrently the accounts of the given AWS profiles (in the configured region) into account-scoped local graphs (see `awless list --all-accounts`). Ex: --profiles dev,prod")
 }
 
 var syncCmd = &cobra.Command{
@@ -56,6 +81,43 @@ var syncCmd = &cobra.Command{
 			logger.DefaultLogger.SetVerbose(logger.VerboseF) //Forcing verbose to display sync info
 		}
 
+		if syncDaemonFlag && (len(syncProfilesFlag) > 0 || len(syncRegionsFlag) > 0 || syncAllRegionsFlag) {
+			return errors.New("--daemon cannot be used along with --profiles, --regions or --all-regions")
+		}
+		if len(syncProfilesFlag) > 0 && (len(syncRegionsFlag) > 0 || syncAllRegionsFlag) {
+			return errors.New("--profiles cannot be used along with --regions or --all-regions")
+		}
+		if syncPullFlag && (syncPushFlag || syncDaemonFlag || syncFullFlag || len(syncProfilesFlag) > 0 || len(syncRegionsFlag) > 0 || syncAllRegionsFlag) {
+			return errors.New("--pull cannot be used along with --push, --daemon, --full, --profiles, --regions or --all-regions: all the remote graphs are pulled")
+		}
+		if syncForceFlag && !syncPullFlag {
+			return errors.New("--force can only be used along with --pull")
+		}
+
+		var backend remote.Backend
+		if syncPushFlag || syncPullFlag {
+			var err error
+			if backend, err = syncRemoteBackend(); err != nil {
+				return err
+			}
+		}
+		if syncPullFlag {
+			return pullFromRemote(backend)
+		}
+
+		if len(syncRegionsFlag) > 0 || syncAllRegionsFlag {
+			if err := syncRegions(); err != nil {
+				return err
+			}
+			return pushToRemote(backend)
+		}
+		if len(syncProfilesFlag) > 0 {
+			if err := syncAccounts(); err != nil {
+				return err
+			}
+			return pushToRemote(backend)
+		}
+
 		var services []cloud.Service
 		displayAllServices := true
 		for _, srv := range cloud.ServiceRegistry {
@@ -70,26 +132,224 @@ var syncCmd = &cobra.Command{
 		}
 		localGraphs := make(map[string]*graph.Graph)
 		for _, service := range services {
-			localGraphs[service.Name()] = sync.LoadCurrentLocalGraph(service.Name())
+			g, err := sync.LoadCurrentLocalGraph(service.Name())
+			if err != nil {
+				return err
+			}
+			localGraphs[service.Name()] = g
 		}
-		logger.Info("running sync: fetching remote resources for local store")
-		start := time.Now()
 
-		graphs, err := sync.DefaultSyncer.Sync(services...)
-		if err != nil {
-			logger.Verbose(err)
+		if syncFullFlag {
+			sync.DefaultSyncer = forcedSyncer()
 		}
 
-		for k, g := range graphs {
-			displaySyncStats(k, g)
+		if syncDaemonFlag {
+			return runSyncDaemon(services, backend)
 		}
-		logger.Infof("sync took %s", time.Since(start))
 
-		return nil
+		syncServices(services)
+
+		return pushToRemote(backend)
 	},
 }
 
-func displaySyncStats(serviceName string, g *graph.Graph) {
+func syncServices(services []cloud.Service) {
+	logger.Info("running sync: fetching remote resources for local store")
+	start := time.Now()
+
+	graphs, err := sync.DefaultSyncer.Sync(services...)
+	if err != nil {
+		logger.Verbose(err)
+	}
+
+	for k, g := range graphs {
+		displaySyncStats(k, k, g)
+	}
+	logger.Infof("sync took %s", time.Since(start))
+}
+
+// runSyncDaemon syncs the services at each interval until interrupted.
+// Meanwhile, commands reading the local graphs of the same profile and region skip their autosync
+// (see skipSyncForDaemon), the services of run templates and of resources not found by show
+// still being synced right away.
+func runSyncDaemon(services []cloud.Service, backend remote.Backend) error {
+	if syncIntervalFlag <= 0 {
+		return fmt.Errorf("invalid interval %s: expecting a positive duration", syncIntervalFlag)
+	}
+
+	release, err := sync.LockDaemon(syncScope())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	ticker := time.NewTicker(syncIntervalFlag)
+	defer ticker.Stop()
+
+	logger.Infof("running sync daemon: syncing every %s", syncIntervalFlag)
+	for {
+		syncServices(services)
+		if err := pushToRemote(backend); err != nil {
+			logger.Errorf("sync daemon: %s", err)
+		}
+		select {
+		case <-ticker.C:
+		case sig := <-stop:
+			logger.Infof("sync daemon stopped (%s)", sig)
+			return nil
+		}
+	}
+}
+
+// skipSyncForDaemon returns whether a sync daemon keeps the local graphs of the current
+// profile and region fresh, so that interactive commands do not sync in the foreground
+func skipSyncForDaemon() bool {
+	if sync.DaemonRunning(syncScope()) {
+		logger.Verbose("sync daemon running: skipping foreground sync")
+		return true
+	}
+	return false
+}
+
+// syncRemoteBackend returns the backend of the remote inventory shared by the team (see `awless config set aws.sync.remote`)
+func syncRemoteBackend() (remote.Backend, error) {
+	location := config.GetSyncRemote()
+	if location == "" {
+		return nil, errors.New("no remote inventory: set it with `awless config set aws.sync.remote`")
+	}
+	if !remote.IsS3URL(location) {
+		return remote.NewDirBackend(location), nil
+	}
+	bucket, prefix, err := remote.ParseS3URL(location)
+	if err != nil {
+		return nil, err
+	}
+	api, err := aws.NewS3API(config.GetAWSRegion(), config.GetAWSProfile(), config.GetSyncRemoteEndpoint())
+	if err != nil {
+		return nil, err
+	}
+	return remote.NewS3Backend(api, bucket, prefix), nil
+}
+
+// pushToRemote uploads the synced graphs and revisions to the remote inventory, if any (see --push)
+func pushToRemote(backend remote.Backend) error {
+	if backend == nil {
+		return nil
+	}
+	start := time.Now()
+	count, err := sync.DefaultSyncer.Push(backend)
+	if err != nil {
+		return fmt.Errorf("pushing to remote inventory %s: %s", config.GetSyncRemote(), err)
+	}
+	logger.Infof("pushed %d files to remote inventory %s in %s", count, config.GetSyncRemote(), time.Since(start))
+	return nil
+}
+
+func pullFromRemote(backend remote.Backend) error {
+	start := time.Now()
+	var services []cloud.Service
+	for _, srv := range cloud.ServiceRegistry {
+		services = append(services, srv)
+	}
+	count, err := sync.DefaultSyncer.Pull(backend, syncForceFlag, services...)
+	if err != nil {
+		return fmt.Errorf("pulling from remote inventory %s: %s", config.GetSyncRemote(), err)
+	}
+	for _, srv := range services {
+		g, err := sync.LoadCurrentLocalGraph(srv.Name())
+		if err != nil {
+			return err
+		}
+		displaySyncStats(srv.Name(), srv.Name(), g)
+	}
+	logger.Infof("pulled %d files from remote inventory %s in %s", count, config.GetSyncRemote(), time.Since(start))
+	return nil
+}
+
+func syncRegions() error {
+	regions, err := selectedRegions(syncRegionsFlag, syncAllRegionsFlag, false)
+	if err != nil {
+		return err
+	}
+	servicesPerRegion, err := newServicesPerRegion(regions)
+	if err != nil {
+		return err
+	}
+
+	for region, services := range servicesPerRegion {
+		servicesPerRegion[region] = selectServicesToSync(services)
+	}
+
+	logger.Infof("running sync: fetching remote resources of %s for local store", strings.Join(regions, ", "))
+	start := time.Now()
+
+	graphsPerRegion, err := sync.DefaultSyncer.SyncRegions(servicesPerRegion)
+	if err != nil {
+		logger.Verbose(err)
+	}
+
+	for _, region := range regions {
+		for k, g := range graphsPerRegion[region] {
+			displaySyncStats(fmt.Sprintf("%s %s", region, k), k, g)
+		}
+	}
+	logger.Infof("sync took %s", time.Since(start))
+
+	return nil
+}
+
+func syncAccounts() error {
+	servicesPerAccount, err := newServicesPerAccount(syncProfilesFlag)
+	if err != nil {
+		return err
+	}
+
+	var accounts []string
+	for account, services := range servicesPerAccount {
+		servicesPerAccount[account] = selectServicesToSync(services)
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	logger.Infof("running sync: fetching remote resources of accounts %s for local store", strings.Join(accounts, ", "))
+	start := time.Now()
+
+	graphsPerAccount, err := sync.DefaultSyncer.SyncAccounts(servicesPerAccount)
+	if err != nil {
+		logger.Verbose(err)
+	}
+
+	for _, account := range accounts {
+		for k, g := range graphsPerAccount[account] {
+			displaySyncStats(fmt.Sprintf("%s %s", account, k), k, g)
+		}
+	}
+	logger.Infof("sync took %s", time.Since(start))
+
+	return nil
+}
+
+// selectServicesToSync returns the services selected with the service flags (ex: --infra), all of them by default
+func selectServicesToSync(services []cloud.Service) []cloud.Service {
+	syncAllServices := true
+	for _, srvName := range aws.ServiceNames {
+		if *servicesToSyncFlags[srvName] {
+			syncAllServices = false
+		}
+	}
+	var selected []cloud.Service
+	for _, srv := range services {
+		if syncAllServices || *servicesToSyncFlags[srv.Name()] {
+			selected = append(selected, srv)
+		}
+	}
+	return selected
+}
+
+func displaySyncStats(title, serviceName string, g *graph.Graph) {
 	var strs []string
 	for rt, service := range aws.ServicePerResourceType {
 		if service == serviceName {
@@ -105,5 +365,5 @@ func displaySyncStats(serviceName string, g *graph.Graph) {
 			}
 		}
 	}
-	logger.Infof("-> %s: %s", serviceName, strings.Join(strs, ", "))
+	logger.Infof("-> %s: %s", title, strings.Join(strs, ", "))
 }
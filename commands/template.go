@@ -0,0 +1,286 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/wallix/awless/aws/driver"
+	"github.com/wallix/awless/database"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/template"
+)
+
+const LIBRARY_PREFIX = "lib:"
+
+var templateDescriptionFlag string
+
+func init() {
+	RootCmd.AddCommand(templateCmd)
+
+	templateCmd.AddCommand(templateTestCmd)
+	templateCmd.AddCommand(templateSaveCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateDeleteCmd)
+
+	templateSaveCmd.Flags().StringVar(&templateDescriptionFlag, "description", "", "Description of the saved template (default: description of the previous version)")
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage and test your templates",
+}
+
+var templateSaveCmd = &cobra.Command{
+	Use:               "save NAME PATH",
+	Short:             "Save a template given a filepath or a URL as a new version of NAME in the local library",
+	Example:           "  awless template save my-vpc ~/templates/vpc.awls --description 'VPC with a public subnet'\n  awless template save my-vpc repo:create_vpc\n  awless run lib:my-vpc",
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("missing NAME and PATH args")
+		}
+		name := args[0]
+		if strings.ContainsAny(name, "@ \t") {
+			return fmt.Errorf("invalid library template name '%s'", name)
+		}
+
+		content, err := getTemplateText(args[1])
+		exitOn(err)
+		_, err = template.Parse(string(content))
+		exitOn(err)
+
+		var saved *database.LibraryTemplate
+		exitOn(database.Execute(func(db *database.DB) (dberr error) {
+			saved, dberr = db.SaveLibraryTemplate(name, templateDescriptionFlag, string(content))
+			return
+		}))
+
+		logger.Infof("saved template '%s' version %d. Run it with `awless run %s%s`", saved.Name, saved.Version, LIBRARY_PREFIX, saved.Name)
+		return nil
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:               "list",
+	Short:             "List the templates of the local library",
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var all []*database.LibraryTemplate
+		exitOn(database.Execute(func(db *database.DB) (dberr error) {
+			all, dberr = db.ListLibraryTemplates()
+			return
+		}))
+
+		if len(all) == 0 {
+			logger.Infof("no templates saved yet. Use `awless template save NAME PATH`")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Name\tVersion\tDescription\tSaved\tRun it with")
+		fmt.Fprintln(w, "----\t-------\t-----------\t-----\t-----------")
+		for _, tpl := range all {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", tpl.Name, tpl.Version, tpl.Description, tpl.Date.Local().Format("Mon, Jan 2, 2006 15:04"), renderGreenFn(fmt.Sprintf("awless run %s%s", LIBRARY_PREFIX, tpl.Name)))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:               "show NAME[@VERSION]",
+	Short:             "Show a template of the local library (latest version by default)",
+	Example:           "  awless template show my-vpc\n  awless template show my-vpc@2",
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing NAME arg")
+		}
+
+		tpl, err := getLibraryTemplate(args[0])
+		exitOn(err)
+
+		fmt.Printf("# %s (version %d, saved %s)\n", tpl.Name, tpl.Version, tpl.Date.Local().Format("Mon, Jan 2, 2006 15:04"))
+		if tpl.Description != "" {
+			fmt.Printf("# %s\n", tpl.Description)
+		}
+		fmt.Println(strings.TrimSpace(tpl.Content))
+		return nil
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:               "delete NAME",
+	Short:             "Delete all the versions of a template of the local library",
+	PersistentPreRun:  applyHooks(initLoggerHook, initAwlessEnvHook),
+	PersistentPostRun: applyHooks(verifyNewVersionHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing NAME arg")
+		}
+
+		exitOn(database.Execute(func(db *database.DB) error {
+			return db.DeleteLibraryTemplate(args[0])
+		}))
+
+		logger.Infof("deleted template '%s'", args[0])
+		return nil
+	},
+}
+
+// getLibraryTemplate returns the library template given 'name' or 'name@version'
+func getLibraryTemplate(ref string) (tpl *database.LibraryTemplate, err error) {
+	name, version := ref, 0
+	if i := strings.LastIndex(ref, "@"); i > -1 {
+		name = ref[:i]
+		if version, err = strconv.Atoi(ref[i+1:]); err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid version in library template reference '%s'", ref)
+		}
+	}
+
+	err = database.Execute(func(db *database.DB) (dberr error) {
+		tpl, dberr = db.GetLibraryTemplate(name, version)
+		return
+	})
+	return
+}
+
+var templateTestCmd = &cobra.Command{
+	Use:              "test [PATH ...]",
+	Short:            fmt.Sprintf("Run the template tests given '*%s' files or directories containing them (default: current directory)", template.TestFileSuffix),
+	Long:             fmt.Sprintf("Run the template tests declared in '*%[1]s' files. A 'NAME%[1]s' file tests the template 'NAME.awls' in the same directory.", template.TestFileSuffix),
+	Example:          "  awless template test\n  awless template test ~/templates\n  awless template test create_vpc_test.awls",
+	PersistentPreRun: applyHooks(initLoggerHook),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		var testFiles []string
+		for _, arg := range args {
+			files, err := findTemplateTestFiles(arg)
+			exitOn(err)
+			testFiles = append(testFiles, files...)
+		}
+		if len(testFiles) == 0 {
+			return fmt.Errorf("no '*%s' files found", template.TestFileSuffix)
+		}
+
+		var failed int
+		for _, testFile := range testFiles {
+			failed += runTemplateTestFile(testFile)
+		}
+
+		if failed > 0 {
+			exitOn(fmt.Errorf("%d template test(s) failed", failed))
+		}
+		return nil
+	},
+}
+
+func findTemplateTestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if !strings.HasSuffix(path, template.TestFileSuffix) {
+			return nil, fmt.Errorf("'%s' is not a template test file (expecting '*%s')", path, template.TestFileSuffix)
+		}
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(p, template.TestFileSuffix) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func runTemplateTestFile(testFile string) (failed int) {
+	fail := func(name string, err error) {
+		failed++
+		fmt.Printf("%s\t%s: %s\n", renderRedFn("FAIL"), testFile, name)
+		if errs, ok := err.(*template.Errors); ok {
+			all, _ := errs.Errors()
+			for _, e := range all {
+				fmt.Printf("\t%s\n", strings.Replace(e.Error(), "\n", "\n\t", -1))
+			}
+		} else {
+			fmt.Printf("\t%s\n", err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		fail("read", err)
+		return
+	}
+	tcases, err := template.ParseTestCases(string(content))
+	if err != nil {
+		fail("parse", err)
+		return
+	}
+	if len(tcases) == 0 {
+		fail("parse", errors.New("no test case declared"))
+		return
+	}
+
+	tplPath := template.TestedTemplatePath(testFile)
+	tplContent, err := ioutil.ReadFile(tplPath)
+	if err != nil {
+		fail("read", fmt.Errorf("tested template: %s", err))
+		return
+	}
+	tpl, err := template.Parse(string(tplContent))
+	if err != nil {
+		fail("parse", fmt.Errorf("tested template '%s': %s", tplPath, err))
+		return
+	}
+
+	for _, tcase := range tcases {
+		if err := tcase.Run(tpl, awsdriver.AWSLookupDefinitions); err != nil {
+			fail(tcase.Name, err)
+			continue
+		}
+		fmt.Printf("%s\t%s: %s\n", renderGreenFn("ok"), testFile, tcase.Name)
+	}
+	return
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/wallix/awless/aws"
 	"github.com/wallix/awless/aws/config"
@@ -29,8 +30,16 @@ const (
 	autosyncConfigKey              = "autosync"
 	checkUpgradeFrequencyConfigKey = "upgrade.checkfrequency"
 	schedulerURL                   = "scheduler.url"
+	templateSignatureKeyConfigKey  = "template.signature.publickey"
 	RegionConfigKey                = "aws.region"
 	ProfileConfigKey               = "aws.profile"
+	syncTTLConfigKey               = "aws.sync.ttl"
+	syncTTLConfigSuffix            = ".sync.ttl"
+	syncHistoryMaxAgeConfigKey     = "aws.sync.history.maxage"
+	syncHistoryMaxRevsConfigKey    = "aws.sync.history.maxrevisions"
+	syncHistoryDailyAfterConfigKey = "aws.sync.history.dailyafter"
+	syncRemoteConfigKey            = "aws.sync.remote"
+	syncRemoteEndpointConfigKey    = "aws.sync.remote.endpoint"
 
 	//Config prefix
 	awsCloudPrefix = "aws."
@@ -54,8 +63,15 @@ var configDefinitions = map[string]*Definition{
 	"aws.dns.sync":                 {help: "Sync AWS Route53 service (when empty: true)", defaultValue: "true", parseParamFn: parseBool},
 	"aws.cdn.sync":                 {help: "Sync AWS CloudFront service (when empty: true)", defaultValue: "true", parseParamFn: parseBool},
 	"aws.cloudformation.sync":      {help: "Sync AWS CloudFormation service (when empty: true)", defaultValue: "true", parseParamFn: parseBool},
+	syncTTLConfigKey:               {help: "Duration (ex: 30m, 2h) during which synced resources are not fetched again by sync (0: always fetched). Override per service or type with aws.SERVICE.sync.ttl or aws.SERVICE.TYPE.sync.ttl", defaultValue: "0", parseParamFn: parseDuration},
+	syncHistoryMaxAgeConfigKey:     {help: "Age (ex: 720h) of the sync revisions pruned from the local history (0: never pruned)", defaultValue: "0", parseParamFn: parseDuration},
+	syncHistoryMaxRevsConfigKey:    {help: "Number of most recent sync revisions kept in the local history (0: no limit)", defaultValue: "0", parseParamFn: parseInt},
+	syncHistoryDailyAfterConfigKey: {help: "Age (ex: 168h) of the sync revisions reduced to the last one of each day in the local history (0: never reduced)", defaultValue: "0", parseParamFn: parseDuration},
+	syncRemoteConfigKey:            {help: "Remote inventory shared by your team with `awless sync --push/--pull`: a directory (ex: /mnt/nfs/awless) or a S3 URL (ex: s3://bucket/inventory)"},
+	syncRemoteEndpointConfigKey:    {help: "Endpoint of the S3 compatible storage of a S3 remote inventory (ex: http://localhost:9000). Empty for AWS S3"},
 	checkUpgradeFrequencyConfigKey: {help: "Upgrade check frequency (hours); a negative value disables check", defaultValue: "8", parseParamFn: parseInt},
 	schedulerURL:                   {help: "URL used by awless CLI to interact with pre-installed awless-scheduler", defaultValue: "http://localhost:8082"},
+	templateSignatureKeyConfigKey:  {help: "Path to a PEM public key (RSA or ECDSA) verifying remote templates signatures (URL.sig files). When set, unsigned remote templates are refused"},
 }
 
 var defaultsDefinitions = map[string]*Definition{
@@ -216,6 +232,13 @@ func parseInt(a string) (interface{}, error) {
 	return i, nil
 }
 
+func parseDuration(a string) (interface{}, error) {
+	if _, err := time.ParseDuration(a); err != nil {
+		return a, fmt.Errorf("invalid value, expected a duration (ex: 30m, 2h), got '%s'", a)
+	}
+	return a, nil
+}
+
 func defaultParser(value string) (interface{}, error) {
 	if num, err := strconv.Atoi(value); err == nil {
 		return num, nil
@@ -259,6 +282,10 @@ func setVolatile(key, value string) (interface{}, *Definition, bool, error) {
 		if v, err = def.parseParamFn(value); err != nil {
 			return nil, def, isConf, err
 		}
+	} else if strings.HasSuffix(key, syncTTLConfigSuffix) {
+		if v, err = parseDuration(value); err != nil {
+			return nil, def, isConf, err
+		}
 	} else {
 		if v, err = defaultParser(value); err != nil {
 			return nil, def, isConf, err
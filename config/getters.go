@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/wallix/awless/aws"
+	"github.com/wallix/awless/sync/repo"
 )
 
 func GetAWSRegion() string {
@@ -36,6 +39,60 @@ func GetAutosync() bool {
 	return true
 }
 
+// GetSyncTTL returns the duration during which the synced resources of a type are not fetched again,
+// from aws.SERVICE.TYPE.sync.ttl, aws.SERVICE.sync.ttl or else aws.sync.ttl. It is negative for
+// types whose sync is disabled (aws.SERVICE.TYPE.sync), only fetched along with their whole service.
+func GetSyncTTL(resourceType string) time.Duration {
+	srvName := aws.ServicePerResourceType[resourceType]
+	if enabled, ok := Config[fmt.Sprintf("aws.%s.%s.sync", srvName, resourceType)].(bool); ok && !enabled {
+		return -1
+	}
+	for _, key := range []string{
+		fmt.Sprintf("aws.%s.%s%s", srvName, resourceType, syncTTLConfigSuffix),
+		fmt.Sprintf("aws.%s%s", srvName, syncTTLConfigSuffix),
+		syncTTLConfigKey,
+	} {
+		if v, ok := Config[key]; ok {
+			if ttl, err := time.ParseDuration(fmt.Sprint(v)); err == nil {
+				return ttl
+			}
+		}
+	}
+	return 0
+}
+
+// GetSyncRetention returns the retention policy of the local sync revisions
+// from aws.sync.history.maxage, aws.sync.history.maxrevisions and aws.sync.history.dailyafter
+func GetSyncRetention() repo.RetentionPolicy {
+	var policy repo.RetentionPolicy
+	if d, err := time.ParseDuration(fmt.Sprint(Config[syncHistoryMaxAgeConfigKey])); err == nil {
+		policy.MaxAge = d
+	}
+	if n, ok := Config[syncHistoryMaxRevsConfigKey].(int); ok {
+		policy.MaxRevisions = n
+	}
+	if d, err := time.ParseDuration(fmt.Sprint(Config[syncHistoryDailyAfterConfigKey])); err == nil {
+		policy.DailyAfter = d
+	}
+	return policy
+}
+
+// GetSyncRemote returns the location of the remote inventory shared by a team, if any
+func GetSyncRemote() string {
+	if remote, ok := Config[syncRemoteConfigKey].(string); ok {
+		return remote
+	}
+	return ""
+}
+
+// GetSyncRemoteEndpoint returns the endpoint of the S3 compatible storage of the remote inventory, if any
+func GetSyncRemoteEndpoint() string {
+	if endpoint, ok := Config[syncRemoteEndpointConfigKey].(string); ok {
+		return endpoint
+	}
+	return ""
+}
+
 func GetSchedulerURL() string {
 	if u, ok := Config[schedulerURL].(string); ok {
 		return u
@@ -43,6 +100,13 @@ func GetSchedulerURL() string {
 	return ""
 }
 
+func GetTemplateSignaturePublicKey() string {
+	if k, ok := Config[templateSignatureKeyConfigKey].(string); ok {
+		return k
+	}
+	return ""
+}
+
 func GetConfigWithPrefix(prefix string) map[string]interface{} {
 	conf := make(map[string]interface{})
 	for k, v := range Config {
@@ -33,6 +33,7 @@ var (
 	DBPath             = filepath.Join(AwlessHome, database.Filename)
 	Dir                = filepath.Join(AwlessHome, "aws")
 	KeysDir            = filepath.Join(AwlessHome, "keys")
+	TemplatesCacheDir  = filepath.Join(AwlessHome, "templates")
 	AwlessFirstInstall bool
 )
 
@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -65,24 +66,116 @@ func (b *Builder) SetSource(i interface{}) *Builder {
 	return b
 }
 
+// filterOperators are the operators of filter expressions, longest first to resolve ambiguities
+var filterOperators = []string{"=~", "!=", ">=", "<=", " in ", "=", ">", "<"}
+
+// buildGraphFilters builds a filter per filter expression: 'KEY OPERATOR VALUE' or 'exists KEY',
+// possibly negated with a 'not ' (or '!') prefix and combined with ' or '
 func (b *Builder) buildGraphFilters() (funcs []graph.FilterFn, err error) {
 	for _, f := range b.filters {
-		splits := strings.SplitN(f, "=", 2)
-		if len(splits) == 2 {
-			name, val := strings.TrimSpace(strings.Title(splits[0])), strings.TrimSpace(splits[1])
-			key := ColumnDefinitions(b.headers).resolveKey(name)
+		var orFuncs []graph.FilterFn
+		for _, term := range splitFilterOr(f) {
+			fn, ferr := b.buildGraphFilterTerm(term)
+			if ferr != nil {
+				return funcs, ferr
+			}
+			orFuncs = append(orFuncs, fn)
+		}
+		if len(orFuncs) == 1 {
+			funcs = append(funcs, orFuncs[0])
+		} else {
+			funcs = append(funcs, graph.BuildOrFilterFunc(orFuncs...))
+		}
+	}
+	return
+}
 
-			if key != "" {
-				funcs = append(funcs, graph.BuildPropertyFilterFunc(key, val))
-			} else {
-				var allowed []string
-				for _, h := range b.headers {
-					allowed = append(allowed, h.propKey())
-				}
-				err = fmt.Errorf("Invalid filter key '%s'. Expecting any of: %s. (Note: filter keys/values are case insensitive)", name, strings.Join(allowed, ", "))
+func (b *Builder) buildGraphFilterTerm(term string) (graph.FilterFn, error) {
+	term = strings.TrimSpace(term)
+	low := strings.ToLower(term)
+	switch {
+	case strings.HasPrefix(low, "not "):
+		fn, err := b.buildGraphFilterTerm(term[4:])
+		if err != nil {
+			return nil, err
+		}
+		return graph.BuildNotFilterFunc(fn), nil
+	case strings.HasPrefix(term, "!"):
+		fn, err := b.buildGraphFilterTerm(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		return graph.BuildNotFilterFunc(fn), nil
+	case strings.HasPrefix(low, "exists "):
+		key, err := b.resolveFilterKey(strings.TrimSpace(term[7:]))
+		if err != nil {
+			return nil, err
+		}
+		return graph.BuildPropertyExistsFilterFunc(key), nil
+	}
+
+	name, op, val, ok := splitFilterTerm(term)
+	if !ok {
+		return nil, fmt.Errorf("Invalid filter '%s'. Expecting 'key=value' (or any operator among %s) or 'exists key'", term, strings.Join(trimmedFilterOperators(), ", "))
+	}
+	key, err := b.resolveFilterKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if op != "=" && op != "!=" && op != "=~" {
+		val = b.normalizeFilterValue(key, val)
+	}
+	return graph.BuildPropertyCompareFilterFunc(key, op, val)
+}
+
+func (b *Builder) resolveFilterKey(name string) (string, error) {
+	name = strings.TrimSpace(strings.Title(name))
+	if key := ColumnDefinitions(b.headers).resolveKey(name); key != "" {
+		return key, nil
+	}
+	var allowed []string
+	for _, h := range b.headers {
+		allowed = append(allowed, h.propKey())
+	}
+	return "", fmt.Errorf("Invalid filter key '%s'. Expecting any of: %s. (Note: filter keys/values are case insensitive)", name, strings.Join(allowed, ", "))
+}
+
+// normalizeFilterValue converts human storage sizes (ex: 10G) to the unit of the property
+func (b *Builder) normalizeFilterValue(key, val string) string {
+	for _, h := range b.headers {
+		if storage, ok := h.(StorageColumnDefinition); ok && storage.propKey() == key {
+			if nb, ok := parseStorage(val, storage.Unit); ok {
+				return strconv.FormatUint(nb, 10)
 			}
 		}
+	}
+	return val
+}
 
+func splitFilterOr(f string) (terms []string) {
+	low := strings.ToLower(f)
+	for i := strings.Index(low, " or "); i > -1; i = strings.Index(low, " or ") {
+		terms = append(terms, f[:i])
+		f, low = f[i+4:], low[i+4:]
+	}
+	return append(terms, f)
+}
+
+func splitFilterTerm(term string) (key, op, val string, ok bool) {
+	low := strings.ToLower(term)
+	for i := 0; i < len(term); i++ {
+		for _, operator := range filterOperators {
+			if strings.HasPrefix(low[i:], operator) {
+				return strings.TrimSpace(term[:i]), strings.TrimSpace(operator), strings.TrimSpace(term[i+len(operator):]), i > 0
+			}
+		}
+	}
+	return
+}
+
+func trimmedFilterOperators() (ops []string) {
+	for _, op := range filterOperators {
+		ops = append(ops, strings.TrimSpace(op))
 	}
 	return
 }
@@ -694,16 +787,11 @@ type diffTableDisplayer struct {
 func (d *diffTableDisplayer) Print(w io.Writer) error {
 	var values table
 
-	fromCommons := make(map[string]*graph.Resource)
-	toCommons := make(map[string]*graph.Resource)
 	each := func(res *graph.Resource, distance int) error {
-		switch res.Meta["diff"] {
-		case "extra":
+		if res.Meta["diff"] == "extra" {
 			values = append(values, []interface{}{
 				res.Type(), color.New(color.FgRed).SprintFunc()("- " + nameOrID(res)), "", "",
 			})
-		default:
-			fromCommons[res.Id()] = res
 		}
 		return nil
 	}
@@ -713,13 +801,10 @@ func (d *diffTableDisplayer) Print(w io.Writer) error {
 	}
 
 	each = func(res *graph.Resource, distance int) error {
-		switch res.Meta["diff"] {
-		case "extra":
+		if res.Meta["diff"] == "extra" {
 			values = append(values, []interface{}{
 				res.Type(), color.New(color.FgGreen).SprintFunc()("+ " + nameOrID(res)), "", "",
 			})
-		default:
-			toCommons[res.Id()] = res
 		}
 		return nil
 	}
@@ -728,22 +813,18 @@ func (d *diffTableDisplayer) Print(w io.Writer) error {
 		return err
 	}
 
-	for _, common := range fromCommons {
-		resType := common.Type()
-		naming := nameOrID(common)
-
-		if rem, ok := toCommons[common.Id()]; ok {
-			added := graph.Subtract(rem.Properties, common.Properties)
-			for k, v := range added {
+	for _, changes := range d.diff.PropertyChanges() {
+		resType := changes.Resource.Type()
+		naming := nameOrID(changes.Resource)
+		for _, change := range changes.Properties {
+			if change.From != nil {
 				values = append(values, []interface{}{
-					resType, naming, k, color.New(color.FgGreen).SprintFunc()("+ " + fmt.Sprint(v)),
+					resType, naming, change.Key, color.New(color.FgRed).SprintFunc()("- " + fmt.Sprint(change.From)),
 				})
 			}
-
-			deleted := graph.Subtract(common.Properties, rem.Properties)
-			for k, v := range deleted {
+			if change.To != nil {
 				values = append(values, []interface{}{
-					resType, naming, k, color.New(color.FgRed).SprintFunc()("- " + fmt.Sprint(v)),
+					resType, naming, change.Key, color.New(color.FgGreen).SprintFunc()("+ " + fmt.Sprint(change.To)),
 				})
 			}
 		}
@@ -777,8 +858,7 @@ func (d *diffTreeDisplayer) Print(w io.Writer) error {
 	g := graph.NewGraph()
 
 	each := func(res *graph.Resource, distance int) error {
-		switch res.Meta["diff"] {
-		case "extra", "missing":
+		if res.Meta["diff"] == "extra" || res.Meta["diff"] == "missing" || len(d.diff.ResourcePropertyChanges(res.Id())) > 0 {
 			var parents []*graph.Resource
 			err := d.diff.MergedGraph().Accept(&graph.ParentsVisitor{From: res, Each: graph.VisitorCollectFunc(&parents)})
 			if err != nil {
@@ -819,7 +899,13 @@ func (d *diffTreeDisplayer) Print(w io.Writer) error {
 			fmt.Fprintf(w, "-%s%s, %s\n", tabs, res.Type(), res.Id())
 			color.Unset()
 		default:
-			fmt.Fprintf(w, "%s%s, %s\n", tabs, res.Type(), res.Id())
+			if changes := d.diff.ResourcePropertyChanges(res.Id()); len(changes) > 0 {
+				color.Set(color.FgYellow)
+				fmt.Fprintf(w, "~%s%s, %s (%s)\n", tabs, res.Type(), res.Id(), strings.Join(changedPropertyKeys(changes), ", "))
+				color.Unset()
+			} else {
+				fmt.Fprintf(w, "%s%s, %s\n", tabs, res.Type(), res.Id())
+			}
 		}
 		return nil
 	}
@@ -832,6 +918,17 @@ func (d *diffTreeDisplayer) Print(w io.Writer) error {
 	return nil
 }
 
+func changedPropertyKeys(changes []*graph.PropertyChange) (keys []string) {
+	seen := make(map[string]bool)
+	for _, c := range changes {
+		if !seen[c.Key] {
+			seen[c.Key] = true
+			keys = append(keys, c.Key)
+		}
+	}
+	return
+}
+
 type defaultSorter struct {
 	sortBy []int
 }
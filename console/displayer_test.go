@@ -18,6 +18,8 @@ package console
 
 import (
 	"bytes"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -347,6 +349,9 @@ func TestDiffDisplay(t *testing.T) {
 	).SetSource(diff).Build()
 
 	expected = `region, eu-west-1
+~	vpc, vpc_1 (Default)
+		subnet, sub_1
+~			instance, inst_1 (ID)
 	vpc, vpc_2
 +		subnet, new_subnet
 +			instance, inst_6
@@ -613,6 +618,61 @@ func TestFilter(t *testing.T) {
 	})
 }
 
+func TestFilterExpressions(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Volume("vol_1").Prop(p.Name, "data").Prop(p.Size, 100).Prop(p.State, "in-use").Build(),
+		resourcetest.Volume("vol_2").Prop(p.Name, "logs").Prop(p.Size, 8).Prop(p.State, "available").Build(),
+		resourcetest.Volume("vol_3").Prop(p.Size, 2048).Prop(p.State, "creating").Build(),
+	)
+
+	tcases := []struct {
+		filters []string
+		expect  []string
+	}{
+		{filters: []string{"state=use"}, expect: []string{"vol_1"}},
+		{filters: []string{"size>=100"}, expect: []string{"vol_1", "vol_3"}},
+		{filters: []string{"size<1T"}, expect: []string{"vol_1", "vol_2"}},
+		{filters: []string{"size>10G", "state!=creating"}, expect: []string{"vol_1"}},
+		{filters: []string{"name=~^d.*a$ or state in available|creating"}, expect: []string{"vol_1", "vol_2", "vol_3"}},
+		{filters: []string{"not exists name"}, expect: []string{"vol_3"}},
+		{filters: []string{"!state=use"}, expect: []string{"vol_2", "vol_3"}},
+	}
+
+	for _, tcase := range tcases {
+		displayer, err := BuildOptions(
+			WithRdfType("volume"),
+			WithHeaders(DefaultsColumnDefinitions["volume"]),
+			WithFormat("csv"),
+			WithFilters(tcase.filters),
+		).SetSource(g).Build()
+		if err != nil {
+			t.Fatalf("%v: %s", tcase.filters, err)
+		}
+		var w bytes.Buffer
+		if err := displayer.Print(&w); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n")[1:] {
+			ids = append(ids, strings.Split(line, ",")[0])
+		}
+		if got, want := ids, tcase.expect; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%v: got %v, want %v", tcase.filters, got, want)
+		}
+	}
+
+	for _, filter := range []string{"size", "unknown=1", "name=~("} {
+		if _, err := BuildOptions(
+			WithRdfType("volume"),
+			WithHeaders(DefaultsColumnDefinitions["volume"]),
+			WithFilters([]string{filter}),
+		).SetSource(g).Build(); err == nil {
+			t.Fatalf("%s: expected error", filter)
+		}
+	}
+}
+
 func TestCompareInterface(t *testing.T) {
 	if got, want := valueLowerOrEqual(interface{}(1), interface{}(4)), true; got != want {
 		t.Fatalf("got %t want %t", got, want)
@@ -56,6 +56,21 @@ func (d ColumnDefinitions) resolveKey(name string) string {
 	return ""
 }
 
+// ExtendColumns returns the definitions completed with the given ones whose property is not displayed yet.
+// Empty definitions, displaying all properties, are left empty.
+func ExtendColumns(defs []ColumnDefinition, extra ...ColumnDefinition) []ColumnDefinition {
+	if len(defs) == 0 {
+		return defs
+	}
+	result := append([]ColumnDefinition{}, defs...)
+	for _, e := range extra {
+		if ColumnDefinitions(result).resolveKey(e.propKey()) == "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
 type StringColumnDefinition struct {
 	Prop, Friendly string
 }
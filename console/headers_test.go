@@ -0,0 +1,43 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package console
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wallix/awless/cloud/properties"
+)
+
+func TestExtendColumns(t *testing.T) {
+	defs := []ColumnDefinition{
+		StringColumnDefinition{Prop: properties.ID},
+		StringColumnDefinition{Prop: properties.Region, Friendly: "Zone Region"},
+	}
+	account := SliceColumnDefinition{StringColumnDefinition{Prop: properties.Account}}
+
+	got := ExtendColumns(defs, StringColumnDefinition{Prop: properties.Region}, account)
+	if want := append(defs, account); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+	if got, want := len(defs), 2; got != want {
+		t.Fatalf("got %d, want %d: given definitions modified", got, want)
+	}
+	if got := ExtendColumns(nil, account); len(got) != 0 {
+		t.Fatalf("got %#v, want empty definitions", got)
+	}
+}
@@ -18,6 +18,8 @@ package console
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -52,6 +54,34 @@ const (
 	gb
 )
 
+// parseStorage converts a human storage size (ex: 512M, 10G, 1.5T) into the given unit
+func parseStorage(s string, unit storageUnit) (uint64, bool) {
+	s = strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "IB"), "B")
+	multipliers := map[string]float64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+	var suffix string
+	if l := len(s); l > 0 && strings.Contains("KMGT", s[l-1:]) {
+		suffix, s = s[l-1:], s[:l-1]
+	}
+	if suffix == "" {
+		return 0, false
+	}
+	nb, err := strconv.ParseFloat(s, 64)
+	if err != nil || nb < 0 {
+		return 0, false
+	}
+	nbBytes := nb * multipliers[suffix]
+	switch unit {
+	case kb:
+		return uint64(nbBytes / (1 << 10)), true
+	case mb:
+		return uint64(nbBytes / (1 << 20)), true
+	case gb:
+		return uint64(nbBytes / (1 << 30)), true
+	default:
+		return uint64(nbBytes), true
+	}
+}
+
 func HumanizeStorage(nb uint64, unit storageUnit) string {
 	var nbBytes uint64
 	switch unit {
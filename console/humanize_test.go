@@ -71,3 +71,31 @@ func TestHumanizeStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestParseStorage(t *testing.T) {
+	tcases := []struct {
+		from   string
+		unit   storageUnit
+		expect uint64
+		ok     bool
+	}{
+		{from: "100", unit: gb, ok: false},
+		{from: "3K", unit: b, expect: 3072, ok: true},
+		{from: "10G", unit: gb, expect: 10, ok: true},
+		{from: "10gb", unit: mb, expect: 10240, ok: true},
+		{from: "1.5GiB", unit: mb, expect: 1536, ok: true},
+		{from: "2T", unit: gb, expect: 2048, ok: true},
+		{from: "G", unit: gb, ok: false},
+		{from: "big", unit: gb, ok: false},
+	}
+
+	for _, tcase := range tcases {
+		nb, ok := parseStorage(tcase.from, tcase.unit)
+		if got, want := ok, tcase.ok; got != want {
+			t.Fatalf("%s: got %t, want %t", tcase.from, got, want)
+		}
+		if got, want := nb, tcase.expect; got != want {
+			t.Fatalf("%s: got %d, want %d", tcase.from, got, want)
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/boltdb/bolt"
+	"github.com/wallix/awless/encrypt"
 )
 
 const (
@@ -173,10 +174,14 @@ func (db *DB) getValue(key string) ([]byte, error) {
 		return value, err
 	}
 
-	return value, nil
+	return encrypt.Open(value)
 }
 
 func (db *DB) setValue(key string, value []byte) error {
+	value, err := encrypt.Seal(value)
+	if err != nil {
+		return err
+	}
 	return db.bolt.Update(func(tx *bolt.Tx) error {
 		b, err := tx.CreateBucketIfNotExists([]byte(awlessBucket))
 		if err != nil {
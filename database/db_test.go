@@ -17,8 +17,16 @@ limitations under the License.
 package database
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/wallix/awless/encrypt"
+	"github.com/wallix/awless/template"
 )
 
 func TestGetSetDatabaseValues(t *testing.T) {
@@ -89,3 +97,63 @@ func TestGetSetDatabaseValues(t *testing.T) {
 		t.Fatalf("got %s, want %s", got, want)
 	}
 }
+
+func TestEncryptedDatabaseValues(t *testing.T) {
+	db, close := newTestDb()
+	defer close()
+
+	if err := db.SetStringValue("plainkey", "plainvalue"); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Unsetenv(encrypt.PassphraseEnv)
+	keyFile := filepath.Join(os.Getenv("__AWLESS_HOME"), "storage.key")
+	if err := ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(keyFile)
+
+	if err := db.SetStringValue("mykey", "myvalue"); err != nil {
+		t.Fatal(err)
+	}
+	tpl := template.MustParse("create instance name=secret-name")
+	tpl.ID = "01BC0MZ1T1HRYGJ7YB4H6K6GPX"
+	if err := db.AddTemplate(&template.TemplateExecution{Template: tpl}); err != nil {
+		t.Fatal(err)
+	}
+
+	db.bolt.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket([]byte(awlessBucket)).Get([]byte("mykey")); !encrypt.IsEncrypted(v) {
+			t.Fatalf("got %q, want encrypted value", v)
+		}
+		if v := tx.Bucket([]byte(TEMPLATES_BUCKET)).Get([]byte("01BC0MZ1T1HRYGJ7YB4H6K6GPX")); strings.Contains(string(v), "secret-name") {
+			t.Fatalf("got %q, want encrypted value", v)
+		}
+		return nil
+	})
+
+	for key, expect := range map[string]string{"mykey": "myvalue", "plainkey": "plainvalue"} {
+		value, err := db.GetStringValue(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := value, expect; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+	tplExec, err := db.GetTemplate("01BC0MZ1T1HRYGJ7YB4H6K6GPX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tplExec.Template.String(), "create instance name=secret-name"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	os.Remove(keyFile)
+	if _, err := db.GetStringValue("mykey"); err != encrypt.ErrNoKey {
+		t.Fatalf("got %v, want %v", err, encrypt.ErrNoKey)
+	}
+	if err := db.SetStringValue("otherkey", "othervalue"); err != encrypt.ErrNoKey {
+		t.Fatalf("got %v, want %v", err, encrypt.ErrNoKey)
+	}
+}
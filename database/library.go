@@ -0,0 +1,157 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/wallix/awless/encrypt"
+)
+
+const LIBRARY_BUCKET = "library"
+
+// LibraryTemplate is a version of a named template saved in the local library
+type LibraryTemplate struct {
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Description string    `json:"description,omitempty"`
+	Content     string    `json:"content"`
+	Date        time.Time `json:"date"`
+}
+
+// SaveLibraryTemplate stores a new version of the named template
+func (db *DB) SaveLibraryTemplate(name, description, content string) (*LibraryTemplate, error) {
+	if name == "" {
+		return nil, errors.New("cannot save library template with empty name")
+	}
+
+	saved := &LibraryTemplate{Name: name, Description: description, Content: content, Date: time.Now().UTC()}
+
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(LIBRARY_BUCKET))
+		if err != nil {
+			return fmt.Errorf("create bucket %s: %s", LIBRARY_BUCKET, err)
+		}
+
+		versions, err := unmarshalLibraryVersions(bucket.Get([]byte(name)))
+		if err != nil {
+			return err
+		}
+		saved.Version = len(versions) + 1
+		if saved.Description == "" && len(versions) > 0 {
+			saved.Description = versions[len(versions)-1].Description
+		}
+
+		b, err := json.Marshal(append(versions, saved))
+		if err != nil {
+			return err
+		}
+		if b, err = encrypt.Seal(b); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(name), b)
+	})
+
+	return saved, err
+}
+
+// GetLibraryTemplate returns the given version of the named template (latest version if version <= 0)
+func (db *DB) GetLibraryTemplate(name string, version int) (*LibraryTemplate, error) {
+	versions, err := db.ListLibraryTemplateVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	if version <= 0 {
+		return versions[len(versions)-1], nil
+	}
+	if version > len(versions) {
+		return nil, fmt.Errorf("no version %d for library template '%s' (latest is %d)", version, name, len(versions))
+	}
+	return versions[version-1], nil
+}
+
+// ListLibraryTemplateVersions returns all the versions of the named template, oldest first
+func (db *DB) ListLibraryTemplateVersions(name string) (versions []*LibraryTemplate, err error) {
+	err = db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(LIBRARY_BUCKET))
+		if b == nil {
+			return errors.New("no library templates saved yet")
+		}
+		content := b.Get([]byte(name))
+		if content == nil {
+			return fmt.Errorf("no library template '%s'", name)
+		}
+		versions, err = unmarshalLibraryVersions(content)
+		return err
+	})
+
+	return
+}
+
+// ListLibraryTemplates returns the latest version of each named template, sorted by name
+func (db *DB) ListLibraryTemplates() ([]*LibraryTemplate, error) {
+	var results []*LibraryTemplate
+
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(LIBRARY_BUCKET))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			versions, err := unmarshalLibraryVersions(v)
+			if err != nil {
+				return fmt.Errorf("library template '%s': %s", k, err)
+			}
+			if len(versions) > 0 {
+				results = append(results, versions[len(versions)-1])
+			}
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// DeleteLibraryTemplate deletes all the versions of the named template
+func (db *DB) DeleteLibraryTemplate(name string) error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(LIBRARY_BUCKET))
+		if b == nil || b.Get([]byte(name)) == nil {
+			return fmt.Errorf("no library template '%s'", name)
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+func unmarshalLibraryVersions(b []byte) (versions []*LibraryTemplate, err error) {
+	if b == nil {
+		return
+	}
+	if b, err = encrypt.Open(b); err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &versions)
+	return
+}
@@ -0,0 +1,79 @@
+package database
+
+import "testing"
+
+func TestLibraryTemplates(t *testing.T) {
+	db, close := newTestDb()
+	defer close()
+
+	all, err := db.ListLibraryTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(all), 0; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+
+	if _, err = db.GetLibraryTemplate("myvpc", 0); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+
+	if _, err = db.SaveLibraryTemplate("myvpc", "Create a vpc", "create vpc cidr={vpc.cidr}"); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := db.SaveLibraryTemplate("myvpc", "", "create vpc cidr={vpc.cidr} name={vpc.name}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved.Version, 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+	if got, want := saved.Description, "Create a vpc"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if _, err = db.SaveLibraryTemplate("mysubnet", "Create a subnet", "create subnet cidr={subnet.cidr}"); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := db.GetLibraryTemplate("myvpc", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := latest.Content, "create vpc cidr={vpc.cidr} name={vpc.name}"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	first, err := db.GetLibraryTemplate("myvpc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := first.Content, "create vpc cidr={vpc.cidr}"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if _, err = db.GetLibraryTemplate("myvpc", 3); err == nil {
+		t.Fatal("expected error for unknown version")
+	}
+
+	all, err = db.ListLibraryTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(all), 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+	if got, want := all[0].Name, "mysubnet"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if got, want := all[1].Version, 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+
+	if err = db.DeleteLibraryTemplate("myvpc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.GetLibraryTemplate("myvpc", 0); err == nil {
+		t.Fatal("expected error for deleted template")
+	}
+	if err = db.DeleteLibraryTemplate("myvpc"); err == nil {
+		t.Fatal("expected error when deleting unknown template")
+	}
+}
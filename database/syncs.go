@@ -0,0 +1,83 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/wallix/awless/encrypt"
+)
+
+const SYNCS_BUCKET = "syncs"
+
+// GetLastSyncs returns the last sync time of the resource types synced in the given scope (ex: profile and region)
+func (db *DB) GetLastSyncs(scope string) (map[string]time.Time, error) {
+	syncs := make(map[string]time.Time)
+	prefix := scope + "/"
+
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(SYNCS_BUCKET))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			key := string(k)
+			if !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+			v, err := encrypt.Open(v)
+			if err != nil {
+				return fmt.Errorf("last sync of %s: %s", key, err)
+			}
+			var t time.Time
+			if err := t.UnmarshalBinary(v); err != nil {
+				return fmt.Errorf("last sync of %s: %s", key, err)
+			}
+			syncs[strings.TrimPrefix(key, prefix)] = t
+			return nil
+		})
+	})
+
+	return syncs, err
+}
+
+// SetLastSyncs sets the last sync time of resource types synced in the given scope
+func (db *DB) SetLastSyncs(scope string, t time.Time, resourceTypes ...string) error {
+	bin, err := t.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if bin, err = encrypt.Seal(bin); err != nil {
+		return err
+	}
+
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(SYNCS_BUCKET))
+		if err != nil {
+			return fmt.Errorf("create bucket %s: %s", SYNCS_BUCKET, err)
+		}
+		for _, typ := range resourceTypes {
+			if err := bucket.Put([]byte(scope+"/"+typ), bin); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLastSyncs(t *testing.T) {
+	db, close := newTestDb()
+	defer close()
+
+	syncs, err := db.GetLastSyncs("default/eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(syncs), 0; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+
+	first := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	if err = db.SetLastSyncs("default/eu-west-1", first, "instance", "vpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.SetLastSyncs("default/eu-west-1", second, "vpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.SetLastSyncs("default/us-east-1", second, "instance"); err != nil {
+		t.Fatal(err)
+	}
+
+	syncs, err = db.GetLastSyncs("default/eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(syncs), 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+	for typ, want := range map[string]time.Time{"instance": first, "vpc": second} {
+		if got := syncs[typ]; !got.Equal(want) {
+			t.Fatalf("%s: got %s, want %s", typ, got, want)
+		}
+	}
+
+	syncs, err = db.GetLastSyncs("default/us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for typ := range syncs {
+		types = append(types, typ)
+	}
+	if got, want := types, []string{"instance"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/wallix/awless/encrypt"
 	"github.com/wallix/awless/template"
 
 	"github.com/boltdb/bolt"
@@ -42,6 +43,9 @@ func (db *DB) AddTemplate(tplExec *template.TemplateExecution) error {
 		if err != nil {
 			return err
 		}
+		if b, err = encrypt.Seal(b); err != nil {
+			return err
+		}
 
 		return bucket.Put([]byte(tplExec.ID), b)
 	})
@@ -56,7 +60,11 @@ func (db *DB) GetTemplate(id string) (*template.TemplateExecution, error) {
 			return errors.New("no templates stored yet")
 		}
 		if content := b.Get([]byte(id)); content != nil {
-			return tplExec.UnmarshalJSON(content)
+			decrypted, err := encrypt.Open(content)
+			if err != nil {
+				return err
+			}
+			return tplExec.UnmarshalJSON(decrypted)
 		} else {
 			return fmt.Errorf("no content for id '%s'", id)
 		}
@@ -104,8 +112,13 @@ func (db *DB) ListTemplates() ([]*LoadedTemplate, error) {
 
 		for k, v := c.First(); k != nil; k, v = c.Next() {
 			tplExec := &template.TemplateExecution{}
-			terr := tplExec.UnmarshalJSON(v)
-			lt := &LoadedTemplate{TplExec: tplExec, Err: terr, Key: string(k), Raw: string(v)}
+			raw, terr := encrypt.Open(v)
+			if terr != nil {
+				raw = v
+			} else {
+				terr = tplExec.UnmarshalJSON(raw)
+			}
+			lt := &LoadedTemplate{TplExec: tplExec, Err: terr, Key: string(k), Raw: string(raw)}
 			results = append(results, lt)
 		}
 
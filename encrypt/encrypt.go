@@ -0,0 +1,261 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encrypt provides the optional at-rest encryption of the awless local storage.
+//
+// Encryption is enabled with a passphrase exported as AWLESS_STORAGE_PASSPHRASE,
+// or a key file (any file of random content, ex: `head -c 32 /dev/urandom > ~/.awless/storage.key`)
+// at ~/.awless/storage.key or at the path exported as AWLESS_STORAGE_KEY_FILE.
+// Data written without encryption is still read as is, and encrypted when rewritten.
+// Once data has been encrypted, writing data requires the key as well, not to store it
+// in plaintext by mistake: remove ~/.awless/storage.encrypted to write plaintext again.
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	PassphraseEnv = "AWLESS_STORAGE_PASSPHRASE"
+	KeyFileEnv    = "AWLESS_STORAGE_KEY_FILE"
+
+	keyFilename    = "storage.key"
+	saltFilename   = "storage.salt"
+	markerFilename = "storage.encrypted"
+
+	minKeyFileSize       = 16
+	passphraseIterations = 100000
+)
+
+var (
+	magic = []byte("AWLESSENC1")
+
+	ErrNoKey = fmt.Errorf("encrypted data: export your passphrase as %s or provide the key file (see %s)", PassphraseEnv, KeyFileEnv)
+)
+
+// Seal returns the data encrypted with the storage key, or the data unchanged
+// when no passphrase or key file is provided. The same data encrypted with the
+// same key gives the same result, so that unchanged files are seen as such.
+// It returns ErrNoKey without key once data has been encrypted.
+func Seal(data []byte) ([]byte, error) {
+	k, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		if fileExists(markerPath()) {
+			return nil, ErrNoKey
+		}
+		return data, nil
+	}
+	if err := writeMarker(); err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, k.nonce)
+	mac.Write(data)
+	nonce := mac.Sum(nil)[:k.aead.NonceSize()]
+
+	sealed := make([]byte, 0, len(magic)+len(nonce)+len(data)+k.aead.Overhead())
+	sealed = append(sealed, magic...)
+	sealed = append(sealed, nonce...)
+	return k.aead.Seal(sealed, nonce, data, magic), nil
+}
+
+// Open returns the decrypted data, or the data unchanged if it has not been encrypted
+func Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	k, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, ErrNoKey
+	}
+
+	data = data[len(magic):]
+	if len(data) < k.aead.NonceSize() {
+		return nil, errors.New("encrypted data: truncated")
+	}
+	nonce, ciphertext := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+	opened, err := k.aead.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, errors.New("encrypted data: cannot decrypt (wrong passphrase or key file?)")
+	}
+	return opened, nil
+}
+
+// IsEncrypted returns whether the data has been encrypted by Seal
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Enabled returns whether a passphrase or key file is provided to encrypt the data written
+func Enabled() bool {
+	k, err := currentKeys()
+	return err == nil && k != nil
+}
+
+type keys struct {
+	aead  cipher.AEAD
+	nonce []byte
+}
+
+var (
+	cacheMu     sync.Mutex
+	cacheSource string
+	cacheKeys   *keys
+)
+
+// currentKeys returns the keys derived from the passphrase or key file, or nil if none is provided
+func currentKeys() (*keys, error) {
+	source, secret, err := keySource()
+	if err != nil || source == "" {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if source == cacheSource {
+		return cacheKeys, nil
+	}
+
+	var master []byte
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		salt, err := readOrCreateSalt()
+		if err != nil {
+			return nil, err
+		}
+		master = pbkdf2SHA256([]byte(passphrase), salt, passphraseIterations)
+	} else {
+		sum := sha256.Sum256(secret)
+		master = sum[:]
+	}
+
+	block, err := aes.NewCipher(derive(master, "encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheSource, cacheKeys = source, &keys{aead: aead, nonce: derive(master, "nonce")}
+	return cacheKeys, nil
+}
+
+// keySource identifies the passphrase or key file in use, returning the key file content if any
+func keySource() (string, []byte, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return "passphrase:" + passphrase + ":" + saltPath(), nil, nil
+	}
+	path := os.Getenv(KeyFileEnv)
+	if path == "" {
+		path = filepath.Join(os.Getenv("__AWLESS_HOME"), keyFilename)
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && os.Getenv(KeyFileEnv) == "" {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("storage key file: %s", err)
+	}
+	if len(content) < minKeyFileSize {
+		return "", nil, fmt.Errorf("storage key file %s: expected at least %d bytes", path, minKeyFileSize)
+	}
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("file:%s:%x", path, sum), content, nil
+}
+
+func markerPath() string {
+	return filepath.Join(os.Getenv("__AWLESS_HOME"), markerFilename)
+}
+
+// writeMarker records that data has been encrypted, so that it is not written in plaintext without key
+func writeMarker() error {
+	if fileExists(markerPath()) {
+		return nil
+	}
+	if err := ioutil.WriteFile(markerPath(), nil, 0600); err != nil {
+		return fmt.Errorf("storage encryption marker: %s", err)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func saltPath() string {
+	return filepath.Join(os.Getenv("__AWLESS_HOME"), saltFilename)
+}
+
+// readOrCreateSalt returns the salt of the passphrase, generated on first use
+func readOrCreateSalt() ([]byte, error) {
+	salt, err := ioutil.ReadFile(saltPath())
+	if err == nil {
+		return salt, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("storage salt: %s", err)
+	}
+	salt = make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(saltPath(), salt, 0600); err != nil {
+		return nil, fmt.Errorf("storage salt: %s", err)
+	}
+	return salt, nil
+}
+
+func derive(master []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a 32 bytes key from a password (PBKDF2 with HMAC-SHA256, RFC 8018)
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
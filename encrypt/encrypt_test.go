@@ -0,0 +1,127 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encrypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	home, err := ioutil.TempDir("", "awless-encrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	os.Setenv("__AWLESS_HOME", home)
+	defer os.Unsetenv("__AWLESS_HOME")
+	os.Unsetenv(PassphraseEnv)
+	os.Unsetenv(KeyFileEnv)
+
+	data := []byte("inventory with 10.0.0.1")
+
+	if Enabled() {
+		t.Fatal("expected encryption disabled without passphrase nor key file")
+	}
+	sealed, err := Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sealed, data; !bytes.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	keyFile := filepath.Join(home, "storage.key")
+	if err := ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !Enabled() {
+		t.Fatal("expected encryption enabled with key file")
+	}
+	sealed, err = Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, []byte("10.0.0.1")) {
+		t.Fatalf("got %q, want encrypted data", sealed)
+	}
+	if again, _ := Seal(data); !bytes.Equal(again, sealed) {
+		t.Fatal("expected same encryption of same data")
+	}
+	opened, err := Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := opened, data; !bytes.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if opened, err = Open(data); err != nil || !bytes.Equal(opened, data) {
+		t.Fatalf("got %q (err %v), want plain data unchanged", opened, err)
+	}
+
+	os.Setenv(PassphraseEnv, "my passphrase")
+	defer os.Unsetenv(PassphraseEnv)
+	if _, err := Open(sealed); err == nil {
+		t.Fatal("expected error with wrong key")
+	}
+	withPassphrase, err := Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "storage.salt")); err != nil {
+		t.Fatal(err)
+	}
+	if opened, err = Open(withPassphrase); err != nil || !bytes.Equal(opened, data) {
+		t.Fatalf("got %q (err %v), want %q", opened, err, data)
+	}
+
+	os.Unsetenv(PassphraseEnv)
+	os.Remove(keyFile)
+	if _, err := Open(withPassphrase); err != ErrNoKey {
+		t.Fatalf("got %v, want %v", err, ErrNoKey)
+	}
+	if _, err := Seal(data); err != ErrNoKey {
+		t.Fatalf("got %v, want %v once data encrypted", err, ErrNoKey)
+	}
+
+	os.Setenv(KeyFileEnv, filepath.Join(home, "missing.key"))
+	defer os.Unsetenv(KeyFileEnv)
+	if _, err := Seal(data); err == nil {
+		t.Fatal("expected error with missing key file")
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// test vectors of RFC 7914
+	tcases := []struct {
+		password, salt string
+		iterations     int
+		expect         string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	}
+	for _, tcase := range tcases {
+		if got, want := hex.EncodeToString(pbkdf2SHA256([]byte(tcase.password), []byte(tcase.salt), tcase.iterations)), tcase.expect; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+// GenericCheckDriversDefs returns the check and wait drivers relying on the resources fetchers.
+// They are defined for each fetched resource type having drivers but no dedicated check driver.
+func GenericCheckDriversDefs() (defs []driversDef) {
+	for _, service := range FetchersDefs {
+		for _, api := range service.Api {
+			def := driversDef{Api: api}
+			for _, fetcher := range service.Fetchers {
+				if fetcher.Api == api && hasGenericCheck(fetcher.ResourceType) {
+					def.Drivers = append(def.Drivers, genericCheckDrivers(fetcher.ResourceType)...)
+				}
+			}
+			if len(def.Drivers) > 0 {
+				defs = append(defs, def)
+			}
+		}
+	}
+	return
+}
+
+// GenericCheckTypes returns the resource types of the service checked through its fetchers
+func (def fetchersDef) GenericCheckTypes() (types []string) {
+	for _, fetcher := range def.Fetchers {
+		if hasGenericCheck(fetcher.ResourceType) {
+			types = append(types, fetcher.ResourceType)
+		}
+	}
+	return
+}
+
+func hasGenericCheck(entity string) bool {
+	var hasDriver bool
+	for _, service := range DriversDefs {
+		for _, d := range service.Drivers {
+			if d.Entity != entity {
+				continue
+			}
+			if d.Action == "check" {
+				return false
+			}
+			hasDriver = true
+		}
+	}
+	return hasDriver
+}
+
+func genericCheckDrivers(entity string) []driver {
+	return []driver{
+		{
+			Action: "check", Entity: entity, ManualFuncDefinition: true,
+			RequiredParams: []param{
+				{TemplateName: "id"},
+				{TemplateName: "state"},
+				{TemplateName: "timeout"},
+			},
+			ExtraParams: []param{
+				{TemplateName: "property"},
+			},
+		},
+		{
+			Action: "wait", Entity: entity, ManualFuncDefinition: true,
+			RequiredParams: []param{
+				{TemplateName: "id"},
+				{TemplateName: "state"},
+			},
+			ExtraParams: []param{
+				{TemplateName: "property"},
+				{TemplateName: "timeout"},
+			},
+		},
+	}
+}
@@ -35,6 +35,7 @@ type driver struct {
 	Input, Output, ApiMethod, OutputExtractor string
 	DryRunUnsupported                         bool
 	ManualFuncDefinition                      bool
+	WaitState                                 string // state reached on success, enabling the 'wait' extra param
 }
 
 func (d *driver) RequiredKeys() []string {
@@ -51,6 +52,9 @@ func (d *driver) ExtraKeys() []string {
 	for _, p := range d.ExtraParams {
 		keys = append(keys, p.TemplateName)
 	}
+	if d.WaitState != "" {
+		keys = append(keys, "wait")
+	}
 
 	return sortUnique(keys)
 }
@@ -126,7 +130,7 @@ var DriversDefs = []driversDef{
 
 			// INSTANCES
 			{
-				Action: "create", Entity: cloud.Instance, Input: "RunInstancesInput", Output: "Reservation", ApiMethod: "RunInstances", OutputExtractor: "aws.StringValue(output.Instances[0].InstanceId)",
+				Action: "create", Entity: cloud.Instance, Input: "RunInstancesInput", Output: "Reservation", ApiMethod: "RunInstances", OutputExtractor: "aws.StringValue(output.Instances[0].InstanceId)", WaitState: "running",
 				RequiredParams: []param{
 					{AwsField: "ImageId", TemplateName: "image", AwsType: "awsstr"},
 					{AwsField: "MaxCount", TemplateName: "count", AwsType: "awsint64"},
@@ -161,13 +165,13 @@ var DriversDefs = []driversDef{
 				},
 			},
 			{
-				Action: "start", Entity: cloud.Instance, Input: "StartInstancesInput", Output: "StartInstancesOutput", ApiMethod: "StartInstances", OutputExtractor: "aws.StringValue(output.StartingInstances[0].InstanceId)",
+				Action: "start", Entity: cloud.Instance, Input: "StartInstancesInput", Output: "StartInstancesOutput", ApiMethod: "StartInstances", OutputExtractor: "aws.StringValue(output.StartingInstances[0].InstanceId)", WaitState: "running",
 				RequiredParams: []param{
 					{AwsField: "InstanceIds", TemplateName: "id", AwsType: "awsstringslice"},
 				},
 			},
 			{
-				Action: "stop", Entity: cloud.Instance, Input: "StopInstancesInput", Output: "StopInstancesOutput", ApiMethod: "StopInstances", OutputExtractor: "aws.StringValue(output.StoppingInstances[0].InstanceId)",
+				Action: "stop", Entity: cloud.Instance, Input: "StopInstancesInput", Output: "StopInstancesOutput", ApiMethod: "StopInstances", OutputExtractor: "aws.StringValue(output.StoppingInstances[0].InstanceId)", WaitState: "stopped",
 				RequiredParams: []param{
 					{AwsField: "InstanceIds", TemplateName: "id", AwsType: "awsstringslice"},
 				},
@@ -180,6 +184,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.Instance, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			// Security Group
 			{
 				Action: "create", Entity: cloud.SecurityGroup, Input: "CreateSecurityGroupInput", Output: "CreateSecurityGroupOutput", ApiMethod: "CreateSecurityGroup", OutputExtractor: "aws.StringValue(output.GroupId)",
@@ -216,6 +230,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.SecurityGroup, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			{
 				Action: "attach", Entity: cloud.SecurityGroup, ManualFuncDefinition: true,
 				RequiredParams: []param{
@@ -272,7 +296,7 @@ var DriversDefs = []driversDef{
 
 			// VOLUME
 			{
-				Action: "create", Entity: cloud.Volume, Input: "CreateVolumeInput", Output: "Volume", ApiMethod: "CreateVolume", OutputExtractor: "aws.StringValue(output.VolumeId)",
+				Action: "create", Entity: cloud.Volume, Input: "CreateVolumeInput", Output: "Volume", ApiMethod: "CreateVolume", OutputExtractor: "aws.StringValue(output.VolumeId)", WaitState: "available",
 				RequiredParams: []param{
 					{AwsField: "AvailabilityZone", TemplateName: "availabilityzone", AwsType: "awsstr"},
 					{AwsField: "Size", TemplateName: "size", AwsType: "awsint64"},
@@ -286,6 +310,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.Volume, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			{
 				Action: "delete", Entity: cloud.Volume, Input: "DeleteVolumeInput", Output: "DeleteVolumeOutput", ApiMethod: "DeleteVolume",
 				RequiredParams: []param{
@@ -478,7 +512,7 @@ var DriversDefs = []driversDef{
 		Drivers: []driver{
 			// LoadBalancer
 			{
-				Action: "create", Entity: cloud.LoadBalancer, Input: "CreateLoadBalancerInput", Output: "CreateLoadBalancerOutput", ApiMethod: "CreateLoadBalancer", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.LoadBalancers[0].LoadBalancerArn)",
+				Action: "create", Entity: cloud.LoadBalancer, Input: "CreateLoadBalancerInput", Output: "CreateLoadBalancerOutput", ApiMethod: "CreateLoadBalancer", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.LoadBalancers[0].LoadBalancerArn)", WaitState: "active",
 				RequiredParams: []param{
 					{AwsField: "Name", TemplateName: "name", AwsType: "awsstr"},
 					{AwsField: "Subnets", TemplateName: "subnets", AwsType: "awsstringslice"},
@@ -503,6 +537,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.LoadBalancer, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			// Listener
 			{
 				Action: "create", Entity: cloud.Listener, Input: "CreateListenerInput", Output: "CreateListenerOutput", ApiMethod: "CreateListener", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.Listeners[0].ListenerArn)",
@@ -647,6 +691,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.ScalingGroup, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "name"},
+					{TemplateName: "count"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			{
 				Action: "create", Entity: cloud.ScalingPolicy, ApiMethod: "PutScalingPolicy", Input: "PutScalingPolicyInput", Output: "PutScalingPolicyOutput", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.PolicyARN)",
 				RequiredParams: []param{
@@ -673,7 +727,7 @@ var DriversDefs = []driversDef{
 		Drivers: []driver{
 			// Database
 			{
-				Action: "create", Entity: cloud.Database, Input: "CreateDBInstanceInput", Output: "CreateDBInstanceOutput", ApiMethod: "CreateDBInstance", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.DBInstance.DBInstanceIdentifier)",
+				Action: "create", Entity: cloud.Database, Input: "CreateDBInstanceInput", Output: "CreateDBInstanceOutput", ApiMethod: "CreateDBInstance", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.DBInstance.DBInstanceIdentifier)", WaitState: "available",
 				RequiredParams: []param{
 					{AwsField: "DBInstanceClass", TemplateName: "type", AwsType: "awsstr"},
 					{AwsField: "DBInstanceIdentifier", TemplateName: "id", AwsType: "awsstr"},
@@ -726,6 +780,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.Database, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			{
 				Action: "create", Entity: cloud.DbSubnetGroup, ApiMethod: "CreateDBSubnetGroup", Input: "CreateDBSubnetGroupInput", Output: "CreateDBSubnetGroupOutput", DryRunUnsupported: true, OutputExtractor: "aws.StringValue(output.DBSubnetGroup.DBSubnetGroupName)",
 				RequiredParams: []param{
@@ -1198,7 +1262,7 @@ var DriversDefs = []driversDef{
 		ApiInterface: "CloudFrontAPI",
 		Drivers: []driver{
 			{
-				Action: "create", Entity: cloud.Distribution, ManualFuncDefinition: true,
+				Action: "create", Entity: cloud.Distribution, ManualFuncDefinition: true, WaitState: "Deployed",
 				RequiredParams: []param{
 					{TemplateName: "origin-domain"},
 				},
@@ -1224,6 +1288,16 @@ var DriversDefs = []driversDef{
 					{TemplateName: "timeout"},
 				},
 			},
+			{
+				Action: "wait", Entity: cloud.Distribution, ManualFuncDefinition: true,
+				RequiredParams: []param{
+					{TemplateName: "id"},
+					{TemplateName: "state"},
+				},
+				ExtraParams: []param{
+					{TemplateName: "timeout"},
+				},
+			},
 			{
 				Action: "update", Entity: cloud.Distribution, ManualFuncDefinition: true,
 				RequiredParams: []param{
@@ -33,7 +33,7 @@ func generateTemplateTemplates() {
 	}
 
 	var buff bytes.Buffer
-	err = templ.Execute(&buff, aws.DriversDefs)
+	err = templ.Execute(&buff, append(aws.DriversDefs, aws.GenericCheckDriversDefs()...))
 	if err != nil {
 		panic(err)
 	}
@@ -316,7 +316,15 @@ func (d *{{ Title $service.Api }}Driver) {{ Title $def.Action }}_{{ Title $def.E
 	}
 		{{- end }}
 	{{- end }}
-	
+	{{- if $def.WaitState }}
+	if timeout, ok := waitTimeout(params); ok {
+		_, err = d.Check_{{ Title $def.Entity }}(map[string]interface{}{"id": id, "state": "{{ $def.WaitState }}", "timeout": timeout})
+		if err != nil {
+			return nil, fmt.Errorf("{{ $def.Action }} {{ $def.Entity }}: wait: %s", err)
+		}
+	}
+	{{- end }}
+
 	d.logger.Infof("{{ $def.Action }} {{ $def.Entity }} '%s' done", id)
 	return id, nil
 	{{- else }}
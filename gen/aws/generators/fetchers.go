@@ -176,6 +176,9 @@ func (s *{{ Title $service.Name }}) Drivers() []driver.Driver {
 			{{- end }}
 		
 		{{- end }}
+		{{- if $service.GenericCheckTypes }}
+		awsdriver.NewFetcherCheckDriver(s.FetchByType, {{ range $, $typ := $service.GenericCheckTypes }}"{{ $typ }}", {{ end }}),
+		{{- end }}
 	}
 }
 
@@ -279,14 +282,21 @@ func (s *{{ Title $service.Name }}) FetchResources() (*graph.Graph, error) {
 }
 
 func (s *{{ Title $service.Name }}) FetchByType(t string) (*graph.Graph, error) {
+	graph, _, err := s.FetchByTypeWithRelations(t)
+	return graph, err
+}
+
+// FetchByTypeWithRelations returns the resources of the given type, without relations, and the func
+// building their relations in the graph of the service they are merged into (see graph.RebuildRelations)
+func (s *{{ Title $service.Name }}) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
   switch t {
   {{- range $index, $fetcher := $service.Fetchers }}
   case "{{ $fetcher.ResourceType }}":
-		graph, _, err := s.fetch_all_{{ $fetcher.ResourceType }}_graph()
-    return graph, err
+		graph, resources, err := s.fetch_all_{{ $fetcher.ResourceType }}_graph()
+    return graph, relationsBuilder("{{ $fetcher.ResourceType }}", resources), err
   {{- end }}
   default:
-    return nil, fmt.Errorf("aws {{ $service.Name }}: unsupported fetch for type %s", t)
+    return nil, nil, fmt.Errorf("aws {{ $service.Name }}: unsupported fetch for type %s", t)
   }
 }
 
@@ -0,0 +1,267 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/cloud/rdf"
+	tstore "github.com/wallix/triplestore"
+)
+
+const (
+	DanglingReference = "dangling reference"
+	MissingParent     = "missing parent"
+	RelationCycle     = "relation cycle"
+)
+
+// Inconsistency is an issue found in a graph by CheckConsistency
+type Inconsistency struct {
+	Kind     string
+	Resource *Resource
+	Message  string
+}
+
+func (i *Inconsistency) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Kind, i.Resource, i.Message)
+}
+
+// ConsistencyRules are the expectations of CheckConsistency for a graph
+type ConsistencyRules struct {
+	// ExpectedParents are the parent types of which resources of a type need one
+	ExpectedParents map[string][]string
+	// IgnoredProperties are the properties whose references are not checked
+	// (ex: referencing resources never synced)
+	IgnoredProperties []string
+}
+
+// CheckConsistency reports, sorted by kind and resource:
+//   - dangling references: relations with resources absent from the graph and
+//     properties (or route targets) referencing unknown resources by id, name or arn
+//   - missing parents: resources not having a parent of the expected types
+//   - relation cycles: resources being their own ancestor through parentOf or applyOn relations
+func (g *Graph) CheckConsistency(rules ConsistencyRules) ([]*Inconsistency, error) {
+	var inconsistencies []*Inconsistency
+	report := func(kind string, res *Resource, format string, a ...interface{}) {
+		inconsistencies = append(inconsistencies, &Inconsistency{Kind: kind, Resource: res, Message: fmt.Sprintf(format, a...)})
+	}
+
+	resources, err := g.indexedResources(func(idx *resourcesIndex) (ids []string) {
+		for _, typIds := range idx.byType {
+			ids = append(ids, typIds...)
+		}
+		return uniqueSortedIds(ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[string]*Resource)
+	known := make(map[string]bool)
+	for _, res := range resources {
+		byId[res.Id()] = res
+		known[res.Id()] = true
+		for _, key := range []string{"Name", "Arn"} {
+			if v, ok := res.Properties[key].(string); ok && v != "" {
+				known[v] = true
+			}
+		}
+	}
+	resourceOf := func(id string) *Resource {
+		if res, ok := byId[id]; ok {
+			return res
+		}
+		return NotFoundResource(id)
+	}
+
+	snap := g.store.Snapshot()
+	for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+		for _, t := range snap.WithPredicate(pred) {
+			obj, ok := t.Object().Resource()
+			if !ok {
+				return nil, fmt.Errorf("triple %s %s: object is not a resource identifier", t.Subject(), pred)
+			}
+			switch {
+			case byId[t.Subject()] == nil:
+				report(DanglingReference, resourceOf(obj), "%s relation from unknown resource '%s'", trimNS(pred), t.Subject())
+			case byId[obj] == nil:
+				report(DanglingReference, resourceOf(t.Subject()), "%s relation to unknown resource '%s'", trimNS(pred), obj)
+			}
+		}
+	}
+
+	ignored := make(map[string]bool)
+	for _, p := range rules.IgnoredProperties {
+		ignored[p] = true
+	}
+	for _, res := range resources {
+		for _, key := range sortedPropertyKeys(res.Properties) {
+			if ignored[key] {
+				continue
+			}
+			for _, ref := range propertyReferences(key, res.Properties[key]) {
+				if !known[ref] {
+					report(DanglingReference, res, "property %s references unknown resource '%s'", key, ref)
+				}
+			}
+		}
+	}
+
+	for _, res := range resources {
+		expected, ok := rules.ExpectedParents[res.Type()]
+		if !ok {
+			continue
+		}
+		var found bool
+		for _, t := range snap.WithPredObj(rdf.ParentOf, tstore.Resource(res.Id())) {
+			if parent, ok := byId[t.Subject()]; ok && containsString(expected, parent.Type()) {
+				found = true
+			}
+		}
+		if !found {
+			report(MissingParent, res, "expected a parent of type %s", strings.Join(expected, " or "))
+		}
+	}
+
+	for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+		for _, cycle := range relationCycles(snap, pred) {
+			report(RelationCycle, resourceOf(cycle[0]), "%s cycle %s -> %s", trimNS(pred), strings.Join(cycle, " -> "), cycle[0])
+		}
+	}
+
+	sort.SliceStable(inconsistencies, func(i, j int) bool {
+		if a, b := inconsistencies[i], inconsistencies[j]; a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		} else if a.Resource.Id() != b.Resource.Id() {
+			return a.Resource.Id() < b.Resource.Id()
+		} else {
+			return a.Message < b.Message
+		}
+	})
+	return inconsistencies, nil
+}
+
+func propertyReferences(key string, value interface{}) (refs []string) {
+	switch v := value.(type) {
+	case []*Route:
+		for _, route := range v {
+			for _, target := range route.Targets {
+				if target.Ref != "" && target.Ref != "local" {
+					refs = append(refs, target.Ref)
+				}
+			}
+		}
+		return
+	}
+
+	propId, err := rdf.Properties.GetRDFId(key)
+	if err != nil {
+		return
+	}
+	definedBy, _ := rdf.Properties.GetDefinedBy(propId)
+	dataType, _ := rdf.Properties.GetDataType(propId)
+	switch {
+	case definedBy == rdf.RdfsClass:
+		if s, ok := value.(string); ok && s != "" {
+			refs = append(refs, s)
+		}
+	case definedBy == rdf.RdfsList && dataType == rdf.RdfsClass:
+		if list, ok := value.([]string); ok {
+			for _, s := range list {
+				if s != "" {
+					refs = append(refs, s)
+				}
+			}
+		}
+	}
+	return
+}
+
+// relationCycles returns the cycles of the given relation, each starting with its lowest id
+func relationCycles(snap tstore.RDFGraph, pred string) [][]string {
+	edges := make(map[string][]string)
+	for _, t := range snap.WithPredicate(pred) {
+		if obj, ok := t.Object().Resource(); ok {
+			edges[t.Subject()] = append(edges[t.Subject()], obj)
+		}
+	}
+	var nodes []string
+	for n := range edges {
+		nodes = append(nodes, n)
+		sort.Strings(edges[n])
+	}
+	sort.Strings(nodes)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var cycles [][]string
+	var path []string
+
+	var visit func(n string)
+	visit = func(n string) {
+		state[n] = visiting
+		path = append(path, n)
+		for _, next := range edges[n] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				var cycle []string
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == next {
+						cycle = append(cycle, path[i:]...)
+						break
+					}
+				}
+				lowest := 0
+				for i, id := range cycle {
+					if id < cycle[lowest] {
+						lowest = i
+					}
+				}
+				cycle = append(append([]string{}, cycle[lowest:]...), cycle[:lowest]...)
+				if key := strings.Join(cycle, " "); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = visited
+	}
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	return cycles
+}
+
+func containsString(arr []string, s string) bool {
+	for _, a := range arr {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
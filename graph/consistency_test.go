@@ -0,0 +1,85 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.VPC("vpc_1").Build(),
+		resourcetest.Subnet("sub_1").Prop("Vpc", "vpc_1").Build(),
+		resourcetest.Subnet("sub_2").Prop("Vpc", "vpc_2").Build(),
+		resourcetest.KeyPair("kp_1").Prop("Name", "my-key").Build(),
+		resourcetest.Instance("inst_1").Prop("KeyPair", "my-key").Prop("SecurityGroups", []string{"sg_1", "sg_2"}).Build(),
+		resourcetest.Instance("inst_2").Prop("KeyPair", "deleted-key").Build(),
+		resourcetest.SecurityGroup("sg_1").Build(),
+		resourcetest.RouteTable("rt_1").Prop("Routes", []*graph.Route{
+			{Destination: anywhere, Targets: []*graph.RouteTarget{{Type: graph.GatewayTarget, Ref: "local"}, {Type: graph.GatewayTarget, Ref: "igw_1"}}},
+		}).Build(),
+		resourcetest.Zone("zone_1").Build(), resourcetest.Record("rec_1").Build(), resourcetest.Record("rec_2").Build(),
+	)
+	resourcetest.AddParents(g,
+		"eu-west-1 -> vpc_1", "vpc_1 -> sub_1", "vpc_1 -> sg_1", "sub_1 -> inst_1", "sub_2 -> inst_2",
+		"vpc_1 -> rt_1", "vpc_2 -> sub_2",
+		"rec_1 -> rec_2", "rec_2 -> zone_1", "zone_1 -> rec_1",
+	)
+	g.AddAppliesOnRelation(graph.InitResource("", "sg_1"), graph.InitResource("", "inst_1"))
+	g.AddAppliesOnRelation(graph.InitResource("", "sg_2"), graph.InitResource("", "inst_1"))
+
+	inconsistencies, err := g.CheckConsistency(graph.ConsistencyRules{
+		ExpectedParents:   map[string][]string{"subnet": {"vpc"}, "instance": {"subnet"}, "vpc": {"region"}, "keypair": {"region"}},
+		IgnoredProperties: []string{"Vpc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, i := range inconsistencies {
+		got = append(got, i.String())
+	}
+	exp := []string{
+		"dangling reference: inst_1[instance]: applyOn relation from unknown resource 'sg_2'",
+		"dangling reference: inst_1[instance]: property SecurityGroups references unknown resource 'sg_2'",
+		"dangling reference: inst_2[instance]: property KeyPair references unknown resource 'deleted-key'",
+		"dangling reference: rt_1[routetable]: property Routes references unknown resource 'igw_1'",
+		"dangling reference: sub_2[subnet]: parentOf relation from unknown resource 'vpc_2'",
+		"missing parent: @my-key[keypair]: expected a parent of type region",
+		"missing parent: sub_2[subnet]: expected a parent of type vpc",
+		"relation cycle: rec_1[record]: parentOf cycle rec_1 -> rec_2 -> zone_1 -> rec_1",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got\n%v\n\nwant\n%v", got, exp)
+	}
+
+	consistent := graph.NewGraph()
+	consistent.AddResource(resourcetest.Region("eu-west-1").Build(), resourcetest.VPC("vpc_1").Build())
+	resourcetest.AddParents(consistent, "eu-west-1 -> vpc_1")
+	if inconsistencies, err := consistent.CheckConsistency(graph.ConsistencyRules{ExpectedParents: map[string][]string{"vpc": {"region"}}}); err != nil || len(inconsistencies) != 0 {
+		t.Fatalf("got %v (%v), want none", inconsistencies, err)
+	}
+}
@@ -17,6 +17,11 @@ limitations under the License.
 package graph
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
 	"github.com/wallix/awless/cloud/rdf"
 	tstore "github.com/wallix/triplestore"
 )
@@ -36,10 +41,35 @@ type Differ interface {
 }
 
 type Diff struct {
-	fromGraph   *Graph
-	toGraph     *Graph
-	mergedGraph *Graph
-	hasDiffs    bool
+	fromGraph       *Graph
+	toGraph         *Graph
+	mergedGraph     *Graph
+	hasDiffs        bool
+	propertyChanges map[string]*ResourceChanges
+}
+
+// ResourceChanges are the property changes of a resource present in both graphs of a diff
+type ResourceChanges struct {
+	Resource   *Resource
+	Properties []*PropertyChange
+}
+
+// PropertyChange is a property added (nil From), removed (nil To) or modified on a resource.
+// Changes of list properties (FirewallRules, Routes, Grants, ...) are given per element.
+type PropertyChange struct {
+	Key      string
+	From, To interface{}
+}
+
+func (c *PropertyChange) String() string {
+	switch {
+	case c.From == nil:
+		return fmt.Sprintf("%s: + %v", c.Key, c.To)
+	case c.To == nil:
+		return fmt.Sprintf("%s: - %v", c.Key, c.From)
+	default:
+		return fmt.Sprintf("%s: %v -> %v", c.Key, c.From, c.To)
+	}
 }
 
 func NewDiff(fromG, toG *Graph) *Diff {
@@ -56,15 +86,15 @@ func (d *Diff) ToGraph() *Graph {
 
 func (d *Diff) MergedGraph() *Graph {
 	d.mergedGraph = NewGraph()
-	d.mergedGraph.store.Add(d.toGraph.store.Snapshot().Triples()...)
+	d.mergedGraph.addTriples(d.toGraph.store.Snapshot().Triples()...)
 
 	fromTriples := d.fromGraph.store.Snapshot().Triples()
 
 	for _, fromT := range fromTriples {
 		if MetaPredicate == fromT.Predicate() {
-			d.mergedGraph.store.Add(tstore.SubjPred(fromT.Subject(), MetaPredicate).StringLiteral(missingLit))
+			d.mergedGraph.addTriples(tstore.SubjPred(fromT.Subject(), MetaPredicate).StringLiteral(missingLit))
 		} else {
-			d.mergedGraph.store.Add(fromT)
+			d.mergedGraph.addTriples(fromT)
 		}
 	}
 
@@ -75,6 +105,59 @@ func (d *Diff) HasDiff() bool {
 	return d.hasDiffs
 }
 
+// PropertyChanges returns the changes of the modified resources sorted by type and id
+func (d *Diff) PropertyChanges() []*ResourceChanges {
+	var all []*ResourceChanges
+	for _, changes := range d.propertyChanges {
+		all = append(all, changes)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Resource.Type() != all[j].Resource.Type() {
+			return all[i].Resource.Type() < all[j].Resource.Type()
+		}
+		return all[i].Resource.Id() < all[j].Resource.Id()
+	})
+	return all
+}
+
+// ResourcePropertyChanges returns the property changes of a resource given its id
+func (d *Diff) ResourcePropertyChanges(id string) []*PropertyChange {
+	if changes, ok := d.propertyChanges[id]; ok {
+		return changes.Properties
+	}
+	return nil
+}
+
+func (d *Diff) addPropertyChanges(id string, fromSnap, toSnap tstore.RDFGraph) error {
+	rT, err := resolveResourceType(toSnap, id)
+	if err == errTypeNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fromT, err := resolveResourceType(fromSnap, id); err != nil || fromT != rT {
+		return nil
+	}
+
+	fromRes := InitResource(rT, id)
+	if err := fromRes.unmarshalFullRdf(fromSnap); err != nil {
+		return err
+	}
+	toRes := InitResource(rT, id)
+	if err := toRes.unmarshalFullRdf(toSnap); err != nil {
+		return err
+	}
+
+	if changes := diffProperties(fromRes.Properties, toRes.Properties); len(changes) > 0 {
+		if d.propertyChanges == nil {
+			d.propertyChanges = make(map[string]*ResourceChanges)
+		}
+		d.propertyChanges[id] = &ResourceChanges{Resource: toRes, Properties: changes}
+		d.hasDiffs = true
+	}
+	return nil
+}
+
 type hierarchicDiffer struct {
 	predicate string
 }
@@ -93,11 +176,12 @@ func (d *hierarchicDiffer) Run(root string, from *Graph, to *Graph) (*Diff, erro
 	}
 
 	processing <- root
+	commons := []string{root}
 
 	for len(processing) > 0 {
 		select {
 		case current := <-processing:
-			extras, missings, commons, err := compareChildTriplesOf(d.predicate, current, fromSnap, toSnap)
+			extras, missings, common, err := compareChildTriplesOf(d.predicate, current, fromSnap, toSnap)
 			if err != nil {
 				return diff, err
 			}
@@ -106,7 +190,7 @@ func (d *hierarchicDiffer) Run(root string, from *Graph, to *Graph) (*Diff, erro
 				res, ok := extra.Object().Resource()
 				if ok {
 					diff.hasDiffs = true
-					diff.toGraph.store.Add(tstore.SubjPred(res, MetaPredicate).StringLiteral(extraLit))
+					diff.toGraph.addTriples(tstore.SubjPred(res, MetaPredicate).StringLiteral(extraLit))
 					processing <- res
 				}
 			}
@@ -115,20 +199,27 @@ func (d *hierarchicDiffer) Run(root string, from *Graph, to *Graph) (*Diff, erro
 				res, ok := missing.Object().Resource()
 				if ok {
 					diff.hasDiffs = true
-					diff.fromGraph.store.Add(tstore.SubjPred(res, MetaPredicate).StringLiteral(extraLit))
+					diff.fromGraph.addTriples(tstore.SubjPred(res, MetaPredicate).StringLiteral(extraLit))
 					processing <- res
 				}
 			}
 
-			for _, nextNodeToProcess := range commons {
+			for _, nextNodeToProcess := range common {
 				res, ok := nextNodeToProcess.Object().Resource()
 				if ok {
+					commons = append(commons, res)
 					processing <- res
 				}
 			}
 		}
 	}
 
+	for _, id := range commons {
+		if err := diff.addPropertyChanges(id, fromSnap, toSnap); err != nil {
+			return diff, err
+		}
+	}
+
 	return diff, nil
 }
 
@@ -177,6 +268,98 @@ func subtractTriples(a, b []tstore.Triple) []tstore.Triple {
 	return sub
 }
 
+// DiffResourceProperties returns the property changes from a resource to another (ex: the same resource
+// at different sync revisions), given per element for list properties
+func DiffResourceProperties(from, to *Resource) []*PropertyChange {
+	return diffProperties(from.Properties, to.Properties)
+}
+
+// diffProperties compares properties by key, and by element for list properties
+func diffProperties(from, to map[string]interface{}) []*PropertyChange {
+	var keys []string
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []*PropertyChange
+	for _, k := range keys {
+		fromV, inFrom := from[k]
+		toV, inTo := to[k]
+		if isListProperty(fromV) || isListProperty(toV) {
+			changes = append(changes, diffListProperty(k, fromV, toV)...)
+			continue
+		}
+		switch {
+		case !inFrom:
+			changes = append(changes, &PropertyChange{Key: k, To: toV})
+		case !inTo:
+			changes = append(changes, &PropertyChange{Key: k, From: fromV})
+		case !equalPropertyValues(fromV, toV):
+			changes = append(changes, &PropertyChange{Key: k, From: fromV, To: toV})
+		}
+	}
+	return changes
+}
+
+func diffListProperty(key string, from, to interface{}) []*PropertyChange {
+	fromElems, toElems := listPropertyElements(from), listPropertyElements(to)
+
+	var removed, added []string
+	for s := range fromElems {
+		if _, ok := toElems[s]; !ok {
+			removed = append(removed, s)
+		}
+	}
+	for s := range toElems {
+		if _, ok := fromElems[s]; !ok {
+			added = append(added, s)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	var changes []*PropertyChange
+	for _, s := range removed {
+		changes = append(changes, &PropertyChange{Key: key, From: fromElems[s]})
+	}
+	for _, s := range added {
+		changes = append(changes, &PropertyChange{Key: key, To: toElems[s]})
+	}
+	return changes
+}
+
+func isListProperty(i interface{}) bool {
+	return i != nil && reflect.TypeOf(i).Kind() == reflect.Slice
+}
+
+func listPropertyElements(i interface{}) map[string]interface{} {
+	elems := make(map[string]interface{})
+	if !isListProperty(i) {
+		return elems
+	}
+	v := reflect.ValueOf(i)
+	for j := 0; j < v.Len(); j++ {
+		elem := v.Index(j).Interface()
+		elems[fmt.Sprint(elem)] = elem
+	}
+	return elems
+}
+
+func equalPropertyValues(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Equal(bt)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
 func max(a, b uint32) uint32 {
 	if a < b {
 		return b
@@ -0,0 +1,110 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestPropertiesDiff(t *testing.T) {
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	_, private, _ := net.ParseCIDR("10.0.0.0/16")
+	ssh := &graph.FirewallRule{PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, Protocol: "tcp", IPRanges: []*net.IPNet{private}}
+	web := &graph.FirewallRule{PortRange: graph.PortRange{FromPort: 80, ToPort: 80}, Protocol: "tcp", IPRanges: []*net.IPNet{anywhere}}
+	local := &graph.Route{Destination: private, Targets: []*graph.RouteTarget{{Type: graph.GatewayTarget, Ref: "local"}}}
+	internet := &graph.Route{Destination: anywhere, Targets: []*graph.RouteTarget{{Type: graph.GatewayTarget, Ref: "igw_1"}}}
+	read := &graph.Grant{Permission: "READ", Grantee: graph.Grantee{GranteeID: "user_1", GranteeType: "CanonicalUser"}}
+	write := &graph.Grant{Permission: "WRITE", Grantee: graph.Grantee{GranteeID: "user_1", GranteeType: "CanonicalUser"}}
+
+	from := graph.NewGraph()
+	from.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.Instance("inst_1").Prop(properties.Name, "redis").Prop(properties.Type, "t2.micro").Prop(properties.KeyPair, "my-key").Build(),
+		resourcetest.Instance("inst_2").Prop(properties.Name, "unchanged").Build(),
+		resourcetest.SecurityGroup("sg_1").Prop(properties.InboundRules, []*graph.FirewallRule{ssh}).Build(),
+		resourcetest.RouteTable("rt_1").Prop(properties.Routes, []*graph.Route{local}).Build(),
+		resourcetest.Bucket("bucket_1").Prop(properties.Grants, []*graph.Grant{read, write}).Build(),
+	)
+	resourcetest.AddParents(from, "eu-west-1 -> inst_1", "eu-west-1 -> inst_2", "eu-west-1 -> sg_1", "eu-west-1 -> rt_1", "eu-west-1 -> bucket_1")
+
+	to := graph.NewGraph()
+	to.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.Instance("inst_1").Prop(properties.Name, "redis").Prop(properties.Type, "t2.large").Prop(properties.PublicIP, "1.2.3.4").Build(),
+		resourcetest.Instance("inst_2").Prop(properties.Name, "unchanged").Build(),
+		resourcetest.SecurityGroup("sg_1").Prop(properties.InboundRules, []*graph.FirewallRule{web, ssh}).Build(),
+		resourcetest.RouteTable("rt_1").Prop(properties.Routes, []*graph.Route{local, internet}).Build(),
+		resourcetest.Bucket("bucket_1").Prop(properties.Grants, []*graph.Grant{read}).Build(),
+	)
+	resourcetest.AddParents(to, "eu-west-1 -> inst_1", "eu-west-1 -> inst_2", "eu-west-1 -> sg_1", "eu-west-1 -> rt_1", "eu-west-1 -> bucket_1")
+
+	diff, err := graph.DefaultDiffer.Run("eu-west-1", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.HasDiff() {
+		t.Fatal("expected diff on properties only")
+	}
+
+	var ids []string
+	for _, changes := range diff.PropertyChanges() {
+		ids = append(ids, changes.Resource.Id())
+	}
+	if got, want := ids, []string{"bucket_1", "inst_1", "rt_1", "sg_1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	tcases := []struct {
+		id  string
+		exp []*graph.PropertyChange
+	}{
+		{id: "inst_1", exp: []*graph.PropertyChange{
+			{Key: properties.KeyPair, From: "my-key"},
+			{Key: properties.PublicIP, To: "1.2.3.4"},
+			{Key: properties.Type, From: "t2.micro", To: "t2.large"},
+		}},
+		{id: "sg_1", exp: []*graph.PropertyChange{{Key: properties.InboundRules, To: web}}},
+		{id: "rt_1", exp: []*graph.PropertyChange{{Key: properties.Routes, To: internet}}},
+		{id: "bucket_1", exp: []*graph.PropertyChange{{Key: properties.Grants, From: write}}},
+		{id: "inst_2"},
+	}
+	for _, tcase := range tcases {
+		changes := diff.ResourcePropertyChanges(tcase.id)
+		if got, want := len(changes), len(tcase.exp); got != want {
+			t.Fatalf("%s: got %d changes (%v), want %d", tcase.id, got, changes, want)
+		}
+		for i := range changes {
+			if got, want := changes[i].String(), tcase.exp[i].String(); got != want {
+				t.Fatalf("%s: got %s, want %s", tcase.id, got, want)
+			}
+		}
+	}
+
+	noDiff, err := graph.DefaultDiffer.Run("eu-west-1", to, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noDiff.HasDiff() {
+		t.Fatalf("expected no diff, got %v", noDiff.PropertyChanges())
+	}
+}
@@ -0,0 +1,527 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wallix/awless/cloud/rdf"
+	tstore "github.com/wallix/triplestore"
+)
+
+// ExportFormats are the formats supported by Graph.Export
+var ExportFormats = []string{"json", "turtle", "jsonld", "graphml", "cypher"}
+
+// ExportBaseIRI is the base IRI of the resources and namespaces in RDF exports (Turtle, JSON-LD)
+const ExportBaseIRI = "http://awless.io/ns/"
+
+var exportPrefixes = map[string]string{
+	rdf.RdfNS:      "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	rdf.RdfsNS:     "http://www.w3.org/2000/01/rdf-schema#",
+	rdf.XsdNS:      "http://www.w3.org/2001/XMLSchema#",
+	rdf.CloudNS:    ExportBaseIRI + "cloud#",
+	rdf.CloudRelNS: ExportBaseIRI + "cloud-rel#",
+	rdf.CloudOwlNS: ExportBaseIRI + "cloud-owl#",
+	rdf.NetNS:      ExportBaseIRI + "net#",
+	rdf.NetowlNS:   ExportBaseIRI + "net-owl#",
+}
+
+type exportRelation struct {
+	from, to  *Resource
+	predicate string
+}
+
+// Export encodes the resources of the given types and the relations between them:
+//   - json: nested inventory of resources following their parents
+//   - turtle, jsonld: RDF triples
+//   - graphml: resources as nodes and relations as edges
+//   - cypher: Neo4j statements merging resources as nodes and relations
+func (g *Graph) Export(w io.Writer, format string, types ...string) error {
+	sub, resources, relations, err := g.exportSubGraph(types...)
+	if err != nil {
+		return err
+	}
+
+	buff := bufio.NewWriter(w)
+	switch format {
+	case "json":
+		err = exportJSON(buff, resources, relations)
+	case "turtle":
+		err = exportTurtle(buff, sub.exportTriples())
+	case "jsonld":
+		err = exportJSONLD(buff, sub.exportTriples())
+	case "graphml":
+		err = exportGraphML(buff, resources, relations)
+	case "cypher":
+		err = exportCypher(buff, resources, relations)
+	default:
+		return fmt.Errorf("export: unknown format '%s', expecting any of %s", format, strings.Join(ExportFormats, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	return buff.Flush()
+}
+
+func (g *Graph) exportSubGraph(types ...string) (*Graph, []*Resource, []*exportRelation, error) {
+	sub := NewGraph()
+	resources, err := g.GetAllResources(types...)
+	if err != nil {
+		return sub, nil, nil, err
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Type() != resources[j].Type() {
+			return resources[i].Type() < resources[j].Type()
+		}
+		return resources[i].Id() < resources[j].Id()
+	})
+	if err = sub.AddResource(resources...); err != nil {
+		return sub, nil, nil, err
+	}
+
+	byId := make(map[string]*Resource)
+	for _, r := range resources {
+		byId[r.Id()] = r
+	}
+
+	var relations []*exportRelation
+	snap := g.store.Snapshot()
+	for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+		for _, t := range snap.WithPredicate(pred) {
+			id, ok := t.Object().Resource()
+			if !ok {
+				continue
+			}
+			from, fromOk := byId[t.Subject()]
+			to, toOk := byId[id]
+			if fromOk && toOk {
+				relations = append(relations, &exportRelation{from: from, to: to, predicate: pred})
+				sub.addRelation(from, to, pred)
+			}
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		a, b := relations[i], relations[j]
+		if a.predicate != b.predicate {
+			return a.predicate > b.predicate
+		}
+		if a.from.Id() != b.from.Id() {
+			return a.from.Id() < b.from.Id()
+		}
+		return a.to.Id() < b.to.Id()
+	})
+
+	return sub, resources, relations, nil
+}
+
+func (g *Graph) exportTriples() []tstore.Triple {
+	triples := g.store.Snapshot().Triples()
+	sort.Slice(triples, func(i, j int) bool {
+		a, b := triples[i], triples[j]
+		if a.Subject() != b.Subject() {
+			return a.Subject() < b.Subject()
+		}
+		if a.Predicate() != b.Predicate() {
+			return a.Predicate() == rdf.RdfType || (b.Predicate() != rdf.RdfType && a.Predicate() < b.Predicate())
+		}
+		return exportObjectKey(a.Object()) < exportObjectKey(b.Object())
+	})
+	return triples
+}
+
+func exportObjectKey(o tstore.Object) string {
+	if id, ok := o.Resource(); ok {
+		return id
+	}
+	lit, _ := o.Literal()
+	return lit.Value()
+}
+
+type jsonExportResource struct {
+	Id         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	AppliesOn  []string               `json:"appliesOn,omitempty"`
+	Children   []*jsonExportResource  `json:"children,omitempty"`
+}
+
+func exportJSON(w io.Writer, resources []*Resource, relations []*exportRelation) error {
+	nodes := make(map[string]*jsonExportResource)
+	for _, r := range resources {
+		props := make(map[string]interface{})
+		for k, v := range r.Properties {
+			props[k] = exportValue(v)
+		}
+		nodes[r.Id()] = &jsonExportResource{Id: r.Id(), Type: r.Type(), Properties: props}
+	}
+
+	hasParent := make(map[string]bool)
+	for _, rel := range relations {
+		from, to := nodes[rel.from.Id()], nodes[rel.to.Id()]
+		switch rel.predicate {
+		case rdf.ParentOf:
+			if !hasParent[to.Id] && from != to {
+				hasParent[to.Id] = true
+				from.Children = append(from.Children, to)
+			}
+		case rdf.ApplyOn:
+			from.AppliesOn = append(from.AppliesOn, to.Id)
+		}
+	}
+
+	roots := []*jsonExportResource{}
+	for _, r := range resources {
+		if !hasParent[r.Id()] {
+			roots = append(roots, nodes[r.Id()])
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(roots)
+}
+
+// exportValue converts property values to their string representation except for basic values
+func exportValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case time.Time, string, bool, int, int64, float64, nil:
+		return vv
+	case fmt.Stringer:
+		return vv.String()
+	}
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Slice:
+		list := make([]interface{}, val.Len())
+		for i := range list {
+			list[i] = exportValue(val.Index(i).Interface())
+		}
+		return list
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exportIRIRef returns the prefixed name of id when it has a known prefix,
+// otherwise the id percent-encoded as an IRI reference relative to the base IRI.
+// Turtle and JSON-LD share it so a node gets the same IRI in both formats.
+func exportIRIRef(id string) (string, bool) {
+	if splits := strings.SplitN(id, ":", 2); len(splits) == 2 {
+		if _, ok := exportPrefixes[splits[0]]; ok {
+			return id, true
+		}
+	}
+	return strings.Replace(url.QueryEscape(id), "+", "%20", -1), false
+}
+
+func exportIRI(id string) string {
+	ref, prefixed := exportIRIRef(id)
+	if prefixed {
+		return ref
+	}
+	return "<" + ref + ">"
+}
+
+// quoteLiteral double quotes s using only the escapes shared by Turtle
+// (ECHAR and UCHAR) and Cypher string literals: Go specific escapes
+// such as \a or \x07 are rejected by both.
+func quoteLiteral(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func exportPrefixNames() []string {
+	var names []string
+	for k := range exportPrefixes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func exportTurtle(w io.Writer, triples []tstore.Triple) error {
+	fmt.Fprintf(w, "@base <%sresources/> .\n", ExportBaseIRI)
+	for _, name := range exportPrefixNames() {
+		fmt.Fprintf(w, "@prefix %s: <%s> .\n", name, exportPrefixes[name])
+	}
+
+	var subject, predicate string
+	for _, t := range triples {
+		switch {
+		case t.Subject() != subject:
+			if subject != "" {
+				fmt.Fprint(w, " .\n")
+			}
+			subject, predicate = t.Subject(), t.Predicate()
+			fmt.Fprintf(w, "\n%s %s ", exportIRI(subject), exportTurtlePredicate(predicate))
+		case t.Predicate() != predicate:
+			predicate = t.Predicate()
+			fmt.Fprintf(w, " ;\n\t%s ", exportTurtlePredicate(predicate))
+		default:
+			fmt.Fprint(w, ", ")
+		}
+
+		if id, ok := t.Object().Resource(); ok {
+			fmt.Fprint(w, exportIRI(id))
+		} else if lit, ok := t.Object().Literal(); ok {
+			fmt.Fprint(w, quoteLiteral(lit.Value()))
+			if lit.Type() != tstore.XsdString {
+				fmt.Fprintf(w, "^^%s", lit.Type())
+			}
+		}
+	}
+	if subject != "" {
+		fmt.Fprint(w, " .\n")
+	}
+	return nil
+}
+
+func exportTurtlePredicate(pred string) string {
+	if pred == rdf.RdfType {
+		return "a"
+	}
+	return exportIRI(pred)
+}
+
+func exportJSONLD(w io.Writer, triples []tstore.Triple) error {
+	context := map[string]interface{}{"@base": ExportBaseIRI + "resources/"}
+	for k, v := range exportPrefixes {
+		context[k] = v
+	}
+
+	var nodes []map[string]interface{}
+	var current map[string]interface{}
+	for _, t := range triples {
+		subject, _ := exportIRIRef(t.Subject())
+		if current == nil || current["@id"] != subject {
+			current = map[string]interface{}{"@id": subject}
+			nodes = append(nodes, current)
+		}
+
+		key := t.Predicate()
+		var value interface{}
+		if id, ok := t.Object().Resource(); ok {
+			ref, _ := exportIRIRef(id)
+			if key == rdf.RdfType {
+				key, value = "@type", ref
+			} else {
+				value = map[string]string{"@id": ref}
+			}
+		} else if lit, ok := t.Object().Literal(); ok {
+			if lit.Type() == tstore.XsdString {
+				value = lit.Value()
+			} else {
+				value = map[string]string{"@value": lit.Value(), "@type": string(lit.Type())}
+			}
+		}
+
+		switch existing := current[key].(type) {
+		case nil:
+			current[key] = value
+		case []interface{}:
+			current[key] = append(existing, value)
+		default:
+			current[key] = []interface{}{existing, value}
+		}
+	}
+	if nodes == nil {
+		nodes = []map[string]interface{}{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{"@context": context, "@graph": nodes})
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLKey struct {
+	Id       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func exportGraphML(w io.Writer, resources []*Resource, relations []*exportRelation) error {
+	doc := graphMLDocument{Xmlns: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Graph.EdgeDefault = "directed"
+
+	propKeys := make(map[string]interface{})
+	for _, r := range resources {
+		node := graphMLNode{Id: r.Id(), Data: []graphMLData{{Key: "type", Value: r.Type()}}}
+		for _, k := range sortedPropertyKeys(r.Properties) {
+			propKeys[k] = nil
+			node.Data = append(node.Data, graphMLData{Key: k, Value: exportString(r.Properties[k])})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, rel := range relations {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: rel.from.Id(), Target: rel.to.Id(),
+			Data: []graphMLData{{Key: "relation", Value: trimNS(rel.predicate)}},
+		})
+	}
+
+	doc.Keys = append(doc.Keys, graphMLKey{Id: "type", For: "node", AttrName: "type", AttrType: "string"})
+	for _, k := range sortedPropertyKeys(propKeys) {
+		doc.Keys = append(doc.Keys, graphMLKey{Id: k, For: "node", AttrName: k, AttrType: "string"})
+	}
+	doc.Keys = append(doc.Keys, graphMLKey{Id: "relation", For: "edge", AttrName: "relation", AttrType: "string"})
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func exportCypher(w io.Writer, resources []*Resource, relations []*exportRelation) error {
+	for _, r := range resources {
+		var props []string
+		for _, k := range sortedPropertyKeys(r.Properties) {
+			if k == "ID" {
+				continue
+			}
+			props = append(props, fmt.Sprintf("`%s`: %s", k, cypherValue(r.Properties[k])))
+		}
+		fmt.Fprintf(w, "MERGE (n:`%s` {id: %s}) SET n += {%s};\n", strings.Title(r.Type()), cypherValue(r.Id()), strings.Join(props, ", "))
+	}
+	for _, rel := range relations {
+		fmt.Fprintf(w, "MATCH (a:`%s` {id: %s}), (b:`%s` {id: %s}) MERGE (a)-[:%s]->(b);\n",
+			strings.Title(rel.from.Type()), cypherValue(rel.from.Id()), strings.Title(rel.to.Type()), cypherValue(rel.to.Id()), cypherRelation(rel.predicate))
+	}
+	return nil
+}
+
+func cypherRelation(pred string) string {
+	switch pred {
+	case rdf.ParentOf:
+		return "PARENT_OF"
+	case rdf.ApplyOn:
+		return "APPLIES_ON"
+	default:
+		return strings.ToUpper(trimNS(pred))
+	}
+}
+
+func cypherValue(v interface{}) string {
+	switch vv := exportValue(v).(type) {
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(vv)
+	case time.Time:
+		return quoteLiteral(vv.Format(time.RFC3339))
+	case []interface{}:
+		var elems []string
+		for _, e := range vv {
+			elems = append(elems, cypherValue(e))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	default:
+		return quoteLiteral(fmt.Sprint(vv))
+	}
+}
+
+func exportString(v interface{}) string {
+	switch vv := exportValue(v).(type) {
+	case time.Time:
+		return vv.Format(time.RFC3339)
+	case []interface{}:
+		var elems []string
+		for _, e := range vv {
+			elems = append(elems, exportString(e))
+		}
+		return strings.Join(elems, ", ")
+	default:
+		return fmt.Sprint(vv)
+	}
+}
+
+func sortedPropertyKeys(m map[string]interface{}) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
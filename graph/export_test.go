@@ -0,0 +1,225 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestExport(t *testing.T) {
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.VPC("vpc_1").Prop(properties.Name, "main").Build(),
+		resourcetest.Subnet("sub_1").Prop(properties.Default, true).Build(),
+		resourcetest.Instance("inst_1").Prop(properties.Name, `my "redis"`).Prop(properties.Launched, time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)).Build(),
+		resourcetest.SecurityGroup("sg_1").Prop(properties.InboundRules, []*graph.FirewallRule{
+			{PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, Protocol: "tcp", IPRanges: []*net.IPNet{anywhere}},
+		}).Build(),
+	)
+	resourcetest.AddParents(g, "eu-west-1 -> vpc_1", "vpc_1 -> sub_1", "sub_1 -> inst_1", "vpc_1 -> sg_1")
+	g.AddAppliesOnRelation(graph.InitResource("securitygroup", "sg_1"), graph.InitResource("instance", "inst_1"))
+
+	export := func(format string, types ...string) string {
+		var buff bytes.Buffer
+		if err := g.Export(&buff, format, types...); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		return buff.String()
+	}
+	all := []string{"region", "vpc", "subnet", "instance", "securitygroup"}
+
+	t.Run("json", func(t *testing.T) {
+		var inventory []struct {
+			Id       string
+			Children []struct {
+				Id       string
+				Children []struct {
+					Id         string
+					Type       string
+					AppliesOn  []string
+					Properties map[string]interface{}
+				}
+			}
+		}
+		if err := json.Unmarshal([]byte(export("json", all...)), &inventory); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(inventory), 1; got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+		vpc := inventory[0].Children[0]
+		if got, want := vpc.Id, "vpc_1"; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+		if got, want := vpc.Children[0].AppliesOn, []string{"inst_1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		if got, want := vpc.Children[0].Properties["InboundRules"], []interface{}{"PortRange:22:22; Protocol:tcp; IPRanges:[0.0.0.0/0]"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v, want %#v", got, want)
+		}
+
+		var filtered []map[string]interface{}
+		if err := json.Unmarshal([]byte(export("json", "instance", "securitygroup")), &filtered); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(filtered), 2; got != want {
+			t.Fatalf("got %d, want %d: %v", got, want, filtered)
+		}
+	})
+
+	t.Run("turtle", func(t *testing.T) {
+		out := export("turtle", "instance")
+		for _, exp := range []string{
+			"@prefix cloud: <http://awless.io/ns/cloud#> .",
+			"<inst_1> a cloud-owl:Instance ;",
+			`cloud:name "my \"redis\""`,
+			`cloud:launched "2017-06-01T00:00:00Z"^^xsd:dateTime`,
+		} {
+			if !strings.Contains(out, exp) {
+				t.Fatalf("expected %q in\n%s", exp, out)
+			}
+		}
+		if strings.Contains(out, "sub_1") {
+			t.Fatalf("unexpected relation to filtered out resource in\n%s", out)
+		}
+	})
+
+	t.Run("jsonld", func(t *testing.T) {
+		var doc struct {
+			Context map[string]interface{}   `json:"@context"`
+			Graph   []map[string]interface{} `json:"@graph"`
+		}
+		if err := json.Unmarshal([]byte(export("jsonld", "subnet", "instance")), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc.Context["cloud-rel"], "http://awless.io/ns/cloud-rel#"; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		var sub map[string]interface{}
+		for _, n := range doc.Graph {
+			if n["@id"] == "sub_1" {
+				sub = n
+			}
+		}
+		if got, want := sub["@type"], "cloud-owl:Subnet"; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		if got, want := sub["cloud-rel:parentOf"], map[string]interface{}{"@id": "inst_1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		if got, want := sub["cloud:default"], map[string]interface{}{"@value": "true", "@type": "xsd:boolean"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var doc struct {
+			Nodes []struct {
+				Id string `xml:"id,attr"`
+			} `xml:"graph>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Data   string `xml:"data"`
+			} `xml:"graph>edge"`
+		}
+		if err := xml.Unmarshal([]byte(export("graphml", all...)), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(doc.Nodes), 5; got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+		if got, want := len(doc.Edges), 5; got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+		last := doc.Edges[len(doc.Edges)-1]
+		if got, want := last.Source+" "+last.Data+" "+last.Target, "sg_1 applyOn inst_1"; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("cypher", func(t *testing.T) {
+		out := export("cypher", "instance", "securitygroup")
+		expected := "MERGE (n:`Instance` {id: \"inst_1\"}) SET n += {`Launched`: \"2017-06-01T00:00:00Z\", `Name`: \"my \\\"redis\\\"\"};\n" +
+			"MERGE (n:`Securitygroup` {id: \"sg_1\"}) SET n += {`InboundRules`: [\"PortRange:22:22; Protocol:tcp; IPRanges:[0.0.0.0/0]\"]};\n" +
+			"MATCH (a:`Securitygroup` {id: \"sg_1\"}), (b:`Instance` {id: \"inst_1\"}) MERGE (a)-[:APPLIES_ON]->(b);\n"
+		if got, want := out, expected; got != want {
+			t.Fatalf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	if err := g.Export(&bytes.Buffer{}, "yaml"); err == nil {
+		t.Fatal("expected error on unknown format")
+	}
+}
+
+func TestExportEscaping(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(resourcetest.Instance("my inst/1").Prop(properties.Name, "bell\a tab\t é").Build())
+
+	export := func(format string) string {
+		var buff bytes.Buffer
+		if err := g.Export(&buff, format, "instance"); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		return buff.String()
+	}
+
+	t.Run("turtle", func(t *testing.T) {
+		out := export("turtle")
+		for _, exp := range []string{
+			"<my%20inst%2F1> a cloud-owl:Instance ;",
+			`cloud:name "bell\u0007 tab\t é"`,
+		} {
+			if !strings.Contains(out, exp) {
+				t.Fatalf("expected %q in\n%s", exp, out)
+			}
+		}
+	})
+
+	t.Run("jsonld", func(t *testing.T) {
+		var doc struct {
+			Graph []map[string]interface{} `json:"@graph"`
+		}
+		if err := json.Unmarshal([]byte(export("jsonld")), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc.Graph[0]["@id"], "my%20inst%2F1"; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("cypher", func(t *testing.T) {
+		out := export("cypher")
+		if exp := `{` + "`Name`" + `: "bell\u0007 tab\t é"}`; !strings.Contains(out, exp) {
+			t.Fatalf("expected %q in\n%s", exp, out)
+		}
+	})
+}
@@ -2,7 +2,12 @@ package graph
 
 import (
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type FilterFn func(*Resource) bool
@@ -32,12 +37,228 @@ func (g *Graph) filter(apply func(filters ...FilterFn) FilterFn, entity string,
 	return filtered, nil
 }
 
+// BuildResourcesFilterFunc builds a filter keeping only the given resources
+func BuildResourcesFilterFunc(resources ...*Resource) FilterFn {
+	return func(r *Resource) bool {
+		for _, other := range resources {
+			if r.Same(other) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func BuildPropertyFilterFunc(key, val string) FilterFn {
 	return func(r *Resource) bool {
 		return strings.Contains(strings.ToLower(fmt.Sprint(r.Properties[key])), strings.ToLower(val))
 	}
 }
 
+// BuildPropertyCompareFilterFunc builds a filter comparing a property with the given operator:
+// '=' (contains), '!=' (does not contain), '=~' (regex), '>', '>=', '<', '<=' and 'in'
+// (list of values separated by '|', or CIDR containment). Comparisons are case insensitive
+// and apply to any element of list properties. Numbers compare numerically and times
+// compare with dates, a duration as 30d or 12h standing for the date that long ago:
+// '>' then always means more recent (ex: launched>30d, launched less than 30 days ago).
+func BuildPropertyCompareFilterFunc(key, op, val string) (FilterFn, error) {
+	switch op {
+	case "=":
+		return BuildPropertyFilterFunc(key, val), nil
+	case "!=":
+		return BuildNotFilterFunc(BuildPropertyFilterFunc(key, val)), nil
+	case "=~":
+		reg, err := regexp.Compile("(?i)" + val)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: invalid regex: %s", key, err)
+		}
+		return buildAnyPropertyFilterFunc(key, func(i interface{}) bool {
+			return reg.MatchString(fmt.Sprint(i))
+		}), nil
+	case "in":
+		if _, cidr, err := net.ParseCIDR(val); err == nil {
+			return buildAnyPropertyFilterFunc(key, func(i interface{}) bool {
+				return cidrContains(cidr, fmt.Sprint(i))
+			}), nil
+		}
+		values := strings.Split(val, "|")
+		return buildAnyPropertyFilterFunc(key, func(i interface{}) bool {
+			for _, v := range values {
+				if strings.EqualFold(fmt.Sprint(i), strings.TrimSpace(v)) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case ">", ">=", "<", "<=":
+		compare, err := buildOrderedCompare(val)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %s", key, err)
+		}
+		return buildAnyPropertyFilterFunc(key, func(i interface{}) bool {
+			cmp, ok := compare(i)
+			if !ok {
+				return false
+			}
+			switch op {
+			case ">":
+				return cmp > 0
+			case ">=":
+				return cmp >= 0
+			case "<":
+				return cmp < 0
+			default:
+				return cmp <= 0
+			}
+		}), nil
+	default:
+		return nil, fmt.Errorf("filter %s: unknown operator '%s'", key, op)
+	}
+}
+
+func BuildPropertyExistsFilterFunc(key string) FilterFn {
+	return func(r *Resource) bool {
+		_, ok := r.Properties[key]
+		return ok
+	}
+}
+
+func BuildNotFilterFunc(filter FilterFn) FilterFn {
+	return func(r *Resource) bool {
+		return !filter(r)
+	}
+}
+
+func BuildOrFilterFunc(filters ...FilterFn) FilterFn {
+	return applyOr(filters...)
+}
+
+func buildAnyPropertyFilterFunc(key string, fn func(interface{}) bool) FilterFn {
+	return func(r *Resource) bool {
+		prop, ok := r.Properties[key]
+		if !ok {
+			return false
+		}
+		val := reflect.ValueOf(prop)
+		if val.Kind() == reflect.Slice {
+			for i := 0; i < val.Len(); i++ {
+				if fn(val.Index(i).Interface()) {
+					return true
+				}
+			}
+			return false
+		}
+		return fn(prop)
+	}
+}
+
+// buildOrderedCompare returns a func comparing a property value with the given value:
+// -1 if the property is lower, 0 if equal and 1 if greater
+func buildOrderedCompare(val string) (func(interface{}) (int, bool), error) {
+	if compareTime, err := buildTimeCompare(val, time.Now().UTC()); err == nil {
+		return func(i interface{}) (int, bool) {
+			t, ok := i.(time.Time)
+			if !ok {
+				return 0, false
+			}
+			return compareTime(t), true
+		}, nil
+	}
+	if number, err := strconv.ParseFloat(val, 64); err == nil {
+		return func(i interface{}) (int, bool) {
+			f, err := strconv.ParseFloat(fmt.Sprint(i), 64)
+			if err != nil {
+				return 0, false
+			}
+			return compareFloats(f, number), true
+		}, nil
+	}
+	return func(i interface{}) (int, bool) {
+		return strings.Compare(strings.ToLower(fmt.Sprint(i)), strings.ToLower(val)), true
+	}, nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// buildTimeCompare returns a func comparing a time with the given date (-1 if before, 0 if equal
+// and 1 if after) or with the given age (-1 if younger, 0 if as old and 1 if older: 'launched>30d'
+// means launched more than 30 days ago)
+func buildTimeCompare(val string, now time.Time) (func(time.Time) int, error) {
+	if age, err := ParseDuration(val); err == nil {
+		return func(t time.Time) int {
+			return compareDurations(now.Sub(t), age)
+		}, nil
+	}
+	date, err := ParseTime(val, now)
+	if err != nil {
+		return nil, err
+	}
+	return func(t time.Time) int {
+		return compareDurations(t.Sub(date), 0)
+	}, nil
+}
+
+func compareDurations(a, b time.Duration) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ParseTime parses a date in the location of now (RFC3339, 2006-01-02T15:04, 2006-01-02 15:04 or 2006-01-02),
+// or else an age (see ParseDuration) as the time that long before now
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if age, err := ParseDuration(s); err == nil {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date or duration '%s'", s)
+}
+
+// ParseDuration parses durations as time.ParseDuration with additional days (d) and weeks (w) units
+func ParseDuration(s string) (time.Duration, error) {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return 0, fmt.Errorf("missing unit in duration '%s'", s)
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, suffix), 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+func cidrContains(cidr *net.IPNet, s string) bool {
+	if ip := net.ParseIP(s); ip != nil {
+		return cidr.Contains(ip)
+	}
+	if _, sub, err := net.ParseCIDR(s); err == nil {
+		cidrOnes, _ := cidr.Mask.Size()
+		subOnes, _ := sub.Mask.Size()
+		return cidrOnes <= subOnes && cidr.Contains(sub.IP)
+	}
+	return false
+}
+
 func BuildTagFilterFunc(key, val string) FilterFn {
 	return func(r *Resource) bool {
 		tags, ok := r.Properties["Tags"].([]string)
@@ -1,7 +1,10 @@
 package graph_test
 
 import (
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/wallix/awless/cloud/properties"
 	"github.com/wallix/awless/graph"
@@ -148,6 +151,65 @@ func TestFilterGraph(t *testing.T) {
 	}
 }
 
+func TestPropertyCompareFilters(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Instance("inst_1").Prop(properties.Name, "redis-prod").Prop(properties.PrivateIP, "10.0.1.12").
+			Prop(properties.Launched, time.Now().Add(-40*24*time.Hour)).Prop(properties.SecurityGroups, []string{"sg-1", "sg-2"}).Build(),
+		resourcetest.Instance("inst_2").Prop(properties.Name, "web-staging").Prop(properties.PrivateIP, "172.16.0.3").
+			Prop(properties.Launched, time.Now().Add(-2*time.Hour)).Build(),
+		resourcetest.Subnet("sub_1").Prop(properties.CIDR, "10.0.1.0/24").Build(),
+		resourcetest.Subnet("sub_2").Prop(properties.CIDR, "10.1.0.0/16").Build(),
+	)
+
+	tcases := []struct {
+		typ, key, op, val string
+		expect            []string
+	}{
+		{typ: "instance", key: properties.Name, op: "=", val: "PROD", expect: []string{"inst_1"}},
+		{typ: "instance", key: properties.Name, op: "!=", val: "prod", expect: []string{"inst_2"}},
+		{typ: "instance", key: properties.Name, op: "=~", val: "^web-.*g$", expect: []string{"inst_2"}},
+		{typ: "instance", key: properties.Name, op: "in", val: "web-staging|redis-prod", expect: []string{"inst_1", "inst_2"}},
+		{typ: "instance", key: properties.SecurityGroups, op: "in", val: "sg-2|sg-3", expect: []string{"inst_1"}},
+		{typ: "instance", key: properties.PrivateIP, op: "in", val: "10.0.0.0/8", expect: []string{"inst_1"}},
+		{typ: "instance", key: properties.Launched, op: ">", val: "30d", expect: []string{"inst_1"}},
+		{typ: "instance", key: properties.Launched, op: "<", val: "30d", expect: []string{"inst_2"}},
+		{typ: "instance", key: properties.Launched, op: "<=", val: "1w", expect: []string{"inst_2"}},
+		{typ: "instance", key: properties.Launched, op: ">", val: time.Now().Add(-24 * time.Hour).Format("2006-01-02"), expect: []string{"inst_2"}},
+		{typ: "subnet", key: properties.CIDR, op: "in", val: "10.0.0.0/16", expect: []string{"sub_1"}},
+	}
+
+	for _, tcase := range tcases {
+		fn, err := graph.BuildPropertyCompareFilterFunc(tcase.key, tcase.op, tcase.val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filtered, _ := g.Filter(tcase.typ, fn)
+		resources, _ := filtered.GetAllResources(tcase.typ)
+		var ids []string
+		for _, r := range resources {
+			ids = append(ids, r.Id())
+		}
+		sort.Strings(ids)
+		if got, want := ids, tcase.expect; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s %s %s: got %v, want %v", tcase.key, tcase.op, tcase.val, got, want)
+		}
+	}
+
+	if _, err := graph.BuildPropertyCompareFilterFunc(properties.Name, "=~", "("); err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+
+	filtered, _ := g.Filter("instance", graph.BuildOrFilterFunc(
+		graph.BuildNotFilterFunc(graph.BuildPropertyExistsFilterFunc(properties.SecurityGroups)),
+		graph.BuildPropertyFilterFunc(properties.Name, "redis"),
+	))
+	instances, _ := filtered.GetAllResources("instance")
+	if got, want := len(instances), 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+}
+
 func hasResource(arr []*graph.Resource, r *graph.Resource) bool {
 	for _, a := range arr {
 		if a.Id() == r.Id() {
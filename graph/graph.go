@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/wallix/awless/cloud/rdf"
 	tstore "github.com/wallix/triplestore"
@@ -28,10 +29,13 @@ import (
 
 type Graph struct {
 	store tstore.Source
+
+	indexMu sync.Mutex
+	index   *resourcesIndex
 }
 
 func NewGraph() *Graph {
-	return &Graph{tstore.NewSource()}
+	return &Graph{store: tstore.NewSource()}
 }
 
 func NewGraphFromFile(filepath string) (*Graph, error) {
@@ -44,7 +48,7 @@ func NewGraphFromFile(filepath string) (*Graph, error) {
 	if err != nil {
 		return g, err
 	}
-	g.store.Add(ts...)
+	g.addTriples(ts...)
 	return g, nil
 }
 
@@ -55,13 +59,126 @@ func (g *Graph) AddResource(resources ...*Resource) error {
 			return err
 		}
 
-		g.store.Add(triples...)
+		g.addTriples(triples...)
 	}
 	return nil
 }
 
 func (g *Graph) AddGraph(other *Graph) {
-	g.store.Add(other.store.Snapshot().Triples()...)
+	g.addTriples(other.store.Snapshot().Triples()...)
+}
+
+// ReplaceResources replaces the resources of the given type, with their properties,
+// by the ones of the other graph. Relations of the resources still in the other graph
+// are kept, the ones of the removed resources are dropped, and the relations of the
+// other graph are added: relations built along with the resources of the type have
+// to be rebuilt then (see RebuildRelations).
+func (g *Graph) ReplaceResources(resourceType string, other *Graph) {
+	cloudType := tstore.Resource(namespacedResourceType(resourceType))
+	snap := g.store.Snapshot()
+	otherSnap := other.store.Snapshot()
+
+	kept := make(map[string]bool)
+	for _, t := range otherSnap.WithPredObj(rdf.RdfType, cloudType) {
+		kept[t.Subject()] = true
+	}
+
+	var removed []tstore.Triple
+	for _, t := range snap.WithPredObj(rdf.RdfType, cloudType) {
+		id := t.Subject()
+		for _, prop := range snap.WithSubject(id) {
+			if isRelation(prop.Predicate()) && kept[id] {
+				continue
+			}
+			removed = append(removed, prop)
+			if obj, ok := prop.Object().Resource(); ok {
+				removed = append(removed, nestedTriples(snap, obj)...)
+			}
+		}
+		if !kept[id] {
+			for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+				removed = append(removed, snap.WithPredObj(pred, tstore.Resource(id))...)
+			}
+		}
+	}
+
+	g.removeTriples(removed...)
+	g.addTriples(otherSnap.Triples()...)
+}
+
+// RebuildRelations replaces the relations of the resources of the given type built along with them
+// (ex: subnets and security groups of instances) by the ones the given func builds, run against a copy
+// of the graph without relations. Only the relations of the same kinds as the built ones (same predicate
+// and types of both ends) are replaced: the others are kept (ex: volumes attached to instances).
+func (g *Graph) RebuildRelations(resourceType string, build func(*Graph) error) error {
+	snap := g.store.Snapshot()
+	scratch := NewGraph()
+	var withoutRelations []tstore.Triple
+	for _, t := range snap.Triples() {
+		if !isRelation(t.Predicate()) {
+			withoutRelations = append(withoutRelations, t)
+		}
+	}
+	scratch.addTriples(withoutRelations...)
+	if err := build(scratch); err != nil {
+		return err
+	}
+
+	typeOf := func(id string) string {
+		rT, _ := resolveResourceType(snap, id)
+		return rT
+	}
+	kind := func(t tstore.Triple) (string, bool) {
+		obj, _ := t.Object().Resource()
+		subjType, objType := typeOf(t.Subject()), typeOf(obj)
+		return fmt.Sprintf("%s %s %s", subjType, t.Predicate(), objType), subjType == resourceType || objType == resourceType
+	}
+
+	var built []tstore.Triple
+	kinds := make(map[string]bool)
+	scratchSnap := scratch.store.Snapshot()
+	for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+		for _, t := range scratchSnap.WithPredicate(pred) {
+			k, _ := kind(t)
+			kinds[k] = true
+			built = append(built, t)
+		}
+	}
+	var removed []tstore.Triple
+	for _, pred := range []string{rdf.ParentOf, rdf.ApplyOn} {
+		for _, t := range snap.WithPredicate(pred) {
+			if k, ofType := kind(t); ofType && kinds[k] {
+				removed = append(removed, t)
+			}
+		}
+	}
+	g.removeTriples(removed...)
+	g.addTriples(built...)
+	return nil
+}
+
+func isRelation(pred string) bool {
+	return pred == rdf.ParentOf || pred == rdf.ApplyOn
+}
+
+// nestedTriples returns the triples of a nested value (ex: firewall rule) and of the values nested in it
+func nestedTriples(snap tstore.RDFGraph, id string) []tstore.Triple {
+	var isNested bool
+	for _, t := range snap.WithSubjPred(id, rdf.RdfType) {
+		if class, ok := t.Object().Resource(); ok && nestedRdfClasses[class] {
+			isNested = true
+		}
+	}
+	if !isNested {
+		return nil
+	}
+	triples := snap.WithSubject(id)
+	for _, t := range snap.WithSubject(id) {
+		if obj, ok := t.Object().Resource(); ok && obj != id {
+			triples = append(triples, nestedTriples(snap, obj)...)
+		}
+	}
+	return triples
 }
 
 func (g *Graph) AddParentRelation(parent, child *Resource) error {
@@ -175,6 +292,45 @@ func (g *Graph) ListResourcesAppliedOn(start *Resource) ([]*Resource, error) {
 	return resources, nil
 }
 
+// ListResourcesIn returns the resources having the given resource as direct or indirect parent
+func (g *Graph) ListResourcesIn(start *Resource) ([]*Resource, error) {
+	var resources []*Resource
+	err := g.Accept(&ChildrenVisitor{From: start, Each: VisitorCollectFunc(&resources)})
+	return resources, err
+}
+
+// ListRelatedResources returns the resources related to the given resource: its parents,
+// its children (direct or indirect) and the resources it applies on or depends on
+func (g *Graph) ListRelatedResources(start *Resource) ([]*Resource, error) {
+	var parents, children []*Resource
+	if err := g.Accept(&ParentsVisitor{From: start, Each: VisitorCollectFunc(&parents)}); err != nil {
+		return nil, err
+	}
+	if err := g.Accept(&ChildrenVisitor{From: start, Each: VisitorCollectFunc(&children)}); err != nil {
+		return nil, err
+	}
+	appliedOn, err := g.ListResourcesAppliedOn(start)
+	if err != nil {
+		return nil, err
+	}
+	dependingOn, err := g.ListResourcesDependingOn(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*Resource
+	seen := make(map[string]bool)
+	for _, list := range [][]*Resource{parents, children, appliedOn, dependingOn} {
+		for _, r := range list {
+			if key := r.Type() + r.Id(); !seen[key] && !r.Same(start) {
+				seen[key] = true
+				resources = append(resources, r)
+			}
+		}
+	}
+	return resources, nil
+}
+
 func (g *Graph) Accept(v Visitor) error {
 	return v.Visit(g)
 }
@@ -184,7 +340,7 @@ func (g *Graph) Unmarshal(data []byte) error {
 	if err != nil {
 		return err
 	}
-	g.store.Add(ts...)
+	g.addTriples(ts...)
 	return nil
 }
 
@@ -194,7 +350,7 @@ func (g *Graph) UnmarshalMultiple(readers ...io.Reader) error {
 	if err != nil {
 		return err
 	}
-	g.store.Add(ts...)
+	g.addTriples(ts...)
 	return nil
 }
 
@@ -213,6 +369,6 @@ func (g *Graph) Marshal() ([]byte, error) {
 }
 
 func (g *Graph) addRelation(one, other *Resource, pred string) error {
-	g.store.Add(tstore.SubjPred(one.Id(), pred).Resource(other.Id()))
+	g.addTriples(tstore.SubjPred(one.Id(), pred).Resource(other.Id()))
 	return nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package graph
 
 import (
+	"reflect"
 	"testing"
 
 	tstore "github.com/wallix/triplestore"
@@ -63,3 +64,64 @@ func TestAddGraphRelation(t *testing.T) {
 		}
 	})
 }
+
+func TestReplaceResources(t *testing.T) {
+	g := NewGraph()
+	sg1 := InitResource("securitygroup", "sg_1")
+	sg1.Properties["Name"] = "old"
+	sg1.Properties["InboundRules"] = []*FirewallRule{{Protocol: "tcp", PortRange: PortRange{FromPort: 22, ToPort: 22}}}
+	sg2 := InitResource("securitygroup", "sg_2")
+	vpc := InitResource("vpc", "vpc_1")
+	inst := InitResource("instance", "inst_1")
+	g.AddResource(sg1, sg2, vpc, inst)
+	g.AddParentRelation(vpc, sg1)
+	g.AddParentRelation(vpc, sg2)
+	g.AddAppliesOnRelation(sg1, inst)
+	g.AddAppliesOnRelation(sg2, inst)
+
+	fetched := NewGraph()
+	newSg1 := InitResource("securitygroup", "sg_1")
+	newSg1.Properties["Name"] = "new"
+	newSg1.Properties["InboundRules"] = []*FirewallRule{{Protocol: "udp", PortRange: PortRange{FromPort: 53, ToPort: 53}}}
+	fetched.AddResource(newSg1, InitResource("securitygroup", "sg_3"))
+
+	g.ReplaceResources("securitygroup", fetched)
+
+	sgs, err := g.GetAllResources("securitygroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, sg := range sgs {
+		ids = append(ids, sg.Id())
+	}
+	if got, want := ids, []string{"sg_1", "sg_3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	res, err := g.GetResource("securitygroup", "sg_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Properties["Name"], "new"; got != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+	rules := res.Properties["InboundRules"].([]*FirewallRule)
+	if got, want := len(rules), 1; got != want {
+		t.Fatalf("got %d rules, want %d", got, want)
+	}
+	if got, want := rules[0].Protocol, "udp"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	snap := g.store.Snapshot()
+	if !snap.Contains(tstore.SubjPred("vpc_1", "cloud-rel:parentOf").Resource("sg_1")) || !snap.Contains(tstore.SubjPred("sg_1", "cloud-rel:applyOn").Resource("inst_1")) {
+		t.Fatal("expected relations of sg_1 to be kept")
+	}
+	if got, want := len(snap.WithSubject("sg_2"))+len(snap.WithObject(tstore.Resource("sg_2"))), 0; got != want {
+		t.Fatalf("got %d triples for sg_2, want %d", got, want)
+	}
+	if got, want := len(snap.WithPredicate("net:protocol")), 1; got != want {
+		t.Fatalf("got %d protocol triples, want %d", got, want)
+	}
+}
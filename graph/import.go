@@ -0,0 +1,587 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wallix/awless/cloud/rdf"
+	tstore "github.com/wallix/triplestore"
+)
+
+// ImportFormats are the formats supported by Graph.Import
+var ImportFormats = []string{"json", "turtle", "ntriples"}
+
+var nestedRdfClasses = map[string]bool{
+	rdf.NetFirewallRule: true, rdf.NetRoute: true, rdf.Grant: true,
+	rdf.CloudGrantee: true, rdf.KeyValue: true, rdf.DistributionOrigin: true,
+}
+
+// Import adds to the graph the resources and relations read from:
+//   - json: inventory as exported in json (resources with type, properties by label, appliesOn and children).
+//     Lists of nested values (FirewallRules, Routes, Grants, ...) are ignored.
+//   - turtle, ntriples: RDF triples using the awless predicates (see Export)
+//
+// Literals are converted to the datatypes of the awless properties. Resources
+// must be of the given types and the graph is left untouched on any error.
+func (g *Graph) Import(r io.Reader, format string, types ...string) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	imported := NewGraph()
+	switch format {
+	case "json":
+		err = imported.importJSON(b)
+	case "turtle", "ntriples":
+		var triples []tstore.Triple
+		if triples, err = parseTurtle(string(b)); err == nil {
+			imported.addTriples(triples...)
+		}
+	default:
+		return fmt.Errorf("import: unknown format '%s', expecting any of %s", format, strings.Join(ImportFormats, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("import: %s", err)
+	}
+
+	if err = imported.validateImport(types); err != nil {
+		return fmt.Errorf("import: %s", err)
+	}
+
+	g.AddGraph(imported)
+	return nil
+}
+
+func (g *Graph) validateImport(types []string) error {
+	allowed := make(map[string]bool)
+	for _, t := range types {
+		allowed[namespacedResourceType(t)] = true
+	}
+	snap := g.store.Snapshot()
+	for _, t := range snap.WithPredicate(rdf.RdfType) {
+		typ, ok := t.Object().Resource()
+		if !ok {
+			return fmt.Errorf("%s: type is not a resource identifier", t.Subject())
+		}
+		if nestedRdfClasses[typ] {
+			continue
+		}
+		if !allowed[typ] {
+			return fmt.Errorf("%s: unknown resource type '%s'", t.Subject(), typ)
+		}
+		res := InitResource(strings.ToLower(trimNS(typ)), t.Subject())
+		if err := res.unmarshalFullRdf(snap); err != nil {
+			return fmt.Errorf("%s: %s", res, err)
+		}
+	}
+	return nil
+}
+
+func (g *Graph) importJSON(b []byte) error {
+	var roots []*jsonExportResource
+	if err := json.Unmarshal(b, &roots); err != nil {
+		return err
+	}
+	var add func(parent *Resource, nodes []*jsonExportResource) error
+	add = func(parent *Resource, nodes []*jsonExportResource) error {
+		for _, node := range nodes {
+			if node.Id == "" || node.Type == "" {
+				return errors.New("resources require an id and a type")
+			}
+			res := InitResource(node.Type, node.Id)
+			for key, value := range node.Properties {
+				label, converted, err := importJSONProperty(key, value)
+				if err != nil {
+					return fmt.Errorf("%s: %s", res, err)
+				}
+				if converted != nil {
+					res.Properties[label] = converted
+				}
+			}
+			if err := g.AddResource(res); err != nil {
+				return err
+			}
+			if parent != nil {
+				g.AddParentRelation(parent, res)
+			}
+			for _, id := range node.AppliesOn {
+				g.AddAppliesOnRelation(res, InitResource("", id))
+			}
+			if err := add(res, node.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return add(nil, roots)
+}
+
+func importJSONProperty(key string, value interface{}) (string, interface{}, error) {
+	propId, err := rdf.Properties.GetRDFId(key)
+	if err != nil {
+		if _, ok := rdf.Properties[key]; !ok {
+			return key, nil, fmt.Errorf("unknown property '%s'", key)
+		}
+		propId = key
+	}
+	label, _ := rdf.Properties.GetLabel(propId)
+	definedBy, _ := rdf.Properties.GetDefinedBy(propId)
+	dataType, _ := rdf.Properties.GetDataType(propId)
+
+	if definedBy == rdf.RdfsList {
+		if dataType != rdf.XsdString && dataType != rdf.RdfsClass {
+			return label, nil, nil
+		}
+		elems, ok := value.([]interface{})
+		if !ok {
+			elems = []interface{}{value}
+		}
+		var list []string
+		for _, e := range elems {
+			list = append(list, fmt.Sprint(e))
+		}
+		return label, list, nil
+	}
+
+	converted, err := convertImportedValue(fmt.Sprint(value), dataType)
+	if err != nil {
+		return label, nil, fmt.Errorf("property '%s': %s", label, err)
+	}
+	return label, converted, nil
+}
+
+func convertImportedValue(value, dataType string) (interface{}, error) {
+	switch dataType {
+	case rdf.XsdInt, string(tstore.XsdInteger), "xsd:long":
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f == float64(int(f)) {
+			return int(f), nil
+		}
+		return nil, fmt.Errorf("invalid integer '%s'", value)
+	case rdf.XsdBoolean:
+		return strconv.ParseBool(value)
+	case rdf.XsdDateTime:
+		return time.Parse(time.RFC3339, value)
+	case string(tstore.XsdDouble), "xsd:decimal":
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+type turtleParser struct {
+	in       []rune
+	pos      int
+	base     string
+	prefixes map[string]string
+	blanks   map[string]string
+	triples  []tstore.Triple
+}
+
+// parseTurtle parses Turtle (and thus NTriples) except collections, mapping the
+// IRIs of the awless namespaces and resources back to their awless identifiers
+func parseTurtle(text string) ([]tstore.Triple, error) {
+	p := &turtleParser{in: []rune(text), prefixes: make(map[string]string), blanks: make(map[string]string)}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.in) {
+			return p.triples, nil
+		}
+		if err := p.statement(); err != nil {
+			return nil, fmt.Errorf("line %d: %s", p.line(), err)
+		}
+	}
+}
+
+func (p *turtleParser) statement() error {
+	word := p.peekWord()
+	switch {
+	case word == "@prefix" || strings.EqualFold(word, "prefix"):
+		p.pos += len([]rune(word))
+		p.skipSpaces()
+		name := p.peekWord()
+		if !strings.HasSuffix(name, ":") {
+			return fmt.Errorf("expected prefix name, got '%s'", name)
+		}
+		p.pos += len([]rune(name))
+		iri, err := p.iriRef()
+		if err != nil {
+			return err
+		}
+		p.prefixes[strings.TrimSuffix(name, ":")] = iri
+		if word == "@prefix" {
+			return p.expect('.')
+		}
+		return nil
+	case word == "@base" || strings.EqualFold(word, "base"):
+		p.pos += len([]rune(word))
+		iri, err := p.iriRef()
+		if err != nil {
+			return err
+		}
+		p.base = iri
+		if word == "@base" {
+			return p.expect('.')
+		}
+		return nil
+	}
+
+	subject, err := p.subject()
+	if err != nil {
+		return err
+	}
+	if err = p.predicateObjectList(subject); err != nil {
+		return err
+	}
+	return p.expect('.')
+}
+
+func (p *turtleParser) subject() (string, error) {
+	p.skipSpaces()
+	if p.peek() == '[' {
+		return p.anonymousNode()
+	}
+	return p.resource()
+}
+
+func (p *turtleParser) predicateObjectList(subject string) error {
+	for {
+		p.skipSpaces()
+		var pred string
+		if word := p.peekWord(); word == "a" {
+			p.pos++
+			pred = rdf.RdfType
+		} else {
+			var err error
+			if pred, err = p.resource(); err != nil {
+				return err
+			}
+		}
+		for {
+			obj, err := p.object(pred)
+			if err != nil {
+				return err
+			}
+			p.triples = append(p.triples, tstore.SubjPred(subject, pred).Object(obj))
+			p.skipSpaces()
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+		p.skipSpaces()
+		if p.peek() != ';' {
+			return nil
+		}
+		for p.peek() == ';' {
+			p.pos++
+			p.skipSpaces()
+		}
+		if c := p.peek(); c == '.' || c == ']' {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) anonymousNode() (string, error) {
+	p.pos++
+	id := randomRdfId()
+	p.skipSpaces()
+	if p.peek() != ']' {
+		if err := p.predicateObjectList(id); err != nil {
+			return id, err
+		}
+	}
+	return id, p.expect(']')
+}
+
+func (p *turtleParser) object(pred string) (tstore.Object, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '[':
+		id, err := p.anonymousNode()
+		return tstore.Resource(id), err
+	case c == '(':
+		return nil, errors.New("collections are not supported")
+	case c == '"' || c == '\'':
+		value, err := p.quotedString()
+		if err != nil {
+			return nil, err
+		}
+		dataType := string(tstore.XsdString)
+		if p.peek() == '@' {
+			p.pos++
+			p.word()
+		} else if strings.HasPrefix(string(p.in[p.pos:min(p.pos+2, len(p.in))]), "^^") {
+			p.pos += 2
+			if dataType, err = p.resource(); err != nil {
+				return nil, err
+			}
+		}
+		return importLiteral(pred, value, dataType)
+	case c == '<' || c == '_':
+		id, err := p.resource()
+		return tstore.Resource(id), err
+	}
+
+	word := p.peekWord()
+	switch {
+	case word == "true" || word == "false":
+		p.pos += len(word)
+		return importLiteral(pred, word, rdf.XsdBoolean)
+	case len(word) > 0 && (unicode.IsDigit(rune(word[0])) || word[0] == '-' || word[0] == '+'):
+		p.pos += len(word)
+		if strings.ContainsAny(word, ".eE") {
+			return importLiteral(pred, word, string(tstore.XsdDouble))
+		}
+		return importLiteral(pred, word, string(tstore.XsdInteger))
+	}
+	id, err := p.resource()
+	return tstore.Resource(id), err
+}
+
+// importLiteral builds a literal typed after the awless property, or after the given datatype
+// for unknown properties. Literals of properties referencing resources become resources.
+func importLiteral(pred, value, dataType string) (tstore.Object, error) {
+	if prop, err := rdf.Properties.Get(pred); err == nil {
+		if prop.RdfsDefinedBy == rdf.RdfsClass || prop.RdfsDataType == rdf.RdfsClass {
+			return tstore.Resource(value), nil
+		}
+		if prop.RdfsDataType != "" && !strings.HasPrefix(prop.RdfsDataType, rdf.CloudOwlNS) && !strings.HasPrefix(prop.RdfsDataType, rdf.NetowlNS) {
+			dataType = prop.RdfsDataType
+		}
+	}
+	converted, err := convertImportedValue(value, dataType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", pred, err)
+	}
+	return tstore.ObjectLiteral(converted)
+}
+
+// resource parses an IRI, a prefixed name or a blank node label
+func (p *turtleParser) resource() (string, error) {
+	p.skipSpaces()
+	if p.peek() == '<' {
+		iri, err := p.iriRef()
+		if err != nil {
+			return "", err
+		}
+		if p.base != "" && !strings.Contains(iri, ":") {
+			iri = p.base + iri
+		}
+		return importIRI(iri), nil
+	}
+
+	word := p.word()
+	if word == "" {
+		return "", fmt.Errorf("unexpected '%c'", p.peek())
+	}
+	if strings.HasPrefix(word, "_:") {
+		id, ok := p.blanks[word]
+		if !ok {
+			id = randomRdfId()
+			p.blanks[word] = id
+		}
+		return id, nil
+	}
+	splits := strings.SplitN(word, ":", 2)
+	if len(splits) != 2 {
+		return "", fmt.Errorf("expected IRI or prefixed name, got '%s'", word)
+	}
+	if iri, ok := p.prefixes[splits[0]]; ok {
+		return importIRI(iri + splits[1]), nil
+	}
+	if _, ok := exportPrefixes[splits[0]]; ok {
+		return word, nil
+	}
+	return "", fmt.Errorf("undeclared prefix '%s'", splits[0])
+}
+
+// importIRI maps IRIs of the awless namespaces and resources to their awless identifiers
+func importIRI(iri string) string {
+	resourcesIRI := ExportBaseIRI + "resources/"
+	if strings.HasPrefix(iri, resourcesIRI) {
+		if id, err := url.QueryUnescape(strings.TrimPrefix(iri, resourcesIRI)); err == nil {
+			return id
+		}
+	}
+	for name, nsIRI := range exportPrefixes {
+		if strings.HasPrefix(iri, nsIRI) {
+			return name + ":" + strings.TrimPrefix(iri, nsIRI)
+		}
+	}
+	return iri
+}
+
+func (p *turtleParser) iriRef() (string, error) {
+	p.skipSpaces()
+	if err := p.expect('<'); err != nil {
+		return "", err
+	}
+	start := p.pos
+	for p.pos < len(p.in) && p.in[p.pos] != '>' {
+		p.pos++
+	}
+	if p.pos >= len(p.in) {
+		return "", errors.New("unterminated IRI")
+	}
+	iri := string(p.in[start:p.pos])
+	p.pos++
+	return iri, nil
+}
+
+func (p *turtleParser) quotedString() (string, error) {
+	quote := p.in[p.pos]
+	long := p.pos+2 < len(p.in) && p.in[p.pos+1] == quote && p.in[p.pos+2] == quote
+	if long {
+		p.pos += 3
+	} else {
+		p.pos++
+	}
+
+	var value []rune
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.in):
+			p.pos++
+			switch e := p.in[p.pos]; e {
+			case 't':
+				value = append(value, '\t')
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 'b':
+				value = append(value, '\b')
+			case 'f':
+				value = append(value, '\f')
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if p.pos+size >= len(p.in) {
+					return "", errors.New("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.in[p.pos+1:p.pos+1+size]), 16, 32)
+				if err != nil {
+					return "", fmt.Errorf("invalid unicode escape: %s", err)
+				}
+				value = append(value, rune(code))
+				p.pos += size
+			default:
+				value = append(value, e)
+			}
+			p.pos++
+		case c == quote && (!long || (p.pos+2 < len(p.in) && p.in[p.pos+1] == quote && p.in[p.pos+2] == quote)):
+			if long {
+				p.pos += 3
+			} else {
+				p.pos++
+			}
+			return string(value), nil
+		case c == '\n' && !long:
+			return "", errors.New("unterminated string")
+		default:
+			value = append(value, c)
+			p.pos++
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+func (p *turtleParser) peekWord() string {
+	start := p.pos
+	w := p.word()
+	p.pos = start
+	return w
+}
+
+// word reads a prefixed name, keyword or number, excluding a trailing statement terminator
+func (p *turtleParser) word() string {
+	start := p.pos
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		if unicode.IsSpace(c) || strings.ContainsRune("<>\"';,[]()#", c) {
+			break
+		}
+		if c == '^' || (c == '@' && p.pos > start) {
+			break
+		}
+		p.pos++
+	}
+	for p.pos > start && p.in[p.pos-1] == '.' {
+		p.pos--
+	}
+	return string(p.in[start:p.pos])
+}
+
+func (p *turtleParser) expect(c rune) error {
+	p.skipSpaces()
+	if p.peek() != c {
+		if p.pos >= len(p.in) {
+			return fmt.Errorf("expected '%c', got end of input", c)
+		}
+		return fmt.Errorf("expected '%c', got '%c'", c, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *turtleParser) peek() rune {
+	if p.pos >= len(p.in) {
+		return 0
+	}
+	return p.in[p.pos]
+}
+
+func (p *turtleParser) skipSpaces() {
+	for p.pos < len(p.in) {
+		switch c := p.in[p.pos]; {
+		case unicode.IsSpace(c):
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.in) && p.in[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *turtleParser) line() int {
+	return strings.Count(string(p.in[:min(p.pos, len(p.in))]), "\n") + 1
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+var importTypes = []string{"region", "vpc", "subnet", "instance", "securitygroup"}
+
+func TestImportExported(t *testing.T) {
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.VPC("vpc_1").Prop(properties.Name, "main").Prop(properties.Default, true).Build(),
+		resourcetest.Subnet("sub_1").Prop(properties.Vpc, "vpc_1").Build(),
+		resourcetest.Instance("inst_1").Prop(properties.Name, "my \"redis\"\n").Prop(properties.Launched, time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)).
+			Prop(properties.SecurityGroups, []string{"sg_1"}).Build(),
+		resourcetest.SecurityGroup("sg_1").Prop(properties.InboundRules, []*graph.FirewallRule{
+			{PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, Protocol: "tcp", IPRanges: []*net.IPNet{anywhere}},
+		}).Build(),
+	)
+	resourcetest.AddParents(g, "eu-west-1 -> vpc_1", "vpc_1 -> sub_1", "sub_1 -> inst_1", "vpc_1 -> sg_1")
+	g.AddAppliesOnRelation(graph.InitResource("securitygroup", "sg_1"), graph.InitResource("instance", "inst_1"))
+
+	for _, format := range []string{"turtle", "json"} {
+		var buff bytes.Buffer
+		if err := g.Export(&buff, format, importTypes...); err != nil {
+			t.Fatal(err)
+		}
+		imported := graph.NewGraph()
+		if err := imported.Import(&buff, format, importTypes...); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		for _, id := range []string{"vpc_1", "inst_1", "sub_1"} {
+			expected, _ := g.FindResource(id)
+			got, err := imported.FindResource(id)
+			if err != nil || got == nil {
+				t.Fatalf("%s: %s not found: %v", format, id, err)
+			}
+			if !reflect.DeepEqual(got.Properties, expected.Properties) {
+				t.Fatalf("%s: got %#v, want %#v", format, got.Properties, expected.Properties)
+			}
+		}
+		inst, _ := imported.FindResource("inst_1")
+		parents, _ := imported.ListRelatedResources(inst)
+		var ids []string
+		for _, r := range parents {
+			ids = append(ids, r.Id())
+		}
+		if got, want := ids, []string{"sub_1", "vpc_1", "eu-west-1", "sg_1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", format, got, want)
+		}
+		if format == "turtle" {
+			sg, _ := imported.FindResource("sg_1")
+			if got, want := len(sg.Properties[properties.InboundRules].([]*graph.FirewallRule)), 1; got != want {
+				t.Fatalf("got %d, want %d", got, want)
+			}
+		}
+	}
+}
+
+func TestImportTriples(t *testing.T) {
+	ntriples := `<srv_1> <rdf:type> <cloud-owl:Instance> .
+<srv_1> <cloud:name> "db server" .
+<srv_1> <cloud:subnet> "sub_1" .
+<srv_1> <cloud:launched> "2017-06-01T00:00:00Z" .
+<sub_1> <rdf:type> <cloud-owl:Subnet> .
+<sub_1> <cloud-rel:parentOf> <srv_1> .
+`
+	turtle := `@prefix cloud: <http://awless.io/ns/cloud#> .
+PREFIX owl: <http://awless.io/ns/cloud-owl#>
+@base <http://cmdb.local/> .
+# servers from the CMDB
+<http://awless.io/ns/resources/srv_2> a owl:Instance ;
+	cloud:name 'web' , "ignored"@en ;
+	cloud:state """run
+ning""" ;
+	cloud:securityGroups <sg_1>, <sg_2> .
+`
+	g := graph.NewGraph()
+	if err := g.Import(strings.NewReader(ntriples), "ntriples", importTypes...); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Import(strings.NewReader(turtle), "turtle", importTypes...); err != nil {
+		t.Fatal(err)
+	}
+
+	srv1, _ := g.GetResource("instance", "srv_1")
+	if got, want := srv1.Properties[properties.Launched], time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+	if got, want := srv1.Properties[properties.Subnet], "sub_1"; got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+	srv2, _ := g.GetResource("instance", "srv_2")
+	if got, want := srv2.Properties[properties.State], "run\nning"; got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+	sgs := srv2.Properties[properties.SecurityGroups].([]string)
+	if len(sgs) != 2 || !strings.HasPrefix(sgs[0], "http://cmdb.local/sg_") {
+		t.Fatalf("unexpected %v", sgs)
+	}
+
+	for _, invalid := range []struct{ format, text string }{
+		{"ntriples", `<srv_3> <rdf:type> <cloud-owl:Server> .`},
+		{"turtle", `<srv_3> a unknown:Instance .`},
+		{"turtle", `<srv_3> a cloud-owl:Instance ; cloud:launched "yesterday" .`},
+		{"turtle", `<srv_3> a cloud-owl:Instance ; cloud:name "unterminated .`},
+		{"turtle", `<srv_3> a cloud-owl:Instance`},
+		{"json", `[{"id": "srv_3", "type": "instance", "properties": {"Unknown": 1}}]`},
+		{"json", `[{"id": "srv_3", "type": "instance", "properties": {"Name": "srv"}, "children": [{"id": "vol_1"}]}]`},
+		{"yaml", ``},
+	} {
+		if err := g.Import(strings.NewReader(invalid.text), invalid.format, importTypes...); err == nil {
+			t.Fatalf("%s: expected error for %s", invalid.format, invalid.text)
+		}
+	}
+	if r, _ := g.FindResource("srv_3"); r != nil {
+		t.Fatal("expected graph untouched on import errors")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/cloud/rdf"
+	tstore "github.com/wallix/triplestore"
+)
+
+// resourcesIndex holds the ids of the resources of a graph by id, name, type and tag
+// along with the resources already unmarshaled, so that lookups neither scan
+// nor unmarshal the triples again. It is built on the first lookup once the graph
+// is loaded and dropped as soon as triples are added to the graph.
+type resourcesIndex struct {
+	byId, byName, byType, byTag map[string][]string
+	resources                   map[string]*Resource
+}
+
+func buildResourcesIndex(snap tstore.RDFGraph) *resourcesIndex {
+	idx := &resourcesIndex{
+		byId:      make(map[string][]string),
+		byName:    make(map[string][]string),
+		byType:    make(map[string][]string),
+		byTag:     make(map[string][]string),
+		resources: make(map[string]*Resource),
+	}
+	for _, t := range snap.WithPredicate(rdf.RdfType) {
+		if class, ok := t.Object().Resource(); ok && nestedRdfClasses[class] {
+			continue
+		}
+		if typ, err := unmarshalResourceType(t.Object()); err == nil {
+			idx.byType[typ] = append(idx.byType[typ], t.Subject())
+		}
+	}
+	for pred, index := range map[string]map[string][]string{rdf.ID: idx.byId, rdf.Name: idx.byName} {
+		for _, t := range snap.WithPredicate(pred) {
+			if lit, ok := t.Object().Literal(); ok {
+				index[lit.Value()] = append(index[lit.Value()], t.Subject())
+			}
+		}
+	}
+	for _, t := range snap.WithPredicate(rdf.Tags) {
+		if lit, ok := t.Object().Literal(); ok {
+			tag := lit.Value()
+			idx.byTag[tag] = append(idx.byTag[tag], t.Subject())
+			if i := strings.Index(tag, "="); i > 0 {
+				idx.byTag[tag[:i]] = append(idx.byTag[tag[:i]], t.Subject())
+			}
+		}
+	}
+	for _, index := range []map[string][]string{idx.byId, idx.byName, idx.byType, idx.byTag} {
+		for k, ids := range index {
+			index[k] = uniqueSortedIds(ids)
+		}
+	}
+	return idx
+}
+
+func (g *Graph) indexedResources(lookup func(*resourcesIndex) []string) ([]*Resource, error) {
+	g.indexMu.Lock()
+	defer g.indexMu.Unlock()
+
+	snap := g.store.Snapshot()
+	if g.index == nil {
+		g.index = buildResourcesIndex(snap)
+	}
+
+	var resources []*Resource
+	for _, id := range lookup(g.index) {
+		res, ok := g.index.resources[id]
+		if !ok {
+			rt, err := resolveResourceType(snap, id)
+			if err != nil {
+				return resources, err
+			}
+			res = InitResource(rt, id)
+			if err := res.unmarshalFullRdf(snap); err != nil {
+				return resources, err
+			}
+			g.index.resources[id] = res
+		}
+		resources = append(resources, res.copy())
+	}
+	return resources, nil
+}
+
+func (g *Graph) addTriples(ts ...tstore.Triple) {
+	g.indexMu.Lock()
+	defer g.indexMu.Unlock()
+	g.store.Add(ts...)
+	g.index = nil
+}
+
+func (g *Graph) removeTriples(ts ...tstore.Triple) {
+	g.indexMu.Lock()
+	defer g.indexMu.Unlock()
+	g.store.Remove(ts...)
+	g.index = nil
+}
+
+// copy returns a deep copy of the resource, so that callers mutating the
+// returned properties (ex: appending to a slice of rules) leave the index untouched
+func (res *Resource) copy() *Resource {
+	dup := InitResource(res.Type(), res.Id())
+	for k, v := range res.Properties {
+		dup.Properties[k] = copyValue(v)
+	}
+	for k, v := range res.Meta {
+		dup.Meta[k] = copyValue(v)
+	}
+	return dup
+}
+
+func copyValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		dup := reflect.New(v.Type().Elem())
+		dup.Elem().Set(deepCopy(v.Elem()))
+		return dup
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		dup := reflect.New(v.Type()).Elem()
+		dup.Set(deepCopy(v.Elem()))
+		return dup
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		dup := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			dup.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return dup
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		dup := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			dup.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return dup
+	case reflect.Struct:
+		dup := reflect.New(v.Type()).Elem()
+		dup.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if dup.Field(i).CanSet() {
+				dup.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return dup
+	default:
+		return v
+	}
+}
+
+func uniqueSortedIds(ids []string) []string {
+	sort.Strings(ids)
+	var unique []string
+	for i, id := range ids {
+		if i == 0 || ids[i-1] != id {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestIndexedLookups(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Instance("inst_1").Prop("Name", "redis").Prop("Tags", []string{"Env=prod", "Team=data"}).Build(),
+		resourcetest.Instance("inst_2").Prop("Tags", []string{"Env=dev"}).Build(),
+		resourcetest.Subnet("sub_1").Prop("Name", "redis").Build(),
+		resourcetest.SecurityGroup("sg_1").Prop("InboundRules", []*graph.FirewallRule{{Protocol: "tcp", PortRange: graph.PortRange{FromPort: 22, ToPort: 22}}}).Build(),
+	)
+
+	ids := func(resources []*graph.Resource) (ids []string) {
+		for _, r := range resources {
+			ids = append(ids, r.Id())
+		}
+		return
+	}
+
+	tcases := []struct {
+		resolver graph.Resolver
+		exp      []string
+	}{
+		{&graph.ById{Id: "inst_2"}, []string{"inst_2"}},
+		{&graph.ByProperty{Key: "Name", Value: "redis"}, []string{"inst_1", "sub_1"}},
+		{&graph.ByType{Typ: "instance"}, []string{"inst_1", "inst_2"}},
+		{&graph.ByTag{Key: "Env", Value: "prod"}, []string{"inst_1"}},
+		{&graph.ByTag{Key: "Env"}, []string{"inst_1", "inst_2"}},
+		{&graph.ByTag{Key: "Team", Value: "web"}, nil},
+	}
+	for i, tcase := range tcases {
+		resources, err := g.ResolveResources(tcase.resolver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ids(resources), tcase.exp; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d. got %v, want %v", i, got, want)
+		}
+	}
+
+	found, _ := g.FindResource("inst_1")
+	found.Properties["Name"] = "modified"
+	if found, _ = g.FindResource("inst_1"); found.Properties["Name"] != "redis" {
+		t.Fatalf("got %v, want redis", found.Properties["Name"])
+	}
+	found.Properties["Tags"].([]string)[0] = "Env=modified"
+	if found, _ = g.FindResource("inst_1"); strings.Contains(fmt.Sprint(found.Properties["Tags"]), "modified") {
+		t.Fatalf("got %v, want unmodified tags", found.Properties["Tags"])
+	}
+	found, _ = g.FindResource("sg_1")
+	found.Properties["InboundRules"].([]*graph.FirewallRule)[0].Protocol = "udp"
+	if found, _ = g.FindResource("sg_1"); found.Properties["InboundRules"].([]*graph.FirewallRule)[0].Protocol != "tcp" {
+		t.Fatalf("got %v, want tcp", found.Properties["InboundRules"])
+	}
+
+	g.AddResource(resourcetest.Instance("inst_3").Prop("Name", "redis").Prop("Tags", []string{"Env=prod"}).Build())
+	g.AddGraph(graph.NewGraph())
+	resources, _ := g.ResolveResources(&graph.ByTag{Key: "Env", Value: "prod"})
+	if got, want := ids(resources), []string{"inst_1", "inst_3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	resources, _ = g.FindResourcesByProperty("Name", "redis")
+	if got, want := ids(resources), []string{"inst_1", "inst_3", "sub_1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func buildLargeGraph(b *testing.B, count int) *graph.Graph {
+	g := graph.NewGraph()
+	var resources []*graph.Resource
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("inst_%d", i)
+		resources = append(resources, resourcetest.Instance(id).Prop("Name", fmt.Sprintf("name_%d", i)).Prop("Tags", []string{fmt.Sprintf("Env=env_%d", i%10)}).Build())
+		if i%100 == 0 {
+			resources = append(resources, resourcetest.Subnet(fmt.Sprintf("sub_%d", i)).Prop("Name", fmt.Sprintf("name_%d", i)).Build())
+		}
+	}
+	if err := g.AddResource(resources...); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := g.FindResource("inst_0"); err != nil {
+		b.Fatal(err)
+	}
+	return g
+}
+
+func BenchmarkFindResource(b *testing.B) {
+	g := buildLargeGraph(b, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if res, err := g.FindResource(fmt.Sprintf("inst_%d", i%50000)); err != nil || res == nil {
+			b.Fatal(res, err)
+		}
+	}
+}
+
+func BenchmarkFindResourcesByName(b *testing.B) {
+	g := buildLargeGraph(b, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if res, err := g.FindResourcesByProperty("Name", fmt.Sprintf("name_%d", i%50000)); err != nil || len(res) == 0 {
+			b.Fatal(res, err)
+		}
+	}
+}
+
+func BenchmarkResolveAlias(b *testing.B) {
+	g := buildLargeGraph(b, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := g.ResolveResources(&graph.And{Resolvers: []graph.Resolver{&graph.ByProperty{Key: "Name", Value: fmt.Sprintf("name_%d", (i%500)*100)}, &graph.ByType{Typ: "subnet"}}})
+		if err != nil || len(res) != 1 {
+			b.Fatal(res, err)
+		}
+	}
+}
+
+func BenchmarkGetAllResourcesOfType(b *testing.B) {
+	g := buildLargeGraph(b, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if res, err := g.GetAllResources("subnet"); err != nil || len(res) != 500 {
+			b.Fatal(len(res), err)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a resolver compiled from the awless query language:
+//
+//	query     := TYPE [ 'where' condition ]
+//	condition := condition 'and' condition | condition 'or' condition | 'not' condition | '(' condition ')'
+//	           | PROPERTY ( '=' | '!=' | '~' | '!~' | '>' | '>=' | '<' | '<=' ) VALUE
+//	           | 'exists' PROPERTY
+//	           | PROPERTY 'in' '(' query ')'
+//	           | PROPERTY 'allows' PORT [ 'from' CIDR ]
+//
+// Properties are matched case insensitively. On list properties, a comparison
+// holds if it holds for any element. 'in' holds if the property references the id
+// of a resource returned by the subquery. 'allows' applies to firewall rules properties.
+//
+// Ex: instance where State = running and SecurityGroups in (securitygroup where InboundRules allows 22 from 0.0.0.0/0)
+type Query struct {
+	Type  string
+	Where queryExpr
+}
+
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return nil, fmt.Errorf("query: %s", err)
+	}
+	p := &queryParser{tokens: tokens}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, fmt.Errorf("query: %s", err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("query: unexpected '%s'", p.peek())
+	}
+	return q, nil
+}
+
+func (q *Query) Resolve(g *Graph) ([]*Resource, error) {
+	all, err := g.GetAllResources(q.Type)
+	if err != nil {
+		return nil, err
+	}
+	if q.Where == nil {
+		return all, nil
+	}
+	if err = q.Where.prepare(g); err != nil {
+		return nil, err
+	}
+	var resources []*Resource
+	for _, r := range all {
+		if q.Where.match(r) {
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// Queries returns the query and all its nested subqueries
+func (q *Query) Queries() []*Query {
+	all := []*Query{q}
+	var walk func(queryExpr)
+	walk = func(e queryExpr) {
+		switch ee := e.(type) {
+		case *andQueryExpr:
+			walk(ee.left)
+			walk(ee.right)
+		case *orQueryExpr:
+			walk(ee.left)
+			walk(ee.right)
+		case *notQueryExpr:
+			walk(ee.expr)
+		case *inQueryExpr:
+			all = append(all, ee.subquery.Queries()...)
+		}
+	}
+	walk(q.Where)
+	return all
+}
+
+type queryExpr interface {
+	prepare(*Graph) error
+	match(*Resource) bool
+}
+
+type andQueryExpr struct{ left, right queryExpr }
+
+func (e *andQueryExpr) prepare(g *Graph) error {
+	if err := e.left.prepare(g); err != nil {
+		return err
+	}
+	return e.right.prepare(g)
+}
+func (e *andQueryExpr) match(r *Resource) bool { return e.left.match(r) && e.right.match(r) }
+
+type orQueryExpr struct{ left, right queryExpr }
+
+func (e *orQueryExpr) prepare(g *Graph) error {
+	if err := e.left.prepare(g); err != nil {
+		return err
+	}
+	return e.right.prepare(g)
+}
+func (e *orQueryExpr) match(r *Resource) bool { return e.left.match(r) || e.right.match(r) }
+
+type notQueryExpr struct{ expr queryExpr }
+
+func (e *notQueryExpr) prepare(g *Graph) error { return e.expr.prepare(g) }
+func (e *notQueryExpr) match(r *Resource) bool { return !e.expr.match(r) }
+
+type existsQueryExpr struct{ prop string }
+
+func (e *existsQueryExpr) prepare(*Graph) error { return nil }
+func (e *existsQueryExpr) match(r *Resource) bool {
+	_, ok := lookupProperty(r, e.prop)
+	return ok
+}
+
+type compareQueryExpr struct {
+	prop, op, value string
+}
+
+func (e *compareQueryExpr) prepare(*Graph) error { return nil }
+func (e *compareQueryExpr) match(r *Resource) bool {
+	val, ok := lookupProperty(r, e.prop)
+	if !ok {
+		return e.op == "!=" || e.op == "!~"
+	}
+	switch e.op {
+	case "!=":
+		return !anyQueryValue(val, func(v interface{}) bool { return compareQueryValue(v, "=", e.value) })
+	case "!~":
+		return !anyQueryValue(val, func(v interface{}) bool { return compareQueryValue(v, "~", e.value) })
+	default:
+		return anyQueryValue(val, func(v interface{}) bool { return compareQueryValue(v, e.op, e.value) })
+	}
+}
+
+type inQueryExpr struct {
+	prop     string
+	subquery *Query
+	ids      map[string]struct{}
+}
+
+func (e *inQueryExpr) prepare(g *Graph) error {
+	resources, err := e.subquery.Resolve(g)
+	if err != nil {
+		return err
+	}
+	e.ids = make(map[string]struct{})
+	for _, res := range resources {
+		e.ids[res.Id()] = struct{}{}
+	}
+	return nil
+}
+
+func (e *inQueryExpr) match(r *Resource) bool {
+	val, ok := lookupProperty(r, e.prop)
+	if !ok {
+		return false
+	}
+	return anyQueryValue(val, func(v interface{}) bool {
+		_, found := e.ids[fmt.Sprint(v)]
+		return found
+	})
+}
+
+type allowsQueryExpr struct {
+	prop string
+	port int64
+	from *net.IPNet
+}
+
+func (e *allowsQueryExpr) prepare(*Graph) error { return nil }
+func (e *allowsQueryExpr) match(r *Resource) bool {
+	val, ok := lookupProperty(r, e.prop)
+	if !ok {
+		return false
+	}
+	rules, ok := val.([]*FirewallRule)
+	if !ok {
+		return false
+	}
+	for _, rule := range rules {
+		if !rule.PortRange.Contains(e.port) {
+			continue
+		}
+		if e.from == nil {
+			return true
+		}
+		fromOnes, _ := e.from.Mask.Size()
+		for _, ipRange := range rule.IPRanges {
+			ones, _ := ipRange.Mask.Size()
+			if ones <= fromOnes && ipRange.Contains(e.from.IP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lookupProperty(r *Resource, prop string) (interface{}, bool) {
+	if v, ok := r.Properties[prop]; ok {
+		return v, true
+	}
+	for k, v := range r.Properties {
+		if strings.EqualFold(k, prop) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func anyQueryValue(val interface{}, fn func(interface{}) bool) bool {
+	switch vv := val.(type) {
+	case []string:
+		for _, v := range vv {
+			if fn(v) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, v := range vv {
+			if fn(v) {
+				return true
+			}
+		}
+		return false
+	default:
+		return fn(val)
+	}
+}
+
+func compareQueryValue(val interface{}, op, expected string) bool {
+	str := fmt.Sprint(val)
+	switch op {
+	case "=":
+		return strings.EqualFold(str, expected)
+	case "~":
+		return strings.Contains(strings.ToLower(str), strings.ToLower(expected))
+	}
+
+	var cmp int
+	switch v := val.(type) {
+	case time.Time:
+		compareTime, err := buildTimeCompare(expected, time.Now().UTC())
+		if err != nil {
+			return false
+		}
+		cmp = compareTime(v)
+	default:
+		f, ferr := strconv.ParseFloat(str, 64)
+		e, eerr := strconv.ParseFloat(expected, 64)
+		switch {
+		case ferr == nil && eerr == nil && f < e:
+			cmp = -1
+		case ferr == nil && eerr == nil && f > e:
+			cmp = 1
+		case ferr != nil || eerr != nil:
+			cmp = strings.Compare(str, expected)
+		}
+	}
+
+	switch op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *queryParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *queryParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() (string, error) {
+	if p.done() {
+		return "", errors.New("unexpected end of query")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *queryParser) isKeyword(kw string) bool {
+	return strings.EqualFold(p.peek(), kw)
+}
+
+func (p *queryParser) expect(tok string) error {
+	got, err := p.next()
+	if err != nil {
+		return fmt.Errorf("expected '%s': %s", tok, err)
+	}
+	if !strings.EqualFold(got, tok) {
+		return fmt.Errorf("expected '%s', got '%s'", tok, got)
+	}
+	return nil
+}
+
+func (p *queryParser) parseQuery() (*Query, error) {
+	typ, err := p.next()
+	if err != nil {
+		return nil, errors.New("missing resource type")
+	}
+	if isQuerySymbol(typ) {
+		return nil, fmt.Errorf("expected resource type, got '%s'", typ)
+	}
+	q := &Query{Type: strings.ToLower(typ)}
+	if p.isKeyword("where") {
+		p.next()
+		if q.Where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orQueryExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andQueryExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	switch {
+	case p.isKeyword("not"):
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notQueryExpr{expr: expr}, nil
+	case p.peek() == "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	case p.isKeyword("exists"):
+		p.next()
+		prop, err := p.parseOperand("property")
+		if err != nil {
+			return nil, err
+		}
+		return &existsQueryExpr{prop: prop}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *queryParser) parsePredicate() (queryExpr, error) {
+	prop, err := p.parseOperand("property")
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("missing operator after '%s'", prop)
+	}
+
+	switch strings.ToLower(op) {
+	case "=", "!=", "~", "!~", ">", ">=", "<", "<=":
+		value, err := p.parseOperand("value")
+		if err != nil {
+			return nil, err
+		}
+		return &compareQueryExpr{prop: prop, op: op, value: value}, nil
+	case "in":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		sub, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		return &inQueryExpr{prop: prop, subquery: sub}, p.expect(")")
+	case "allows":
+		portStr, err := p.parseOperand("port")
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.ParseInt(portStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port '%s'", portStr)
+		}
+		expr := &allowsQueryExpr{prop: prop, port: port}
+		if p.isKeyword("from") {
+			p.next()
+			cidr, err := p.parseOperand("cidr")
+			if err != nil {
+				return nil, err
+			}
+			if !strings.Contains(cidr, "/") {
+				cidr = cidr + "/32"
+			}
+			if _, expr.from, err = net.ParseCIDR(cidr); err != nil {
+				return nil, err
+			}
+		}
+		return expr, nil
+	default:
+		return nil, fmt.Errorf("unknown operator '%s' after '%s'", op, prop)
+	}
+}
+
+func (p *queryParser) parseOperand(name string) (string, error) {
+	tok, err := p.next()
+	if err != nil {
+		return "", fmt.Errorf("missing %s", name)
+	}
+	if isQuerySymbol(tok) {
+		return "", fmt.Errorf("expected %s, got '%s'", name, tok)
+	}
+	return unquoteQueryToken(tok), nil
+}
+
+var querySymbols = []string{"!=", "!~", ">=", "<=", "=", "~", ">", "<", "(", ")"}
+
+func isQuerySymbol(tok string) bool {
+	for _, s := range querySymbols {
+		if tok == s {
+			return true
+		}
+	}
+	return false
+}
+
+func unquoteQueryToken(tok string) string {
+	if len(tok) > 1 && (tok[0] == '\'' || tok[0] == '"') && tok[len(tok)-1] == tok[0] {
+		return tok[1 : len(tok)-1]
+	}
+	return tok
+}
+
+func tokenizeQuery(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted string at %d", i)
+			}
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		default:
+			if sym := matchQuerySymbol(text[i:]); sym != "" {
+				tokens = append(tokens, sym)
+				i += len(sym)
+				continue
+			}
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t\n\r'\"", rune(text[i])) && matchQuerySymbol(text[i:]) == "" {
+				i++
+			}
+			tokens = append(tokens, text[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+func matchQuerySymbol(s string) string {
+	for _, sym := range querySymbols {
+		if strings.HasPrefix(s, sym) {
+			return sym
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package graph_test
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestQuery(t *testing.T) {
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	_, private, _ := net.ParseCIDR("10.0.0.0/16")
+
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Instance("inst_1").Prop(properties.Name, "redis").Prop(properties.State, "running").Prop(properties.Subnet, "sub_1").
+			Prop(properties.SecurityGroups, []string{"sg_1", "sg_2"}).Prop(properties.Launched, time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)).Build(),
+		resourcetest.Instance("inst_2").Prop(properties.Name, "web server").Prop(properties.State, "stopped").Prop(properties.Subnet, "sub_1").
+			Prop(properties.SecurityGroups, []string{"sg_2"}).Prop(properties.Launched, time.Date(2017, 8, 1, 0, 0, 0, 0, time.UTC)).Build(),
+		resourcetest.Instance("inst_3").Prop(properties.State, "running").Prop(properties.Subnet, "sub_2").
+			Prop(properties.SecurityGroups, []string{"sg_1"}).Build(),
+		resourcetest.SecurityGroup("sg_1").Prop(properties.InboundRules, []*graph.FirewallRule{
+			{PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, Protocol: "tcp", IPRanges: []*net.IPNet{anywhere}},
+		}).Build(),
+		resourcetest.SecurityGroup("sg_2").Prop(properties.InboundRules, []*graph.FirewallRule{
+			{PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, Protocol: "tcp", IPRanges: []*net.IPNet{private}},
+			{PortRange: graph.PortRange{FromPort: 80, ToPort: 443}, Protocol: "tcp", IPRanges: []*net.IPNet{anywhere}},
+		}).Build(),
+	)
+
+	tcases := []struct {
+		query  string
+		expect []string
+	}{
+		{query: "instance", expect: []string{"inst_1", "inst_2", "inst_3"}},
+		{query: "instance where state = running", expect: []string{"inst_1", "inst_3"}},
+		{query: "instance where State != running", expect: []string{"inst_2"}},
+		{query: "instance where name ~ 'server'", expect: []string{"inst_2"}},
+		{query: "instance where name !~ 'server'", expect: []string{"inst_1", "inst_3"}},
+		{query: "instance where exists Name and not Name = redis", expect: []string{"inst_2"}},
+		{query: "instance where subnet = sub_1 and (state = running or name = 'web server')", expect: []string{"inst_1", "inst_2"}},
+		{query: "instance where SecurityGroups = sg_2", expect: []string{"inst_1", "inst_2"}},
+		{query: "instance where launched > 2017-07-01", expect: []string{"inst_2"}},
+		{query: "instance where launched <= 2017-07-01", expect: []string{"inst_1"}},
+		{query: "instance where launched > 1w and launched < 2017-07-01", expect: []string{"inst_1"}},
+		{query: "securitygroup where inboundrules allows 22", expect: []string{"sg_1", "sg_2"}},
+		{query: "securitygroup where inboundrules allows 22 from 0.0.0.0/0", expect: []string{"sg_1"}},
+		{query: "securitygroup where inboundrules allows 22 from 10.0.1.12", expect: []string{"sg_1", "sg_2"}},
+		{query: "securitygroup where inboundrules allows 100 from 0.0.0.0/0", expect: []string{"sg_2"}},
+		{query: "instance where subnet = sub_1 and securitygroups in (securitygroup where inboundrules allows 22 from 0.0.0.0/0)", expect: []string{"inst_1"}},
+		{query: "subnet", expect: nil},
+	}
+
+	for _, tcase := range tcases {
+		q, err := graph.ParseQuery(tcase.query)
+		if err != nil {
+			t.Fatalf("%s: %s", tcase.query, err)
+		}
+		resources, err := g.ResolveResources(q)
+		if err != nil {
+			t.Fatalf("%s: %s", tcase.query, err)
+		}
+		var ids []string
+		for _, r := range resources {
+			ids = append(ids, r.Id())
+		}
+		sort.Strings(ids)
+		if got, want := ids, tcase.expect; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", tcase.query, got, want)
+		}
+	}
+}
+
+func TestParseInvalidQuery(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"instance where",
+		"instance where state",
+		"instance where state running",
+		"instance where state = ",
+		"instance where (state = running",
+		"instance where state = running)",
+		"instance where name = 'unterminated",
+		"instance where securitygroups in securitygroup",
+		"securitygroup where inboundrules allows ssh",
+		"= instance",
+	} {
+		if _, err := graph.ParseQuery(query); err == nil {
+			t.Fatalf("%s: expected error", query)
+		}
+	}
+}
+
+func TestQueryWithSubqueries(t *testing.T) {
+	q, err := graph.ParseQuery("instances where not (subnet in (subnets where vpc in (vpcs))) or securitygroups in (securitygroups)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, sub := range q.Queries() {
+		types = append(types, sub.Type)
+	}
+	if got, want := types, []string{"instances", "subnets", "vpcs", "securitygroups"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
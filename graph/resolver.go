@@ -5,7 +5,6 @@ import (
 
 	"github.com/wallix/awless/cloud/properties"
 	"github.com/wallix/awless/cloud/rdf"
-	tstore "github.com/wallix/triplestore"
 )
 
 type Resolver interface {
@@ -31,6 +30,14 @@ func (r *ByProperty) Resolve(g *Graph) ([]*Resource, error) {
 	if r.Value == nil {
 		return resources, nil
 	}
+	if value, ok := r.Value.(string); ok {
+		switch r.Key {
+		case properties.ID:
+			return g.indexedResources(func(idx *resourcesIndex) []string { return idx.byId[value] })
+		case properties.Name:
+			return g.indexedResources(func(idx *resourcesIndex) []string { return idx.byName[value] })
+		}
+	}
 	rdfpropLabel, ok := rdf.Labels[r.Key]
 	if !ok {
 		return resources, fmt.Errorf("resolve by property: undefined property label '%s'", r.Key)
@@ -95,18 +102,7 @@ type ByType struct {
 }
 
 func (r *ByType) Resolve(g *Graph) ([]*Resource, error) {
-	var resources []*Resource
-	snap := g.store.Snapshot()
-	typ := namespacedResourceType(r.Typ)
-	for _, t := range snap.WithPredObj(rdf.RdfType, tstore.Resource(typ)) {
-		r := InitResource(r.Typ, t.Subject())
-		err := r.unmarshalFullRdf(snap)
-		if err != nil {
-			return resources, err
-		}
-		resources = append(resources, r)
-	}
-	return resources, nil
+	return g.indexedResources(func(idx *resourcesIndex) []string { return idx.byType[r.Typ] })
 }
 
 type ByTypes struct {
@@ -126,3 +122,17 @@ func (r *ByTypes) Resolve(g *Graph) ([]*Resource, error) {
 
 	return res, nil
 }
+
+// ByTag resolves the resources tagged with the given key and value,
+// or with the given key whatever the value when the value is empty
+type ByTag struct {
+	Key, Value string
+}
+
+func (r *ByTag) Resolve(g *Graph) ([]*Resource, error) {
+	tag := r.Key
+	if r.Value != "" {
+		tag = r.Key + "=" + r.Value
+	}
+	return g.indexedResources(func(idx *resourcesIndex) []string { return idx.byTag[tag] })
+}
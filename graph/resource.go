@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/wallix/awless/cloud/properties"
 	"github.com/wallix/awless/cloud/rdf"
 	tstore "github.com/wallix/triplestore"
 )
@@ -78,6 +79,18 @@ func (res *Resource) Same(other *Resource) bool {
 	return res.Id() == other.Id() && res.Type() == other.Type()
 }
 
+// CreatedWithVpc returns true for the resources AWS creates along with a vpc,
+// and deletes with it (default security group, main route table)
+func (res *Resource) CreatedWithVpc() bool {
+	switch res.Type() {
+	case "securitygroup":
+		return res.Properties[properties.Name] == "default"
+	case "routetable":
+		return res.Properties[properties.Main] == true
+	}
+	return false
+}
+
 func (res *Resource) marshalFullRDF() ([]tstore.Triple, error) {
 	var triples []tstore.Triple
 
@@ -84,6 +84,24 @@ func TestPrintResource(t *testing.T) {
 	}
 }
 
+func TestResourceCreatedWithVpc(t *testing.T) {
+	tcases := []struct {
+		res *Resource
+		exp bool
+	}{
+		{res: sGrpResource("sg_1").prop(properties.Name, "default").build(), exp: true},
+		{res: sGrpResource("sg_2").prop(properties.Name, "web").build(), exp: false},
+		{res: testResource("rt_1", "routetable").prop(properties.Main, true).build(), exp: true},
+		{res: testResource("rt_2", "routetable").prop(properties.Main, false).build(), exp: false},
+		{res: vpcResource("vpc_1").prop(properties.Name, "default").build(), exp: false},
+	}
+	for _, tcase := range tcases {
+		if got, want := tcase.res.CreatedWithVpc(), tcase.exp; got != want {
+			t.Fatalf("%s: got %t, want %t", tcase.res, got, want)
+		}
+	}
+}
+
 func TestReduceResources(t *testing.T) {
 	res := Resources{{id: "1"}, {id: "2"}, {id: "3"}}
 	if got, want := res.Map(func(r *Resource) string { return r.String() }), []string{"1[]", "2[]", "3[]"}; !reflect.DeepEqual(got, want) {
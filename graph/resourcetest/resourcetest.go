@@ -133,6 +133,10 @@ func Distribution(id string) *rBuilder {
 	return new("distribution", id).Prop(properties.ID, id)
 }
 
+func Volume(id string) *rBuilder {
+	return new("volume", id).Prop(properties.ID, id)
+}
+
 func Stack(id string) *rBuilder {
 	return new("stack", id).Prop(properties.ID, id)
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/cloud/rdf"
+	tstore "github.com/wallix/triplestore"
+)
+
+// TeardownOrder returns the root resource and all the resources beneath it
+// ordered so that deleting them one after the other is safe:
+//   - children are deleted before their parents (ex: instances before their subnet)
+//   - resources are deleted before the ones applying on them (ex: instances before their security groups)
+//
+// Resources applying on the root resource only (ex: an internet gateway attached
+// to the vpc) are part of the teardown, as well as their own children.
+func (g *Graph) TeardownOrder(root *Resource) ([]*Resource, error) {
+	snap := g.store.Snapshot()
+
+	members := make(map[string]bool)
+	var add func(id string) error
+	add = func(id string) error {
+		if members[id] {
+			return nil
+		}
+		members[id] = true
+		children, err := relatedIds(snap, id, rdf.ParentOf)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := add(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(root.Id()); err != nil {
+		return nil, err
+	}
+
+	for _, tri := range snap.WithPredObj(rdf.ApplyOn, tstore.Resource(root.Id())) {
+		applier := tri.Subject()
+		targets, err := relatedIds(snap, applier, rdf.ApplyOn)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 1 {
+			if err := add(applier); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	before := make(map[string][]string)
+	dependencies := make(map[string]int)
+	for id := range members {
+		dependencies[id] = 0
+	}
+	for id := range members {
+		children, err := relatedIds(snap, id, rdf.ParentOf)
+		if err != nil {
+			return nil, err
+		}
+		targets, err := relatedIds(snap, id, rdf.ApplyOn)
+		if err != nil {
+			return nil, err
+		}
+		for _, first := range append(children, targets...) {
+			if members[first] && first != id {
+				before[first] = append(before[first], id)
+				dependencies[id]++
+			}
+		}
+	}
+
+	var ready, ordered []string
+	for id, count := range dependencies {
+		if count == 0 {
+			ready = append(ready, id)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, id)
+		for _, next := range before[id] {
+			dependencies[next]--
+			if dependencies[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(ordered) != len(members) {
+		var cycle []string
+		for id, count := range dependencies {
+			if count > 0 {
+				cycle = append(cycle, id)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("teardown of %s: circular dependencies between %s", root.Id(), strings.Join(cycle, ", "))
+	}
+
+	var resources []*Resource
+	for _, id := range ordered {
+		rT, err := resolveResourceType(snap, id)
+		if err == errTypeNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res, err := g.GetResource(rT, id)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+func relatedIds(snap tstore.RDFGraph, id, predicate string) ([]string, error) {
+	var ids []string
+	for _, tri := range snap.WithSubjPred(id, predicate) {
+		other, ok := tri.Object().Resource()
+		if !ok {
+			return nil, fmt.Errorf("triple %s %s: object is not a resource identifier", id, predicate)
+		}
+		ids = append(ids, other)
+	}
+	return ids, nil
+}
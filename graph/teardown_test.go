@@ -0,0 +1,87 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+)
+
+func TestTeardownOrder(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddResource(
+		resourcetest.Region("eu-west-1").Build(),
+		resourcetest.VPC("vpc_1").Build(), resourcetest.VPC("vpc_2").Build(),
+		resourcetest.Subnet("sub_1").Build(), resourcetest.Subnet("sub_2").Build(),
+		resourcetest.Instance("inst_1").Build(), resourcetest.Instance("inst_2").Build(), resourcetest.Instance("inst_3").Build(),
+		resourcetest.SecurityGroup("sg_1").Build(), resourcetest.InternetGw("igw_1").Build(),
+		resourcetest.Volume("vol_1").Build(), resourcetest.KeyPair("kp_1").Build(),
+	)
+	resourcetest.AddParents(g,
+		"eu-west-1 -> vpc_1", "eu-west-1 -> vpc_2", "eu-west-1 -> igw_1", "eu-west-1 -> kp_1",
+		"vpc_1 -> sub_1", "vpc_1 -> sg_1", "vpc_2 -> sub_2",
+		"sub_1 -> inst_1", "sub_1 -> inst_2", "sub_2 -> inst_3",
+	)
+	applyOn := func(applier, target string) {
+		g.AddAppliesOnRelation(graph.InitResource("", applier), graph.InitResource("", target))
+	}
+	applyOn("sg_1", "inst_1")
+	applyOn("igw_1", "vpc_1")
+	applyOn("vol_1", "inst_1")
+	applyOn("kp_1", "inst_1")
+	applyOn("kp_1", "inst_3")
+
+	ids := func(resources []*graph.Resource) (ids []string) {
+		for _, r := range resources {
+			ids = append(ids, r.Id())
+		}
+		return
+	}
+
+	tcases := []struct {
+		root string
+		exp  []string
+	}{
+		{root: "vpc_1", exp: []string{"inst_1", "inst_2", "sg_1", "sub_1", "vpc_1", "igw_1"}},
+		{root: "sub_1", exp: []string{"inst_1", "inst_2", "sub_1"}},
+		{root: "inst_1", exp: []string{"inst_1", "sg_1", "vol_1"}},
+		{root: "vpc_2", exp: []string{"inst_3", "sub_2", "vpc_2"}},
+		{root: "inst_2", exp: []string{"inst_2"}},
+	}
+	for i, tcase := range tcases {
+		root, err := g.FindResource(tcase.root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources, err := g.TeardownOrder(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ids(resources), tcase.exp; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d. got %v, want %v", i, got, want)
+		}
+	}
+
+	applyOn("inst_2", "sub_1")
+	sub, _ := g.FindResource("sub_1")
+	if _, err := g.TeardownOrder(sub); err == nil {
+		t.Fatal("expected error got none")
+	}
+}
@@ -69,3 +69,69 @@ func TestCollectors(t *testing.T) {
 	}
 
 }
+
+func TestListResourcesInAndRelated(t *testing.T) {
+	g := graph.NewGraph()
+	i1 := graph.InitResource("instance", "inst_1")
+	i2 := graph.InitResource("instance", "inst_2")
+	vol := graph.InitResource("volume", "vol_1")
+	sg := graph.InitResource("securitygroup", "sg_1")
+	s1 := graph.InitResource("subnet", "sub_1")
+	v1 := graph.InitResource("vpc", "vpc_1")
+	if err := g.AddResource(i1, i2, vol, sg, s1, v1); err != nil {
+		t.Fatal(err)
+	}
+	g.AddParentRelation(v1, s1)
+	g.AddParentRelation(s1, i1)
+	g.AddParentRelation(s1, i2)
+	g.AddParentRelation(v1, sg)
+	g.AddAppliesOnRelation(sg, i1)
+	g.AddAppliesOnRelation(i1, vol)
+
+	ids := func(resources []*graph.Resource) (ids []string) {
+		for _, r := range resources {
+			ids = append(ids, r.Id())
+		}
+		return
+	}
+
+	in, err := g.ListResourcesIn(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(in), []string{"sg_1", "sub_1", "inst_1", "inst_2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	tcases := []struct {
+		from *graph.Resource
+		exp  []string
+	}{
+		{from: i1, exp: []string{"sub_1", "vpc_1", "vol_1", "sg_1"}},
+		{from: sg, exp: []string{"vpc_1", "inst_1"}},
+		{from: vol, exp: []string{"inst_1"}},
+		{from: s1, exp: []string{"vpc_1", "inst_1", "inst_2"}},
+	}
+	for i, tcase := range tcases {
+		related, err := g.ListRelatedResources(tcase.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ids(related), tcase.exp; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d. got %v, want %v", i, got, want)
+		}
+	}
+
+	related, err := g.ListRelatedResources(vol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := g.Filter("instance", graph.BuildResourcesFilterFunc(related...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := filtered.GetAllResources("instance")
+	if got, want := ids(all), []string{"inst_1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspectors
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/cloud/properties"
+	"github.com/wallix/awless/graph"
+)
+
+var consistencyRules = graph.ConsistencyRules{
+	ExpectedParents: map[string][]string{
+		cloud.Vpc:             {cloud.Region},
+		cloud.Subnet:          {cloud.Vpc},
+		cloud.Instance:        {cloud.Subnet},
+		cloud.SecurityGroup:   {cloud.Vpc},
+		cloud.RouteTable:      {cloud.Vpc},
+		cloud.InternetGateway: {cloud.Region},
+		cloud.Volume:          {cloud.AvailabilityZone},
+	},
+	IgnoredProperties: []string{properties.Location, properties.Role},
+}
+
+type Consistency struct {
+	inconsistencies []*graph.Inconsistency
+}
+
+func (*Consistency) Name() string {
+	return "consistency"
+}
+
+func (c *Consistency) Inspect(g *graph.Graph) (err error) {
+	c.inconsistencies, err = g.CheckConsistency(consistencyRules)
+	return
+}
+
+func (c *Consistency) Print(w io.Writer) {
+	if len(c.inconsistencies) == 0 {
+		fmt.Fprintln(w, "none found")
+		return
+	}
+	tab := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, i := range c.inconsistencies {
+		fmt.Fprintf(tab, "%s\t%s\t%s\n", i.Kind, i.Resource, i.Message)
+	}
+	tab.Flush()
+}
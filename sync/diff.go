@@ -17,34 +17,39 @@ limitations under the License.
 package sync
 
 import (
+	"fmt"
+
 	"github.com/wallix/awless/graph"
 	"github.com/wallix/awless/sync/repo"
 )
 
 // Diff represents the deleted/inserted RDF triples of a revision
 type Diff struct {
-	From       *repo.Rev
-	To         *repo.Rev
-	InfraDiff  *graph.Diff
-	AccessDiff *graph.Diff
+	From *repo.Rev
+	To   *repo.Rev
+
+	// Diffs are the diffs of the services synced in any of the revisions, by service name
+	Diffs map[string]*graph.Diff
 }
 
 func BuildDiff(from, to *repo.Rev, root string) (*Diff, error) {
-	infraDiff, err := graph.DefaultDiffer.Run(root, from.Infra, to.Infra)
-	if err != nil {
-		return nil, err
-	}
-
-	accessDiff, err := graph.DefaultDiffer.Run(root, from.Access, to.Access)
-	if err != nil {
-		return nil, err
+	res := &Diff{
+		From:  from,
+		To:    to,
+		Diffs: make(map[string]*graph.Diff),
 	}
 
-	res := &Diff{
-		From:       from,
-		To:         to,
-		InfraDiff:  infraDiff,
-		AccessDiff: accessDiff,
+	for _, rev := range []*repo.Rev{from, to} {
+		for name := range rev.Graphs {
+			if _, done := res.Diffs[name]; done {
+				continue
+			}
+			diff, err := graph.DefaultDiffer.Run(root, from.Graph(name), to.Graph(name))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			res.Diffs[name] = diff
+		}
 	}
 
 	return res, nil
@@ -0,0 +1,172 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/sync/repo"
+)
+
+const (
+	ResourceAppeared    = "appeared"
+	ResourceUpdated     = "updated"
+	ResourceDisappeared = "disappeared"
+)
+
+// ResourceChange is the change of a resource from the previous sync revision to Rev
+type ResourceChange struct {
+	Rev  *repo.Rev
+	Kind string
+	// Resource is the resource at Rev, or at the previous revision once disappeared
+	Resource   *graph.Resource
+	Properties []*graph.PropertyChange
+	Relations  []*RelationChange
+}
+
+// RelationChange is a direct relation (parent, child, applies on, depended on by) added or removed
+type RelationChange struct {
+	Relation string
+	Resource *graph.Resource
+	Added    bool
+}
+
+// ResourceHistory returns the changes of the resource of the given id across
+// the given revisions, in the order of their dates
+func ResourceHistory(revs []*repo.Rev, id string) ([]*ResourceChange, error) {
+	sorted := make([]*repo.Rev, len(revs))
+	copy(sorted, revs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	var changes []*ResourceChange
+	var previous *graph.Resource
+	var previousRelations map[string]*RelationChange
+
+	for _, rev := range sorted {
+		g := graph.NewGraph()
+		for _, name := range sortedServiceNames(rev) {
+			g.AddGraph(rev.Graphs[name])
+		}
+		res, err := g.FindResource(id)
+		if err != nil {
+			return changes, fmt.Errorf("revision %s: %s", rev.Id, err)
+		}
+
+		switch {
+		case res == nil && previous == nil:
+			continue
+		case res == nil:
+			changes = append(changes, &ResourceChange{Rev: rev, Kind: ResourceDisappeared, Resource: previous})
+			previous, previousRelations = nil, nil
+			continue
+		}
+
+		relations, err := directRelations(g, res)
+		if err != nil {
+			return changes, fmt.Errorf("revision %s: %s", rev.Id, err)
+		}
+
+		if previous == nil {
+			changes = append(changes, &ResourceChange{Rev: rev, Kind: ResourceAppeared, Resource: res})
+		} else {
+			change := &ResourceChange{
+				Rev:        rev,
+				Kind:       ResourceUpdated,
+				Resource:   res,
+				Properties: graph.DiffResourceProperties(previous, res),
+				Relations:  relationChanges(previousRelations, relations),
+			}
+			if len(change.Properties) > 0 || len(change.Relations) > 0 {
+				changes = append(changes, change)
+			}
+		}
+		previous, previousRelations = res, relations
+	}
+
+	return changes, nil
+}
+
+func sortedServiceNames(rev *repo.Rev) (names []string) {
+	for name := range rev.Graphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// directRelations returns the direct relations of a resource keyed by relation and resource id
+func directRelations(g *graph.Graph, res *graph.Resource) (map[string]*RelationChange, error) {
+	relations := make(map[string]*RelationChange)
+	add := func(relation string, others ...*graph.Resource) {
+		for _, other := range others {
+			relations[relation+" "+other.Id()] = &RelationChange{Relation: relation, Resource: other}
+		}
+	}
+	direct := func(relation string) func(*graph.Resource, int) error {
+		return func(other *graph.Resource, depth int) error {
+			if depth == 1 {
+				add(relation, other)
+			}
+			return nil
+		}
+	}
+
+	if err := g.Accept(&graph.ParentsVisitor{From: res, Each: direct("parent")}); err != nil {
+		return relations, err
+	}
+	if err := g.Accept(&graph.ChildrenVisitor{From: res, Each: direct("child")}); err != nil {
+		return relations, err
+	}
+	appliedOn, err := g.ListResourcesAppliedOn(res)
+	if err != nil {
+		return relations, err
+	}
+	add("applies on", appliedOn...)
+	dependingOn, err := g.ListResourcesDependingOn(res)
+	if err != nil {
+		return relations, err
+	}
+	add("depended on by", dependingOn...)
+
+	return relations, nil
+}
+
+func relationChanges(from, to map[string]*RelationChange) (changes []*RelationChange) {
+	var keys []string
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if rel, ok := to[k]; ok {
+			changes = append(changes, &RelationChange{Relation: rel.Relation, Resource: rel.Resource, Added: true})
+		} else {
+			changes = append(changes, &RelationChange{Relation: from[k].Relation, Resource: from[k].Resource})
+		}
+	}
+	return
+}
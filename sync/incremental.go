@@ -0,0 +1,181 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	gosync "sync"
+	"time"
+
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/database"
+	"github.com/wallix/awless/graph"
+)
+
+// TTLFunc returns the duration during which the synced resources of a type
+// are fresh and not fetched again. With 0, resources are fetched on each sync.
+// With a negative duration, resources are only fetched along with their whole service
+// (ex: types whose sync is disabled)
+type TTLFunc func(resourceType string) time.Duration
+
+const serviceScopeKeyPrefix = "sync.scope."
+
+// lastSyncs returns the last sync times of the resource types per service, for the services
+// whose local graph has been written in the scope of the syncer
+func (s *syncer) lastSyncs(services []cloud.Service) map[string]map[string]time.Time {
+	lastSyncs := make(map[string]map[string]time.Time)
+	if s.ttl == nil {
+		return lastSyncs
+	}
+
+	err := database.Execute(func(db *database.DB) error {
+		syncs, err := db.GetLastSyncs(s.scope)
+		if err != nil {
+			return err
+		}
+		for _, srv := range services {
+			scope, err := db.GetStringValue(serviceScopeKeyPrefix + srv.Name())
+			if err != nil {
+				return err
+			}
+			if scope != s.scope {
+				continue
+			}
+			lastSyncs[srv.Name()] = make(map[string]time.Time)
+			for _, typ := range srv.ResourceTypes() {
+				if t, ok := syncs[typ]; ok {
+					lastSyncs[srv.Name()][typ] = t
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Warningf("sync: cannot load last sync times, fetching all resources: %s", err)
+		return make(map[string]map[string]time.Time)
+	}
+	return lastSyncs
+}
+
+func (s *syncer) setLastSyncs(syncedTypes map[string][]string, t time.Time) error {
+	if len(syncedTypes) == 0 {
+		return nil
+	}
+	return database.Execute(func(db *database.DB) error {
+		for srvName, types := range syncedTypes {
+			if err := db.SetStringValue(serviceScopeKeyPrefix+srvName, s.scope); err != nil {
+				return err
+			}
+			if s.ttl == nil {
+				continue
+			}
+			if err := db.SetLastSyncs(s.scope, t, types...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// staleTypes returns the resource types of the service to fetch again,
+// and whether they are all the ones to fetch, i.e. the whole service is to fetch
+func (s *syncer) staleTypes(srv cloud.Service, lastSyncs map[string]time.Time, now time.Time) ([]string, bool) {
+	if s.ttl == nil || lastSyncs == nil || !fileExists(localGraphPath(srv.Name())) {
+		return srv.ResourceTypes(), true
+	}
+
+	var stale []string
+	var eligible int
+	for _, typ := range srv.ResourceTypes() {
+		ttl := s.ttl(typ)
+		if ttl < 0 {
+			continue
+		}
+		eligible++
+		if last, ok := lastSyncs[typ]; !ok || now.Sub(last) >= ttl {
+			stale = append(stale, typ)
+		}
+	}
+	return stale, len(stale) == eligible
+}
+
+// fetchStaleTypes fetches concurrently the stale types of the services and
+// merges them into the given local graphs of the services. Once all the types of a service
+// are merged, their relations are built again in its graph, which holds the resources they relate to
+func (s *syncer) fetchStaleTypes(staleTypes map[cloud.Service][]string, localGraphs map[string]*graph.Graph) (map[string]*graph.Graph, map[string][]string, []error) {
+	type result struct {
+		srv       cloud.Service
+		typ       string
+		gph       *graph.Graph
+		relations func(*graph.Graph) error
+		err       error
+		start     time.Time
+	}
+
+	var workers gosync.WaitGroup
+	resultc := make(chan *result)
+	for srv, types := range staleTypes {
+		for _, typ := range types {
+			workers.Add(1)
+			go func(srv cloud.Service, typ string) {
+				defer workers.Done()
+				res := &result{srv: srv, typ: typ, start: time.Now()}
+				if fetcher, ok := srv.(cloud.RelationsFetcher); ok {
+					res.gph, res.relations, res.err = fetcher.FetchByTypeWithRelations(typ)
+				} else {
+					res.gph, res.err = srv.FetchByType(typ)
+				}
+				resultc <- res
+			}(srv, typ)
+		}
+	}
+	go func() {
+		workers.Wait()
+		close(resultc)
+	}()
+
+	graphs := make(map[string]*graph.Graph)
+	var fetched []*result
+	var allErrors []error
+	for res := range resultc {
+		name := res.srv.Name()
+		if res.err != nil {
+			allErrors = append(allErrors, fmt.Errorf("syncing %s[%s]: %s", name, res.typ, res.err))
+			continue
+		}
+		s.logger.ExtraVerbosef("sync: fetched %s[%s] took %s", name, res.typ, time.Since(res.start))
+		if _, ok := graphs[name]; !ok {
+			graphs[name] = localGraphs[name]
+		}
+		graphs[name].ReplaceResources(res.typ, res.gph)
+		fetched = append(fetched, res)
+	}
+
+	syncedTypes := make(map[string][]string)
+	for _, res := range fetched {
+		name := res.srv.Name()
+		if res.relations != nil {
+			if err := graphs[name].RebuildRelations(res.typ, res.relations); err != nil {
+				allErrors = append(allErrors, fmt.Errorf("syncing %s[%s]: relations: %s", name, res.typ, err))
+				continue
+			}
+		}
+		syncedTypes[name] = append(syncedTypes[name], res.typ)
+	}
+
+	return graphs, syncedTypes, allErrors
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/wallix/awless/sync/repo"
+)
+
+const (
+	graphsLockFilename = ".graphs.lock"
+	daemonLockFilename = ".daemon.lock"
+)
+
+// ErrDaemonRunning is returned by LockDaemon when a sync daemon is already running
+var ErrDaemonRunning = errors.New("a sync daemon is already running")
+
+// withGraphsLock runs fn holding the lock on the local graphs shared by the awless processes:
+// exclusively when writing graphs, shared when reading them, so that readers always load a
+// consistent snapshot. Graphs are not locked if they have never been synced.
+func withGraphsLock(exclusive bool, fn func() error) error {
+	f, err := os.OpenFile(filepath.Join(repo.Dir(), graphsLockFilename), os.O_CREATE|os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return fn()
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, exclusive, true); err != nil {
+		return err
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// LockDaemon marks a sync daemon as running in the given scope (ex: profile and region) until
+// the returned func is called or the process exits. It returns ErrDaemonRunning if a daemon is already running.
+func LockDaemon(scope string) (func(), error) {
+	os.MkdirAll(repo.Dir(), 0700)
+	f, err := os.OpenFile(filepath.Join(repo.Dir(), daemonLockFilename), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, true, false); err != nil {
+		f.Close()
+		if err == errLocked {
+			return nil, ErrDaemonRunning
+		}
+		return nil, err
+	}
+	release := func() {
+		unlockFile(f)
+		f.Close()
+	}
+	if err := f.Truncate(0); err != nil {
+		release()
+		return nil, err
+	}
+	if _, err := f.WriteAt([]byte(scope), 0); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// DaemonRunning returns whether a sync daemon keeps the local graphs of the given scope fresh in the background
+func DaemonRunning(scope string) bool {
+	f, err := os.Open(filepath.Join(repo.Dir(), daemonLockFilename))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if err := lockFile(f, false, false); err != nil {
+		if err != errLocked {
+			return false
+		}
+		daemonScope, err := ioutil.ReadAll(f)
+		return err == nil && string(daemonScope) == scope
+	}
+	unlockFile(f)
+	return false
+}
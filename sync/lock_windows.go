@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+
+	// lockOffsetHigh places the locked byte far beyond the content of the files,
+	// as the locked regions of a file cannot be read by other processes
+	lockOffsetHigh = 0x7fffffff
+)
+
+var (
+	errLocked = errors.New("file locked")
+
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+func lockFile(f *os.File, exclusive, wait bool) error {
+	var flags uintptr
+	if exclusive {
+		flags |= lockfileExclusiveLock
+	}
+	if !wait {
+		flags |= lockfileFailImmediately
+	}
+	ol := syscall.Overlapped{OffsetHigh: lockOffsetHigh}
+	r, _, err := procLockFileEx.Call(f.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		if err == errorLockViolation {
+			return errLocked
+		}
+		return err
+	}
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	ol := syscall.Overlapped{OffsetHigh: lockOffsetHigh}
+	r, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DirBackend stores the files in a directory, local or mounted (ex: NFS)
+type DirBackend struct {
+	root string
+}
+
+func NewDirBackend(root string) *DirBackend {
+	return &DirBackend{root: root}
+}
+
+func (b *DirBackend) List(prefix string) ([]string, error) {
+	var names []string
+	err := filepath.Walk(b.root, func(p string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, p)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+func (b *DirBackend) Get(name string) ([]byte, error) {
+	content, err := ioutil.ReadFile(b.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return content, err
+}
+
+// Put writes the file through a temporary file renamed once written,
+// so that concurrent readers never get a partial content
+func (b *DirBackend) Put(name string, content []byte) error {
+	p := b.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".awless-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (b *DirBackend) path(name string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean("/"+name)))
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remote provides the backends storing a synced inventory shared by a team
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNotFound = errors.New("remote file not found")
+
+// Backend stores named files, names being slash separated paths
+type Backend interface {
+	// List returns the names of the files stored under the given prefix
+	List(prefix string) ([]string, error)
+	// Get returns the content of the named file, or ErrNotFound
+	Get(name string) ([]byte, error)
+	// Put stores the content of the named file, overwriting any previous content
+	Put(name string, content []byte) error
+}
+
+// ParseS3URL returns the bucket and key prefix of a S3 URL (ex: s3://bucket/team/inventory)
+func ParseS3URL(url string) (bucket, prefix string, err error) {
+	if !IsS3URL(url) {
+		return "", "", fmt.Errorf("invalid S3 URL '%s': expecting s3://bucket/prefix", url)
+	}
+	splits := strings.SplitN(strings.TrimPrefix(url, "s3://"), "/", 2)
+	if splits[0] == "" {
+		return "", "", fmt.Errorf("invalid S3 URL '%s': missing bucket", url)
+	}
+	if len(splits) > 1 {
+		prefix = strings.Trim(splits[1], "/")
+	}
+	return splits[0], prefix, nil
+}
+
+// IsS3URL returns whether the remote location is a S3 URL rather than a directory
+func IsS3URL(url string) bool {
+	return strings.HasPrefix(url, "s3://")
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestDirBackend(t *testing.T) {
+	root, err := ioutil.TempDir("", "awless-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	testBackend(t, NewDirBackend(filepath.Join(root, "inventory")))
+
+	if _, err := os.Stat(filepath.Join(root, "escaped")); !os.IsNotExist(err) {
+		t.Fatalf("got %v, want file written inside the backend directory", err)
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	server := newFakeS3Server("inventories")
+	defer server.Close()
+
+	sess := session.New(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+	})
+	testBackend(t, NewS3Backend(s3.New(sess), "inventories", "/team/"))
+
+	var keys []string
+	for key := range server.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if got, want := keys, []string{"team/escaped", "team/graphs/infra.triples", "team/graphs/us-east-1/infra.triples", "team/revisions/objects/ab/cdef"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func testBackend(t *testing.T, b Backend) {
+	if names, err := b.List(""); err != nil || len(names) != 0 {
+		t.Fatalf("got %v (err %v), want no files", names, err)
+	}
+	if _, err := b.Get("graphs/infra.triples"); err != ErrNotFound {
+		t.Fatalf("got %v, want %v", err, ErrNotFound)
+	}
+
+	files := map[string]string{
+		"graphs/infra.triples":           "first",
+		"graphs/us-east-1/infra.triples": "region",
+		"revisions/objects/ab/cdef":      "object",
+		"../escaped":                     "escaped",
+	}
+	for name, content := range files {
+		if err := b.Put(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Put("graphs/infra.triples", []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := b.Get("graphs/infra.triples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(content), "second"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	names, err := b.List("graphs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	if got, want := names, []string{"graphs/infra.triples", "graphs/us-east-1/infra.triples"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseS3URL(t *testing.T) {
+	tcases := []struct {
+		url, bucket, prefix string
+		err                 bool
+	}{
+		{url: "s3://bucket", bucket: "bucket"},
+		{url: "s3://bucket/", bucket: "bucket"},
+		{url: "s3://bucket/team/inventory/", bucket: "bucket", prefix: "team/inventory"},
+		{url: "s3:///inventory", err: true},
+		{url: "/mnt/nfs/awless", err: true},
+	}
+	for _, tcase := range tcases {
+		bucket, prefix, err := ParseS3URL(tcase.url)
+		if tcase.err {
+			if err == nil {
+				t.Fatalf("%s: expected error", tcase.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tcase.url, err)
+		}
+		if bucket != tcase.bucket || prefix != tcase.prefix {
+			t.Fatalf("%s: got %s and %s, want %s and %s", tcase.url, bucket, prefix, tcase.bucket, tcase.prefix)
+		}
+	}
+}
+
+// fakeS3Server is a local stand-in of a S3 compatible storage serving a single bucket with path-style requests
+type fakeS3Server struct {
+	*httptest.Server
+	bucket  string
+	mu      gosync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3Server(bucket string) *fakeS3Server {
+	s := &fakeS3Server{bucket: bucket, objects: make(map[string][]byte)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	Name        string
+	Prefix      string
+	IsTruncated bool
+	Contents    []struct{ Key string }
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if path[0] != s.bucket {
+		s.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	if len(path) == 1 || path[1] == "" {
+		if r.Method != "GET" {
+			s.error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+			return
+		}
+		result := listBucketResult{Name: s.bucket, Prefix: r.URL.Query().Get("prefix")}
+		var keys []string
+		for key := range s.objects {
+			if strings.HasPrefix(key, result.Prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.Contents = append(result.Contents, struct{ Key string }{key})
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+
+	key := path[1]
+	switch r.Method {
+	case "GET":
+		content, ok := s.objects[key]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Write(content)
+	case "PUT":
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		s.objects[key] = content
+	default:
+		s.error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (s *fakeS3Server) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte("<Error><Code>" + code + "</Code><Message>" + code + "</Message></Error>"))
+}
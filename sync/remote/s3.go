@@ -0,0 +1,75 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3Backend stores the files as objects of a bucket under a key prefix,
+// in AWS S3 or any S3 compatible storage
+type S3Backend struct {
+	api            s3iface.S3API
+	bucket, prefix string
+}
+
+func NewS3Backend(api s3iface.S3API, bucket, prefix string) *S3Backend {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		prefix = prefix + "/"
+	}
+	return &S3Backend{api: api, bucket: bucket, prefix: prefix}
+}
+
+func (b *S3Backend) List(prefix string) ([]string, error) {
+	var names []string
+	err := b.api.ListObjectsPages(&s3.ListObjectsInput{Bucket: aws.String(b.bucket), Prefix: aws.String(b.prefix + prefix)}, func(out *s3.ListObjectsOutput, last bool) bool {
+		for _, obj := range out.Contents {
+			names = append(names, strings.TrimPrefix(aws.StringValue(obj.Key), b.prefix))
+		}
+		return true
+	})
+	return names, err
+}
+
+func (b *S3Backend) Get(name string) ([]byte, error) {
+	out, err := b.api.GetObject(&s3.GetObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(b.key(name))})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (b *S3Backend) Put(name string, content []byte) error {
+	_, err := b.api.PutObject(&s3.PutObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(b.key(name)), Body: bytes.NewReader(content)})
+	return err
+}
+
+// key returns the key of the named file, never outside of the prefix
+func (b *S3Backend) key(name string) string {
+	return b.prefix + strings.TrimPrefix(path.Clean("/"+name), "/")
+}
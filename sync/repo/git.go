@@ -19,36 +19,210 @@ package repo
 import (
 	"bytes"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	masterRef = plumbing.ReferenceName("refs/heads/master")
+
+	gitFileMode = os.FileMode(0100644)
+	gitDirMode  = os.FileMode(0040000)
 )
 
-type gitCmd struct {
-	dir string
-	env []string
+// head returns the commit of the current revision, or nil if there is none yet
+func (r *gitRepo) head() (*object.Commit, error) {
+	ref, err := r.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return r.repo.Commit(ref.Hash())
 }
 
-func newGit(workdir string, envs ...string) *gitCmd {
-	return &gitCmd{dir: workdir}
+// setHead makes the given commit the current revision, updating the branch of HEAD if any
+func (r *gitRepo) setHead(commit plumbing.Hash) error {
+	ref, err := r.storage.Reference(plumbing.HEAD)
+	if err != nil {
+		return err
+	}
+	if ref.Type() == plumbing.SymbolicReference {
+		return r.storage.SetReference(plumbing.NewHashReference(ref.Target(), commit))
+	}
+	return r.storage.SetReference(plumbing.NewHashReference(plumbing.HEAD, commit))
 }
 
-func (g *gitCmd) run(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = g.dir
-	cmd.Env = g.env
+// history returns the commits of the current revision and its ancestors, following first parents
+func (r *gitRepo) history() ([]*object.Commit, error) {
+	var commits []*object.Commit
+	commit, err := r.head()
+	for commit != nil && err == nil {
+		commits = append(commits, commit)
+		if commit.NumParents() == 0 {
+			break
+		}
+		commit, err = commit.Parents().Next()
+	}
+	return commits, err
+}
 
-	out, err := cmd.Output()
+// IsAncestor returns whether the revision ancestor is the given revision or one of its history (see history)
+func (r *gitRepo) IsAncestor(ancestor, rev string) (bool, error) {
+	commit, err := r.repo.Commit(plumbing.NewHash(rev))
+	for commit != nil && err == nil {
+		if commit.Hash.String() == ancestor {
+			return true, nil
+		}
+		if commit.NumParents() == 0 {
+			return false, nil
+		}
+		commit, err = commit.Parents().Next()
+	}
+	return false, err
+}
+
+func (r *gitRepo) writeBlob(content []byte) (plumbing.Hash, error) {
+	return r.writeObject(plumbing.BlobObject, content)
+}
+
+func (r *gitRepo) writeObject(typ plumbing.ObjectType, content []byte) (plumbing.Hash, error) {
+	obj := r.storage.NewEncodedObject()
+	obj.SetType(typ)
+	obj.SetSize(int64(len(content)))
+	w, err := obj.Writer()
 	if err != nil {
-		var msg bytes.Buffer
+		return plumbing.ZeroHash, err
+	}
+	if _, err = w.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err = w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.storage.SetEncodedObject(obj)
+}
+
+// writeTree writes the trees of the given files, keyed by slash separated paths, and returns the root one
+func (r *gitRepo) writeTree(files map[string]plumbing.Hash) (plumbing.Hash, error) {
+	tree := &object.Tree{}
+	dirs := make(map[string]map[string]plumbing.Hash)
+	for path, hash := range files {
+		if i := strings.Index(path, "/"); i > 0 {
+			dir := path[:i]
+			if dirs[dir] == nil {
+				dirs[dir] = make(map[string]plumbing.Hash)
+			}
+			dirs[dir][path[i+1:]] = hash
+			continue
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: path, Mode: gitFileMode, Hash: hash})
+	}
+	for dir, dirFiles := range dirs {
+		hash, err := r.writeTree(dirFiles)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: dir, Mode: gitDirMode, Hash: hash})
+	}
 
-		msg.WriteString(err.Error())
-		msg.WriteByte('\n')
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			msg.Write(exitErr.Stderr)
-			msg.WriteByte('\n')
+	// git sorts tree entries as if directory names had a trailing slash
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == gitDirMode {
+			return e.Name + "/"
 		}
+		return e.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return sortKey(tree.Entries[i]) < sortKey(tree.Entries[j]) })
 
-		return "", fmt.Errorf("git error: %s", msg.String())
+	obj := r.storage.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
 	}
+	return r.storage.SetEncodedObject(obj)
+}
 
-	return string(out), nil
+func (r *gitRepo) writeCommit(tree, parent plumbing.Hash, author, committer object.Signature, message string) (plumbing.Hash, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "tree %s\n", tree)
+	if !parent.IsZero() {
+		fmt.Fprintf(&b, "parent %s\n", parent)
+	}
+	b.WriteString("author ")
+	if err := author.Encode(&b); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	b.WriteString("\ncommitter ")
+	if err := committer.Encode(&b); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	fmt.Fprintf(&b, "\n\n%s", message)
+
+	return r.writeObject(plumbing.CommitObject, b.Bytes())
+}
+
+// removeUnreachableObjects removes the loose objects not reachable from the history
+// of the current revision (objects packed by a git binary are left untouched),
+// along with the reflogs a git binary may have written, which reference them
+func (r *gitRepo) removeUnreachableObjects() error {
+	commits, err := r.history()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(r.path, ".git", "logs")); err != nil {
+		return err
+	}
+
+	reachable := make(map[string]bool)
+	var walkTree func(*object.Tree) error
+	walkTree = func(tree *object.Tree) error {
+		if reachable[tree.Hash.String()] {
+			return nil
+		}
+		reachable[tree.Hash.String()] = true
+		for _, entry := range tree.Entries {
+			if entry.Mode.IsDir() {
+				sub, err := r.repo.Tree(entry.Hash)
+				if err != nil {
+					return err
+				}
+				if err := walkTree(sub); err != nil {
+					return err
+				}
+			} else {
+				reachable[entry.Hash.String()] = true
+			}
+		}
+		return nil
+	}
+	for _, commit := range commits {
+		reachable[commit.Hash.String()] = true
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		if err := walkTree(tree); err != nil {
+			return err
+		}
+	}
+
+	objectsDir := filepath.Join(r.path, ".git", "objects")
+	loose, err := filepath.Glob(filepath.Join(objectsDir, "[0-9a-f][0-9a-f]", "*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range loose {
+		hash := filepath.Base(filepath.Dir(path)) + filepath.Base(path)
+		if len(hash) == 40 && !reachable[hash] {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
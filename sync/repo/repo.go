@@ -17,28 +17,43 @@ limitations under the License.
 package repo
 
 import (
+	"errors"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/wallix/awless/encrypt"
 	"github.com/wallix/awless/graph"
 
 	git "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	osfs "srcd.works/go-billy.v1/os"
 )
 
+const graphFileExt = ".triples"
+
 type Rev struct {
 	Id   string
 	Date time.Time
 
-	Infra  *graph.Graph
-	Access *graph.Graph
+	// Graphs are the graphs of the services synced at this revision, by service name
+	Graphs map[string]*graph.Graph
+}
+
+// Graph returns the graph of the given service at this revision,
+// or an empty graph if the service was not synced
+func (r *Rev) Graph(serviceName string) *graph.Graph {
+	if g, ok := r.Graphs[serviceName]; ok {
+		return g
+	}
+	return graph.NewGraph()
 }
 
 func (r *Rev) DateString() string {
@@ -49,18 +64,16 @@ type Repo interface {
 	Commit(files ...string) error
 	List() ([]*Rev, error)
 	LoadRev(version string) (*Rev, error)
+	Prune(RetentionPolicy) (int, error)
+	IsAncestor(ancestor, rev string) (bool, error)
 }
 
-type noRevisionRepo struct{}
-
-func (*noRevisionRepo) Commit(files ...string) error         { return nil }
-func (*noRevisionRepo) LoadRev(version string) (*Rev, error) { return &Rev{}, nil }
-func (*noRevisionRepo) List() ([]*Rev, error)                { return nil, nil }
-
+// gitRepo stores the revisions in a git repository written with go-git,
+// with no need of a git binary
 type gitRepo struct {
-	repo  *git.Repository
-	files []string
-	path  string
+	repo    *git.Repository
+	storage *filesystem.Storage
+	path    string
 }
 
 func Dir() string {
@@ -71,51 +84,39 @@ func New() (Repo, error) {
 	dir := Dir()
 	os.MkdirAll(dir, 0700)
 
-	if IsGitInstalled() {
-		return newGitRepo(dir)
-	} else {
-		return &noRevisionRepo{}, nil
-	}
-}
-
-func IsGitInstalled() bool {
-	_, err := exec.LookPath("git")
-	return err == nil
+	return newGitRepo(dir)
 }
 
 func newGitRepo(path string) (Repo, error) {
-	if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
-		if _, err := newGit(path).run("init"); err != nil {
+	storage, err := filesystem.NewStorage(osfs.New(filepath.Join(path, ".git")))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := storage.Reference(plumbing.HEAD); err == plumbing.ErrReferenceNotFound {
+		if err := storage.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, masterRef)); err != nil {
 			return nil, err
 		}
+	} else if err != nil {
+		return nil, err
 	}
 
-	repo, err := git.NewFilesystemRepository(filepath.Join(path, ".git"))
-	return &gitRepo{repo: repo, path: path}, err
+	repo, err := git.NewRepository(storage)
+	return &gitRepo{repo: repo, storage: storage, path: path}, err
 }
 
+// List returns the revisions of the history of the current revision sorted by date
 func (r *gitRepo) List() ([]*Rev, error) {
 	var all []*Rev
 
-	iter, err := r.repo.Commits()
+	commits, err := r.history()
 	if err != nil {
 		return all, err
 	}
-	defer iter.Close()
-
-	for {
-		commit, err := iter.Next()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			panic(fmt.Sprintf("error listing repo revisions: %s", err))
-		}
-
-		all = append(all, &Rev{Id: commit.Hash.String(), Date: commit.Committer.When})
+	for i := len(commits) - 1; i >= 0; i-- {
+		all = append(all, &Rev{Id: commits[i].Hash.String(), Date: commits[i].Committer.When})
 	}
 
-	sort.Sort(revsByDate(all))
+	sort.Stable(revsByDate(all)) // revisions within the same second keep their history order
 
 	return all, nil
 }
@@ -147,14 +148,21 @@ func (r *gitRepo) LoadRev(version string) (*Rev, error) {
 
 	rev.Date = commit.Committer.When
 
-	rev.Infra = graph.NewGraph()
-	rev.Access = graph.NewGraph()
-
-	if err := unmarshalIntoGraph(rev.Infra, commit, "infra.triples"); err != nil {
+	tree, err := commit.Tree()
+	if err != nil {
 		return rev, err
 	}
-	if err := unmarshalIntoGraph(rev.Access, commit, "access.triples"); err != nil {
-		return rev, err
+
+	rev.Graphs = make(map[string]*graph.Graph)
+	for _, entry := range tree.Entries {
+		if entry.Mode.IsDir() || filepath.Ext(entry.Name) != graphFileExt {
+			continue
+		}
+		g := graph.NewGraph()
+		if err := unmarshalIntoGraph(g, commit, entry.Name); err != nil {
+			return rev, err
+		}
+		rev.Graphs[strings.TrimSuffix(entry.Name, graphFileExt)] = g
 	}
 
 	return rev, nil
@@ -169,42 +177,138 @@ func unmarshalIntoGraph(g *graph.Graph, commit *object.Commit, filename string)
 		if err != nil {
 			return err
 		}
-		g.Unmarshal([]byte(contents))
+		decrypted, err := encrypt.Open([]byte(contents))
+		if err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		g.Unmarshal(decrypted)
 	}
 	return nil
 }
 
+// Commit records the given files, relative to the repository directory, in a new revision
+// along with the files of the current revision. Files no longer existing are removed.
+// No revision is recorded if the files are unchanged.
 func (r *gitRepo) Commit(files ...string) error {
-	for _, path := range files {
-		r.files = append(r.files, path)
+	if len(files) == 0 {
+		return nil
+	}
+
+	head, err := r.head()
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]plumbing.Hash)
+	var parent plumbing.Hash
+	if head != nil {
+		parent = head.Hash
+		tree, err := head.Tree()
+		if err != nil {
+			return err
+		}
+		if err := tree.Files().ForEach(func(f *object.File) error {
+			entries[f.Name] = f.Hash
+			return nil
+		}); err != nil {
+			return err
+		}
 	}
 
-	for _, path := range r.files {
-		if _, err := newGit(r.path).run("add", path); err != nil {
+	for _, file := range files {
+		content, err := ioutil.ReadFile(filepath.Join(r.path, file))
+		if os.IsNotExist(err) {
+			delete(entries, filepath.ToSlash(file))
+			continue
+		} else if err != nil {
+			return err
+		}
+		if entries[filepath.ToSlash(file)], err = r.writeBlob(content); err != nil {
 			return err
 		}
 	}
 
-	if hasChanges, err := r.hasChanges(); err != nil {
+	tree, err := r.writeTree(entries)
+	if err != nil {
 		return err
-	} else if !hasChanges {
-		return nil
+	}
+	if head != nil {
+		if headTree, err := head.Tree(); err == nil && headTree.Hash == tree {
+			return nil
+		}
 	}
 
-	_, err := newGit(r.path).run(
-		append(awlessCommitter, "commit", "-m", fmt.Sprintf("syncing %s", strings.Join(files, ", ")))...,
-	)
+	now := time.Now()
+	sig := object.Signature{Name: awlessCommitterName, Email: awlessCommitterEmail, When: now}
+	commit, err := r.writeCommit(tree, parent, sig, sig, fmt.Sprintf("syncing %s", strings.Join(files, ", ")))
+	if err != nil {
+		return err
+	}
 
-	return err
+	return r.setHead(commit)
 }
 
-var awlessCommitter = []string{"-c", "user.name='awless'", "-c", "user.email='git@awless.io'"}
+const (
+	awlessCommitterName  = "awless"
+	awlessCommitterEmail = "git@awless.io"
+)
+
+var revisionIdRegex = regexp.MustCompile("^[0-9a-f]{4,40}$")
 
-func (r *gitRepo) hasChanges() (bool, error) {
-	stdout, err := newGit(r.path).run("status", "--porcelain")
+// FindRev returns among the given revisions the one whose id starts with the given reference,
+// or the last revision at or before the given date (ex: 2017-06-20, 2017-06-20T15:04, RFC3339)
+// or duration ago (ex: 36h, 2d, 1w). A date without time stands for the end of that day.
+func FindRev(revs []*Rev, at string, now time.Time) (*Rev, error) {
+	if len(revs) == 0 {
+		return nil, errors.New("no sync revision found")
+	}
+
+	if revisionIdRegex.MatchString(at) {
+		var found []*Rev
+		for _, rev := range revs {
+			if strings.HasPrefix(rev.Id, at) {
+				found = append(found, rev)
+			}
+		}
+		switch len(found) {
+		case 0:
+		case 1:
+			return found[0], nil
+		default:
+			return nil, fmt.Errorf("ambiguous revision '%s': %d revisions found", at, len(found))
+		}
+	}
+
+	date, err := parseRevDate(at, now)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	sorted := make([]*Rev, len(revs))
+	copy(sorted, revs)
+	sort.Sort(revsByDate(sorted))
+
+	var found *Rev
+	for _, rev := range sorted {
+		if rev.Date.After(date) {
+			break
+		}
+		found = rev
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no sync revision at %s: first revision on %s", date.Format(time.RFC3339), sorted[0].DateString())
 	}
+	return found, nil
+}
 
-	return !(strings.TrimSpace(stdout) == ""), nil
+// parseRevDate parses the date or age of a revision (see graph.ParseTime), a date without time standing for the end of that day
+func parseRevDate(s string, now time.Time) (time.Time, error) {
+	t, err := graph.ParseTime(s, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid revision or date '%s'", s)
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return t, nil
 }
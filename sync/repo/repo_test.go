@@ -17,11 +17,84 @@ limitations under the License.
 package repo
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
 )
 
+func TestLoadRevGraphsOfAllServices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "awless-repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newGitRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graphs := map[string]*graph.Graph{"infra": graph.NewGraph(), "dns": graph.NewGraph(), filepath.Join("us-east-1", "infra"): graph.NewGraph()}
+	graphs["infra"].AddResource(resourcetest.Instance("inst_1").Build())
+	graphs["dns"].AddResource(resourcetest.Zone("zone_1").Build())
+	graphs[filepath.Join("us-east-1", "infra")].AddResource(resourcetest.Instance("inst_2").Build())
+	os.MkdirAll(filepath.Join(dir, "us-east-1"), 0700)
+
+	var files []string
+	for name, g := range graphs {
+		b, err := g.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := name + graphFileExt
+		if err := ioutil.WriteFile(filepath.Join(dir, file), b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	if err := r.Commit(files...); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(revs), 1; got != want {
+		t.Fatalf("got %d revisions, want %d", got, want)
+	}
+	rev, err := r.LoadRev(revs[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for name := range rev.Graphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if got, want := names, []string{"dns", "infra"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, err := rev.Graph("dns").GetResource("zone", "zone_1"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := rev.Graph("infra").GetAllResources("instance"); len(all) != 1 || all[0].Id() != "inst_1" {
+		t.Fatalf("got %v, want only inst_1", all)
+	}
+	if all, _ := rev.Graph("storage").GetAllResources("bucket"); len(all) != 0 {
+		t.Fatalf("got %v, want no resources for unsynced service", all)
+	}
+}
+
 func TestReduceToLastRevOfEachDay(t *testing.T) {
 	revs := []*Rev{
 		{Id: "1", Date: mustParse("2017-01-18 15:05")},
@@ -58,3 +131,49 @@ func mustParse(s string) time.Time {
 	}
 	return t
 }
+
+func TestFindRev(t *testing.T) {
+	revs := []*Rev{
+		{Id: "1f2e3d4c5b6a", Date: mustParse("2017-01-18 15:05")},
+		{Id: "1f2e9999aaaa", Date: mustParse("2017-01-17 21:05")},
+		{Id: "abcdef012345", Date: mustParse("2017-01-19 09:05")},
+	}
+	now := mustParse("2017-01-20 12:00")
+
+	tcases := []struct {
+		at, expect, err string
+	}{
+		{at: "abcd", expect: "abcdef012345"},
+		{at: "1f2e3", expect: "1f2e3d4c5b6a"},
+		{at: "1f2e", err: "ambiguous"},
+		{at: "2017-01-18", expect: "1f2e3d4c5b6a"},
+		{at: "2017-01-18T15:00", expect: "1f2e9999aaaa"},
+		{at: "2017-01-18 15:05", expect: "1f2e3d4c5b6a"},
+		{at: "2017-01-25", expect: "abcdef012345"},
+		{at: "1d", expect: "abcdef012345"},
+		{at: "2d", expect: "1f2e9999aaaa"},
+		{at: "36h", expect: "1f2e3d4c5b6a"},
+		{at: "60h", expect: "1f2e9999aaaa"},
+		{at: "2017-01-16", err: "no sync revision"},
+		{at: "last tuesday", err: "invalid"},
+	}
+	for _, tcase := range tcases {
+		rev, err := FindRev(revs, tcase.at, now)
+		if tcase.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tcase.err) {
+				t.Fatalf("%s: got %v, want error containing '%s'", tcase.at, err, tcase.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tcase.at, err)
+		}
+		if got, want := rev.Id, tcase.expect; got != want {
+			t.Fatalf("%s: got %s, want %s", tcase.at, got, want)
+		}
+	}
+
+	if _, err := FindRev(nil, "2017-01-18", now); err == nil {
+		t.Fatal("expected error without revisions")
+	}
+}
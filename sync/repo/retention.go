@@ -0,0 +1,102 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"sort"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// RetentionPolicy caps the revisions kept to limit disk usage. Zero values mean no limit.
+// The current revision is always kept.
+type RetentionPolicy struct {
+	// MaxAge is the age of the revisions removed
+	MaxAge time.Duration
+	// MaxRevisions is the number of most recent revisions kept
+	MaxRevisions int
+	// DailyAfter is the age of the revisions reduced to the last one of each day
+	DailyAfter time.Duration
+}
+
+// keep returns the revisions kept by the policy sorted by date
+func (p RetentionPolicy) keep(revs []*Rev, now time.Time) []*Rev {
+	if len(revs) == 0 {
+		return nil
+	}
+	sorted := make([]*Rev, len(revs))
+	copy(sorted, revs)
+	sort.Stable(revsByDate(sorted))
+
+	var daily, recent []*Rev
+	for _, rev := range sorted {
+		age := now.Sub(rev.Date)
+		switch {
+		case p.MaxAge > 0 && age > p.MaxAge:
+		case p.DailyAfter > 0 && age > p.DailyAfter:
+			daily = append(daily, rev)
+		default:
+			recent = append(recent, rev)
+		}
+	}
+	daily = reduceToLastRevOfEachDay(daily)
+	sort.Sort(revsByDate(daily))
+
+	kept := append(daily, recent...)
+	if p.MaxRevisions > 0 && len(kept) > p.MaxRevisions {
+		kept = kept[len(kept)-p.MaxRevisions:]
+	}
+	if current := sorted[len(sorted)-1]; len(kept) == 0 || kept[len(kept)-1] != current {
+		kept = append(kept, current)
+	}
+	return kept
+}
+
+// Prune removes the revisions not kept by the policy, rewriting the history of the kept ones,
+// along with the files only referenced by the removed ones. It returns the number of revisions removed.
+// The ids of the revisions following the first one removed change.
+func (r *gitRepo) Prune(policy RetentionPolicy) (int, error) {
+	revs, err := r.List()
+	if err != nil {
+		return 0, err
+	}
+	kept := policy.keep(revs, time.Now())
+	if len(kept) == len(revs) {
+		return 0, nil
+	}
+
+	var parent plumbing.Hash
+	for _, rev := range kept {
+		commit, err := r.repo.Commit(plumbing.NewHash(rev.Id))
+		if err != nil {
+			return 0, err
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return 0, err
+		}
+		if parent, err = r.writeCommit(tree.Hash, parent, commit.Author, commit.Committer, commit.Message); err != nil {
+			return 0, err
+		}
+	}
+	if err := r.setHead(parent); err != nil {
+		return 0, err
+	}
+
+	return len(revs) - len(kept), r.removeUnreachableObjects()
+}
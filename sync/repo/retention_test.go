@@ -0,0 +1,151 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestRetentionPolicyKeep(t *testing.T) {
+	now := mustParse("2017-01-20 12:00")
+	revs := []*Rev{
+		{Id: "1", Date: mustParse("2017-01-01 10:00")},
+		{Id: "2", Date: mustParse("2017-01-10 10:00")},
+		{Id: "3", Date: mustParse("2017-01-10 18:00")},
+		{Id: "4", Date: mustParse("2017-01-11 09:00")},
+		{Id: "5", Date: mustParse("2017-01-19 10:00")},
+		{Id: "6", Date: mustParse("2017-01-19 11:00")},
+		{Id: "7", Date: mustParse("2017-01-20 11:00")},
+	}
+	tcases := []struct {
+		policy RetentionPolicy
+		expect []string
+	}{
+		{policy: RetentionPolicy{}, expect: []string{"1", "2", "3", "4", "5", "6", "7"}},
+		{policy: RetentionPolicy{MaxAge: 15 * 24 * time.Hour}, expect: []string{"2", "3", "4", "5", "6", "7"}},
+		{policy: RetentionPolicy{MaxRevisions: 2}, expect: []string{"6", "7"}},
+		{policy: RetentionPolicy{DailyAfter: 7 * 24 * time.Hour}, expect: []string{"1", "3", "4", "5", "6", "7"}},
+		{policy: RetentionPolicy{DailyAfter: 7 * 24 * time.Hour, MaxAge: 15 * 24 * time.Hour, MaxRevisions: 4}, expect: []string{"4", "5", "6", "7"}},
+		{policy: RetentionPolicy{MaxAge: time.Minute}, expect: []string{"7"}},
+	}
+	for i, tcase := range tcases {
+		var ids []string
+		for _, rev := range tcase.policy.keep(revs, now) {
+			ids = append(ids, rev.Id)
+		}
+		if got, want := ids, tcase.expect; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d: got %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestCommitAndPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "awless-repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newGitRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, "us-east-1"), 0700)
+
+	write := func(file, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []string{"infra.nt", filepath.Join("us-east-1", "infra.nt")}
+	for i, content := range []string{"first", "second", "second", "third"} {
+		write(files[0], content)
+		write(files[1], content+" in us-east-1")
+		if i == 3 {
+			os.Remove(filepath.Join(dir, files[1]))
+		}
+		if err := r.Commit(files...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revs, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(revs), 3; got != want {
+		t.Fatalf("got %d revisions, want %d", got, want)
+	}
+	fileContent := func(rev *Rev, file string) string {
+		commit, err := r.(*gitRepo).repo.Commit(plumbing.NewHash(rev.Id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := commit.File(filepath.ToSlash(file))
+		if err != nil {
+			return ""
+		}
+		content, err := f.Contents()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	if got, want := fileContent(revs[1], files[1]), "second in us-east-1"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got, want := fileContent(revs[2], files[0]), "third"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got, want := fileContent(revs[2], files[1]), ""; got != want {
+		t.Fatalf("got %q, want removed file", got)
+	}
+
+	pruned, err := r.Prune(RetentionPolicy{MaxRevisions: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pruned, 1; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+	revs, err = r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(revs), 2; got != want {
+		t.Fatalf("got %d revisions, want %d", got, want)
+	}
+	if got, want := fileContent(revs[0], files[0]), "second"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got, want := fileContent(revs[1], files[0]), "third"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, err := r.(*gitRepo).storage.EncodedObject(plumbing.BlobObject, plumbing.ComputeHash(plumbing.BlobObject, []byte("first"))); err == nil {
+		t.Fatal("expected blob only referenced by pruned revision to be removed")
+	}
+	if _, err := r.(*gitRepo).storage.EncodedObject(plumbing.BlobObject, plumbing.ComputeHash(plumbing.BlobObject, []byte("second"))); err != nil {
+		t.Fatal(err)
+	}
+}
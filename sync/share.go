@@ -0,0 +1,273 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/sync/remote"
+	"github.com/wallix/awless/sync/repo"
+)
+
+const (
+	remoteGraphsPrefix    = "graphs/"
+	remoteRevisionsPrefix = "revisions/"
+	revisionsObjectsDir   = "objects"
+	revisionsHeadRef      = "refs/heads/master"
+	remoteScopeName       = "scope"
+)
+
+// Push uploads the local graphs and their revisions to a backend shared by a team,
+// the revisions already uploaded being skipped. It returns the number of files uploaded.
+func (s *syncer) Push(b remote.Backend) (int, error) {
+	var count int
+	err := withGraphsLock(false, func() error {
+		gitDir := filepath.Join(repo.Dir(), ".git")
+
+		uploaded, err := b.List(remoteRevisionsPrefix + revisionsObjectsDir + "/")
+		if err != nil {
+			return err
+		}
+		remoteObjects := make(map[string]bool)
+		for _, name := range uploaded {
+			remoteObjects[name] = true
+		}
+		objects, err := listFiles(filepath.Join(gitDir, revisionsObjectsDir), func(name string) bool { return !strings.HasPrefix(name, "info/") })
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			name := remoteRevisionsPrefix + path.Join(revisionsObjectsDir, object)
+			if remoteObjects[name] {
+				continue
+			}
+			if err := pushFile(b, name, filepath.Join(gitDir, revisionsObjectsDir, object)); err != nil {
+				return err
+			}
+			count++
+		}
+
+		graphs, err := listFiles(repo.Dir(), isGraphFile)
+		if err != nil {
+			return err
+		}
+		for _, graph := range graphs {
+			if err := pushFile(b, remoteGraphsPrefix+graph, filepath.Join(repo.Dir(), graph)); err != nil {
+				return err
+			}
+			count++
+		}
+
+		if err := b.Put(remoteScopeName, []byte(s.scope)); err != nil {
+			return err
+		}
+
+		// the reference of the current revision is pushed last, once all its objects are
+		if err := pushFile(b, remoteRevisionsPrefix+revisionsHeadRef, filepath.Join(gitDir, revisionsHeadRef)); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// Pull downloads the graphs and revisions of a backend shared by a team, replacing
+// the local ones with no API calls. It returns the number of files downloaded.
+// Graphs pushed from another scope (profile and region) are refused before anything
+// is written, as are the revisions not following the local ones, unless forced to discard them.
+// The resource types of the given services are considered synced at the date of the pulled
+// revision, so that incremental syncs only fetch the ones older than their TTL.
+func (s *syncer) Pull(b remote.Backend, force bool, services ...cloud.Service) (int, error) {
+	scope, err := b.Get(remoteScopeName)
+	if err == remote.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if string(scope) != s.scope {
+		return 0, fmt.Errorf("graphs pushed from %s, not from the current profile and region %s", scope, s.scope)
+	}
+
+	var count int
+	err = withGraphsLock(true, func() error {
+		gitDir := filepath.Join(repo.Dir(), ".git")
+
+		objects, err := b.List(remoteRevisionsPrefix + revisionsObjectsDir + "/")
+		if err != nil {
+			return err
+		}
+		for _, name := range objects {
+			local := localPath(gitDir, strings.TrimPrefix(name, remoteRevisionsPrefix))
+			if fileExists(local) {
+				continue
+			}
+			if err := pullFile(b, name, local); err != nil {
+				return err
+			}
+			count++
+		}
+
+		head, err := b.Get(remoteRevisionsPrefix + revisionsHeadRef)
+		if err != nil && err != remote.ErrNotFound {
+			return err
+		}
+		if head != nil && !force {
+			if err := s.checkFastForward(filepath.Join(gitDir, revisionsHeadRef), string(head)); err != nil {
+				return err
+			}
+		}
+
+		graphs, err := b.List(remoteGraphsPrefix)
+		if err != nil {
+			return err
+		}
+		pulled := make(map[string]bool)
+		for _, name := range graphs {
+			rel := strings.TrimPrefix(name, remoteGraphsPrefix)
+			if !isGraphFile(rel) {
+				continue
+			}
+			if err := pullFile(b, name, localPath(repo.Dir(), rel)); err != nil {
+				return err
+			}
+			pulled[localPath(repo.Dir(), rel)] = true
+			count++
+		}
+		locals, err := listFiles(repo.Dir(), isGraphFile)
+		if err != nil {
+			return err
+		}
+		for _, local := range locals {
+			if path := localPath(repo.Dir(), local); !pulled[path] {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+		}
+
+		if head == nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(gitDir, revisionsHeadRef)), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(gitDir, revisionsHeadRef), head, 0600); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil || count == 0 {
+		return count, err
+	}
+
+	revs, err := s.List()
+	if err != nil || len(revs) == 0 {
+		return count, err
+	}
+	syncedTypes := make(map[string][]string)
+	for _, srv := range services {
+		if fileExists(localGraphPath(srv.Name())) {
+			syncedTypes[srv.Name()] = srv.ResourceTypes()
+		}
+	}
+	return count, s.setLastSyncs(syncedTypes, revs[len(revs)-1].Date)
+}
+
+// checkFastForward returns an error if the local revisions, whose reference is at the given path,
+// are not in the history of the remote revision: they would be discarded by the pull
+func (s *syncer) checkFastForward(localRef, head string) error {
+	content, err := ioutil.ReadFile(localRef)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	local, pulled := strings.TrimSpace(string(content)), strings.TrimSpace(head)
+	if local == pulled {
+		return nil
+	}
+	ok, err := s.IsAncestor(local, pulled)
+	if err != nil {
+		return fmt.Errorf("remote revision %s: %s", pulled, err)
+	}
+	if !ok {
+		return fmt.Errorf("local revision %s not in the history of the remote inventory: force the pull to discard the local revisions", local)
+	}
+	return nil
+}
+
+func isGraphFile(name string) bool {
+	return strings.HasSuffix(name, fileExt) && !strings.HasPrefix(name, ".git/")
+}
+
+// listFiles returns the slash separated paths, relative to the given directory, of the files matching
+func listFiles(dir string, match func(string) bool) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); match(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// localPath returns the path of a remote file in the given directory, never outside of it
+func localPath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func pushFile(b remote.Backend, name, local string) error {
+	content, err := ioutil.ReadFile(local)
+	if err != nil {
+		return err
+	}
+	return b.Put(name, content)
+}
+
+func pullFile(b remote.Backend, name, local string) error {
+	content, err := b.Get(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(local), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(local, content, 0600)
+}
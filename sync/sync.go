@@ -17,34 +17,48 @@ limitations under the License.
 package sync
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	gosync "sync"
 	"time"
 
 	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/encrypt"
 	"github.com/wallix/awless/graph"
 	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync/remote"
 	"github.com/wallix/awless/sync/repo"
 )
 
-const fileExt = ".triples"
+const (
+	fileExt     = ".triples"
+	accountsDir = "accounts"
+)
 
 var DefaultSyncer Syncer
 
 type Syncer interface {
 	repo.Repo
 	Sync(...cloud.Service) (map[string]*graph.Graph, error)
+	SyncRegions(map[string][]cloud.Service) (map[string]map[string]*graph.Graph, error)
+	SyncAccounts(map[string][]cloud.Service) (map[string]map[string]*graph.Graph, error)
+	Push(remote.Backend) (int, error)
+	Pull(remote.Backend, bool, ...cloud.Service) (int, error)
 }
 
 type syncer struct {
 	repo.Repo
-	logger *logger.Logger
+	logger    *logger.Logger
+	scope     string
+	ttl       TTLFunc
+	retention repo.RetentionPolicy
 }
 
 func NewSyncer(l ...*logger.Logger) Syncer {
@@ -64,7 +78,139 @@ func NewSyncer(l ...*logger.Logger) Syncer {
 	return s
 }
 
+// NewIncrementalSyncer returns a syncer whose Sync fetches only the resource types
+// of a service not synced in the given scope (ex: profile and region) for longer than their TTL.
+// Services whose types are all stale are fetched entirely, as with NewSyncer.
+// Revisions not kept by the retention policy are pruned after each commit.
+func NewIncrementalSyncer(scope string, ttl TTLFunc, retention repo.RetentionPolicy, l ...*logger.Logger) Syncer {
+	s := NewSyncer(l...).(*syncer)
+	s.scope = scope
+	s.ttl = ttl
+	s.retention = retention
+	return s
+}
+
 func (s *syncer) Sync(services ...cloud.Service) (map[string]*graph.Graph, error) {
+	start := time.Now()
+	lastSyncs := s.lastSyncs(services)
+
+	var fullServices []cloud.Service
+	staleTypes := make(map[cloud.Service][]string)
+	localGraphs := make(map[string]*graph.Graph)
+	for _, srv := range services {
+		stale, all := s.staleTypes(srv, lastSyncs[srv.Name()], start)
+		switch {
+		case srv.IsSyncDisabled() || all:
+			fullServices = append(fullServices, srv)
+		case len(stale) == 0:
+			s.logger.Verbosef("sync: %s service fresh, not fetched", srv.Name())
+		default:
+			local, err := readGraph(localGraphPath(srv.Name()))
+			if err != nil {
+				s.logger.Warningf("sync: cannot load local %s graph, fetching the whole service: %s", srv.Name(), err)
+				fullServices = append(fullServices, srv)
+				continue
+			}
+			localGraphs[srv.Name()] = local
+			staleTypes[srv] = stale
+		}
+	}
+
+	graphs, allErrors := s.fetch(fullServices)
+	synced := make(map[string][]string)
+	for _, srv := range fullServices {
+		if _, ok := graphs[srv.Name()]; ok {
+			synced[srv.Name()] = srv.ResourceTypes()
+		}
+	}
+
+	merged, mergedTypes, errs := s.fetchStaleTypes(staleTypes, localGraphs)
+	allErrors = append(allErrors, errs...)
+	for name, g := range merged {
+		graphs[name] = g
+		synced[name] = mergedTypes[name]
+	}
+
+	allErrors = append(allErrors, s.writeAndCommit(map[string]map[string]*graph.Graph{"": graphs})...)
+
+	if err := s.setLastSyncs(synced, start); err != nil {
+		allErrors = append(allErrors, fmt.Errorf("saving sync times: %s", err))
+	}
+
+	return graphs, concatErrors(allErrors)
+}
+
+// SyncRegions fetches concurrently the services of each region and stores
+// their graphs partitioned by region (see LoadRegionGraph), apart from the
+// graphs of the configured region written by Sync. It returns the fetched graphs per region.
+func (s *syncer) SyncRegions(servicesPerRegion map[string][]cloud.Service) (map[string]map[string]*graph.Graph, error) {
+	return s.syncPartitions(servicesPerRegion, func(region string) string { return region }, nil)
+}
+
+// SyncAccounts fetches concurrently the services of each account (i.e. of
+// the profile of each account), tags their resources with the account id and
+// stores their graphs in account-scoped directories (see LoadAccountGraphs).
+// It returns the fetched graphs per account.
+func (s *syncer) SyncAccounts(servicesPerAccount map[string][]cloud.Service) (map[string]map[string]*graph.Graph, error) {
+	return s.syncPartitions(servicesPerAccount, accountDir, func(account string, srv cloud.Service, g *graph.Graph) error {
+		resources, err := g.GetAllResources(srv.ResourceTypes()...)
+		if err != nil {
+			return err
+		}
+		for _, res := range resources {
+			// only the account is added: marshaling again nested properties (ex: firewall rules) would duplicate them
+			tagged := graph.InitResource(res.Type(), res.Id())
+			tagged.Properties["Account"] = []string{account}
+			if err := g.AddResource(tagged); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// syncPartitions fetches concurrently the services of each partition, and writes
+// their graphs, once tagged if needed, in the directory of the partition
+func (s *syncer) syncPartitions(servicesPerPartition map[string][]cloud.Service, dirOf func(string) string, tag func(string, cloud.Service, *graph.Graph) error) (map[string]map[string]*graph.Graph, error) {
+	graphsPerPartition := make(map[string]map[string]*graph.Graph)
+	var allErrors []error
+	var workers gosync.WaitGroup
+	var mu gosync.Mutex
+
+	for partition, services := range servicesPerPartition {
+		workers.Add(1)
+		go func(partition string, services []cloud.Service) {
+			defer workers.Done()
+			graphs, errs := s.fetch(services)
+			if tag != nil {
+				for _, srv := range services {
+					if g, ok := graphs[srv.Name()]; ok {
+						if err := tag(partition, srv, g); err != nil {
+							errs = append(errs, fmt.Errorf("tagging %s: %s", srv.Name(), err))
+						}
+					}
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			graphsPerPartition[partition] = graphs
+			for _, err := range errs {
+				allErrors = append(allErrors, fmt.Errorf("%s: %s", partition, err))
+			}
+		}(partition, services)
+	}
+	workers.Wait()
+
+	graphsPerDir := make(map[string]map[string]*graph.Graph)
+	for partition, graphs := range graphsPerPartition {
+		graphsPerDir[dirOf(partition)] = graphs
+	}
+	allErrors = append(allErrors, s.writeAndCommit(graphsPerDir)...)
+
+	return graphsPerPartition, concatErrors(allErrors)
+}
+
+func (s *syncer) fetch(services []cloud.Service) (map[string]*graph.Graph, []error) {
 	graphs := make(map[string]*graph.Graph)
 	var workers gosync.WaitGroup
 
@@ -123,13 +269,59 @@ Loop:
 		}
 	}
 
+	return graphs, allErrors
+}
+
+// writeAndCommit writes and commits the graphs of each directory (see writeGraphs)
+// holding exclusively the lock on the local graphs
+func (s *syncer) writeAndCommit(graphsPerDir map[string]map[string]*graph.Graph) []error {
+	var dirs, filenames []string
+	for dir := range graphsPerDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var allErrors []error
+	err := withGraphsLock(true, func() error {
+		for _, dir := range dirs {
+			names, errs := writeGraphs(dir, graphsPerDir[dir])
+			filenames = append(filenames, names...)
+			allErrors = append(allErrors, errs...)
+		}
+		if err := s.Commit(filenames...); err != nil {
+			return fmt.Errorf("commit %s: %s", strings.Join(filenames, ", "), err)
+		}
+		pruned, err := s.Prune(s.retention)
+		if err != nil {
+			return fmt.Errorf("pruning revisions: %s", err)
+		}
+		if pruned > 0 {
+			s.logger.Verbosef("sync: pruned %d revisions", pruned)
+		}
+		return nil
+	})
+	if err != nil {
+		allErrors = append(allErrors, err)
+	}
+	return allErrors
+}
+
+// writeGraphs writes the graphs of the services into the given region directory
+// (the root directory if empty) and returns their filenames relative to repo.Dir()
+func writeGraphs(region string, graphs map[string]*graph.Graph) ([]string, []error) {
 	var filenames []string
+	var allErrors []error
+
+	if err := os.MkdirAll(filepath.Join(repo.Dir(), region), 0700); err != nil {
+		return filenames, append(allErrors, err)
+	}
 
 	for name, g := range graphs {
-		filename := fmt.Sprintf("%s%s", name, fileExt)
-		tofile, err := g.Marshal()
+		filename := filepath.Join(region, fmt.Sprintf("%s%s", name, fileExt))
+		tofile, err := marshalGraph(g)
 		if err != nil {
 			allErrors = append(allErrors, fmt.Errorf("marshal %s: %s", filename, err))
+			continue
 		}
 		filepath := filepath.Join(repo.Dir(), filename)
 		if err = ioutil.WriteFile(filepath, tofile, 0600); err != nil {
@@ -138,11 +330,7 @@ Loop:
 		filenames = append(filenames, filename)
 	}
 
-	if err := s.Commit(filenames...); err != nil {
-		allErrors = append(allErrors, fmt.Errorf("commit %s: %s", strings.Join(filenames, ", "), err))
-	}
-
-	return graphs, concatErrors(allErrors)
+	return filenames, allErrors
 }
 
 func concatErrors(errs []error) error {
@@ -158,30 +346,205 @@ func concatErrors(errs []error) error {
 	return errors.New(strings.Join(lines, "\n"))
 }
 
-func LoadCurrentLocalGraph(serviceName string) *graph.Graph {
-	path := filepath.Join(repo.Dir(), fmt.Sprintf("%s%s", serviceName, fileExt))
-	g, err := graph.NewGraphFromFile(path)
+// LoadCurrentLocalGraph returns the local graph of a service, or an empty graph if it has not been synced.
+// It fails if the graph cannot be read, as when it has been encrypted and no key is given (see encrypt.ErrNoKey).
+func LoadCurrentLocalGraph(serviceName string) (*graph.Graph, error) {
+	return loadGraphFile(localGraphPath(serviceName))
+}
+
+func localGraphPath(serviceName string) string {
+	return filepath.Join(repo.Dir(), fmt.Sprintf("%s%s", serviceName, fileExt))
+}
+
+// LoadRegionGraph returns the local graph of a service synced for the given region
+// with SyncRegions, or an empty graph if the region has not been synced
+func LoadRegionGraph(region, serviceName string) (*graph.Graph, error) {
+	return loadGraphFile(filepath.Join(repo.Dir(), region, fmt.Sprintf("%s%s", serviceName, fileExt)))
+}
+
+// loadGraphFile returns the graph of the given file, or an empty graph if it does not exist
+func loadGraphFile(path string) (*graph.Graph, error) {
+	if !fileExists(path) {
+		return graph.NewGraph(), nil
+	}
+	return readGraph(path)
+}
+
+// readGraph returns the graph of a file, read holding the lock on the local graphs
+func readGraph(path string) (*graph.Graph, error) {
+	g := graph.NewGraph()
+	err := withGraphsLock(false, func() error {
+		content, err := readGraphFile(path)
+		if err != nil {
+			return err
+		}
+		return g.Unmarshal(content)
+	})
 	if err != nil {
-		return graph.NewGraph()
+		return nil, err
 	}
-	return g
+	return g, nil
 }
 
-func LoadAllGraphs() (*graph.Graph, error) {
-	path := filepath.Join(repo.Dir(), fmt.Sprintf("*%s", fileExt))
-	files, _ := filepath.Glob(path)
+// readGraphFile returns the content of a graph file, decrypted if need be
+func readGraphFile(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt.Open(content)
+}
 
-	g := graph.NewGraph()
+// marshalGraph returns the content of a graph file, encrypted if the storage encryption is enabled
+func marshalGraph(g *graph.Graph) ([]byte, error) {
+	b, err := g.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return encrypt.Seal(b)
+}
 
-	var readers []io.Reader
+// SyncedRegions returns the regions whose graphs have been synced with SyncRegions
+func SyncedRegions() []string {
+	files, _ := filepath.Glob(filepath.Join(repo.Dir(), "*", fmt.Sprintf("*%s", fileExt)))
+	unique := make(map[string]bool)
+	var regions []string
 	for _, f := range files {
-		reader, err := os.Open(f)
+		if region := filepath.Base(filepath.Dir(f)); !unique[region] {
+			unique[region] = true
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// MergeRegionGraphs merges the graphs of the given regions, setting the Region
+// property of their resources of the given types. Resources found in several
+// regions (ex: users, buckets) are given the region "global".
+func MergeRegionGraphs(graphs map[string]*graph.Graph, resourceTypes ...string) (*graph.Graph, error) {
+	merged := graph.NewGraph()
+	resources := make(map[string]*graph.Resource)
+	regionsOf := make(map[string][]string)
+
+	var regions []string
+	for region := range graphs {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		g := graphs[region]
+		merged.AddGraph(g)
+		all, err := g.GetAllResources(resourceTypes...)
 		if err != nil {
-			return g, fmt.Errorf("loading '%s': %s", f, err)
+			return merged, fmt.Errorf("merging %s: %s", region, err)
+		}
+		for _, res := range all {
+			if _, ok := resources[res.Id()]; !ok {
+				resources[res.Id()] = res
+			}
+			regionsOf[res.Id()] = append(regionsOf[res.Id()], region)
+		}
+	}
+
+	for id, res := range resources {
+		if _, ok := res.Properties["Region"]; ok {
+			continue
+		}
+		// only the region is added: marshaling again nested properties (ex: firewall rules) would duplicate them
+		tagged := graph.InitResource(res.Type(), id)
+		if regions := regionsOf[id]; len(regions) > 1 {
+			tagged.Properties["Region"] = "global"
+		} else {
+			tagged.Properties["Region"] = regions[0]
+		}
+		if err := merged.AddResource(tagged); err != nil {
+			return merged, err
 		}
-		readers = append(readers, reader)
 	}
 
-	err := g.UnmarshalMultiple(readers...)
+	return merged, nil
+}
+
+func accountDir(account string) string {
+	return filepath.Join(accountsDir, account)
+}
+
+// SyncedAccounts returns the ids of the accounts whose graphs have been synced with SyncAccounts
+func SyncedAccounts() []string {
+	files, _ := filepath.Glob(filepath.Join(repo.Dir(), accountsDir, "*", fmt.Sprintf("*%s", fileExt)))
+	unique := make(map[string]bool)
+	var accounts []string
+	for _, f := range files {
+		if account := filepath.Base(filepath.Dir(f)); !unique[account] {
+			unique[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// LoadAccountGraphs returns the merged graphs of the given services (all of them if none given)
+// synced for the given accounts with SyncAccounts. Resources are tagged with their accounts.
+func LoadAccountGraphs(accounts []string, serviceNames ...string) (*graph.Graph, error) {
+	var files []string
+	for _, account := range accounts {
+		dir := filepath.Join(repo.Dir(), accountDir(account))
+		if _, err := os.Stat(dir); err != nil {
+			return graph.NewGraph(), fmt.Errorf("account '%s' not synced: see `awless sync --profiles`", account)
+		}
+		if len(serviceNames) == 0 {
+			all, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*%s", fileExt)))
+			files = append(files, all...)
+		}
+		for _, name := range serviceNames {
+			if f := filepath.Join(dir, fmt.Sprintf("%s%s", name, fileExt)); fileExists(f) {
+				files = append(files, f)
+			}
+		}
+	}
+
+	return loadGraphFiles(files)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// SaveLocalGraph writes the graph as the local graph of the given name, loaded
+// along the graphs of the synced services by LoadAllGraphs
+func SaveLocalGraph(name string, g *graph.Graph) error {
+	b, err := marshalGraph(g)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %s", name, err)
+	}
+	return withGraphsLock(true, func() error {
+		return ioutil.WriteFile(filepath.Join(repo.Dir(), fmt.Sprintf("%s%s", name, fileExt)), b, 0600)
+	})
+}
+
+func LoadAllGraphs() (*graph.Graph, error) {
+	path := filepath.Join(repo.Dir(), fmt.Sprintf("*%s", fileExt))
+	files, _ := filepath.Glob(path)
+
+	return loadGraphFiles(files)
+}
+
+// loadGraphFiles returns the merged graphs of the given files, read holding the lock on the local graphs
+func loadGraphFiles(files []string) (*graph.Graph, error) {
+	g := graph.NewGraph()
+	err := withGraphsLock(false, func() error {
+		var readers []io.Reader
+		for _, f := range files {
+			content, err := readGraphFile(f)
+			if err != nil {
+				return fmt.Errorf("loading '%s': %s", f, err)
+			}
+			readers = append(readers, bytes.NewReader(content))
+		}
+		return g.UnmarshalMultiple(readers...)
+	})
 	return g, err
 }
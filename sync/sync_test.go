@@ -0,0 +1,742 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wallix/awless/cloud"
+	"github.com/wallix/awless/encrypt"
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/graph/resourcetest"
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/sync/remote"
+	"github.com/wallix/awless/sync/repo"
+	"github.com/wallix/awless/template/driver"
+)
+
+func TestMergeRegionGraphs(t *testing.T) {
+	eu, us := graph.NewGraph(), graph.NewGraph()
+	eu.AddResource(
+		resourcetest.Instance("inst_1").Prop("Name", "web").Build(),
+		resourcetest.SecurityGroup("sg_1").Prop("InboundRules", []*graph.FirewallRule{{Protocol: "tcp", PortRange: graph.PortRange{FromPort: 22, ToPort: 22}}}).Build(),
+		resourcetest.User("user_1").Build(),
+		resourcetest.AvailabilityZone("eu-west-1a").Prop("Region", "eu-west-1").Build(),
+	)
+	us.AddResource(
+		resourcetest.Instance("inst_2").Build(),
+		resourcetest.User("user_1").Build(),
+	)
+
+	merged, err := MergeRegionGraphs(map[string]*graph.Graph{"eu-west-1": eu, "us-east-1": us}, "instance", "user", "availabilityzone", "securitygroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"inst_1": "eu-west-1", "inst_2": "us-east-1", "user_1": "global", "eu-west-1a": "eu-west-1", "sg_1": "eu-west-1"}
+	for id, region := range expected {
+		res, err := merged.FindResource(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res == nil {
+			t.Fatalf("%s: not found", id)
+		}
+		if got, want := res.Properties["Region"], region; got != want {
+			t.Fatalf("%s: got %v, want %s", id, got, want)
+		}
+	}
+	inst, _ := merged.GetResource("instance", "inst_1")
+	if got, want := inst.Properties["Name"], "web"; got != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+	sg, _ := merged.GetResource("securitygroup", "sg_1")
+	if got, want := len(sg.Properties["InboundRules"].([]*graph.FirewallRule)), 1; got != want {
+		t.Fatalf("got %d inbound rules, want %d", got, want)
+	}
+}
+
+func TestSyncedRegions(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	g := graph.NewGraph()
+	g.AddResource(resourcetest.Instance("inst_1").Build())
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		if _, errs := writeGraphs(region, map[string]*graph.Graph{"infra": g}); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+	}
+	if _, errs := writeGraphs("", map[string]*graph.Graph{"infra": g}); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if got, want := SyncedRegions(), []string{"eu-west-1", "us-east-1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if all, _ := mustLoadGraph(LoadRegionGraph("eu-west-1", "infra")).GetAllResources("instance"); len(all) != 1 {
+		t.Fatalf("got %d instances in eu-west-1 graph, want 1", len(all))
+	}
+	if all, _ := mustLoadGraph(LoadRegionGraph("ap-south-1", "infra")).GetAllResources("instance"); len(all) != 0 {
+		t.Fatalf("got %d instances in graph of region not synced, want 0", len(all))
+	}
+}
+
+func TestSyncAccounts(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	services := func(users ...string) []cloud.Service {
+		g := graph.NewGraph()
+		for _, u := range users {
+			g.AddResource(resourcetest.User(u).Build())
+		}
+		infra := graph.NewGraph()
+		infra.AddResource(resourcetest.SecurityGroup("sg_"+users[0]).Prop("InboundRules", []*graph.FirewallRule{{Protocol: "tcp", PortRange: graph.PortRange{FromPort: 22, ToPort: 22}}}).Build())
+		return []cloud.Service{
+			&fakeService{name: "access", types: []string{"user"}, g: g},
+			&fakeService{name: "infra", types: []string{"securitygroup"}, g: infra},
+		}
+	}
+
+	s := NewSyncer(logger.DiscardLogger)
+	if _, err := s.SyncAccounts(map[string][]cloud.Service{"111111111111": services("user_1", "shared"), "222222222222": services("user_2", "shared")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := SyncedAccounts(), []string{"111111111111", "222222222222"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	g, err := LoadAccountGraphs([]string{"111111111111", "222222222222"}, "access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{"user_1": {"111111111111"}, "user_2": {"222222222222"}, "shared": {"111111111111", "222222222222"}}
+	for id, accounts := range expected {
+		res, err := g.GetResource("user", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := res.Properties["Account"].([]string)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, accounts) {
+			t.Fatalf("%s: got %v, want %v", id, got, accounts)
+		}
+	}
+
+	infra, err := LoadAccountGraphs([]string{"111111111111"}, "infra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sg, err := infra.GetResource("securitygroup", "sg_user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(sg.Properties["InboundRules"].([]*graph.FirewallRule)), 1; got != want {
+		t.Fatalf("got %d inbound rules, want %d", got, want)
+	}
+
+	if _, err := LoadAccountGraphs([]string{"333333333333"}); err == nil {
+		t.Fatal("expected error for account not synced")
+	}
+}
+
+func setTempAwlessHome(t *testing.T) func() {
+	home, err := ioutil.TempDir("", "awless-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Getenv("__AWLESS_HOME")
+	os.Setenv("__AWLESS_HOME", home)
+	return func() {
+		os.Setenv("__AWLESS_HOME", previous)
+		os.RemoveAll(home)
+	}
+}
+
+func mustLoadGraph(g *graph.Graph, err error) *graph.Graph {
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+type fakeService struct {
+	name  string
+	types []string
+	g     *graph.Graph
+
+	fetchedAll    int
+	fetchedByType []string
+}
+
+func (s *fakeService) Name() string             { return s.name }
+func (s *fakeService) Drivers() []driver.Driver { return nil }
+func (s *fakeService) ResourceTypes() []string  { return s.types }
+func (s *fakeService) IsSyncDisabled() bool     { return false }
+
+func (s *fakeService) FetchResources() (*graph.Graph, error) {
+	s.fetchedAll++
+	return s.g, nil
+}
+
+func (s *fakeService) FetchByType(t string) (*graph.Graph, error) {
+	s.fetchedByType = append(s.fetchedByType, t)
+	g := graph.NewGraph()
+	resources, err := s.g.GetAllResources(t)
+	if err != nil {
+		return g, err
+	}
+	return g, g.AddResource(resources...)
+}
+
+func TestIncrementalSync(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	ttls := map[string]time.Duration{"instance": time.Hour, "vpc": 0, "subnet": -1}
+	srv := &fakeService{name: "infra", types: []string{"instance", "vpc", "subnet"}, g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Build(), resourcetest.VPC("vpc_1").Build(), resourcetest.Subnet("sub_1").Build())
+	resourcetest.AddParents(srv.g, "vpc_1 -> sub_1", "sub_1 -> inst_1")
+
+	s := NewIncrementalSyncer("default/eu-west-1", func(typ string) time.Duration { return ttls[typ] }, repo.RetentionPolicy{}, logger.DiscardLogger)
+
+	if _, err := s.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll, 1; got != want {
+		t.Fatalf("got %d full fetches, want %d", got, want)
+	}
+
+	srv.g = graph.NewGraph()
+	srv.g.AddResource(resourcetest.Instance("inst_2").Build(), resourcetest.VPC("vpc_1").Prop("Name", "updated").Build(), resourcetest.Subnet("sub_2").Build())
+
+	graphs, err := s.Sync(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll, 1; got != want {
+		t.Fatalf("got %d full fetches, want %d", got, want)
+	}
+	if got, want := srv.fetchedByType, []string{"vpc"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for _, g := range []*graph.Graph{graphs["infra"], mustLoadGraph(LoadCurrentLocalGraph("infra"))} {
+		vpc, err := g.GetResource("vpc", "vpc_1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := vpc.Properties["Name"], "updated"; got != want {
+			t.Fatalf("got %v, want %s", got, want)
+		}
+		for typ, id := range map[string]string{"instance": "inst_1", "subnet": "sub_1"} {
+			all, err := g.GetAllResources(typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 1 || all[0].Id() != id {
+				t.Fatalf("got %v, want fresh %s kept", all, id)
+			}
+		}
+		children, err := g.ListResourcesIn(vpc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(children), 2; got != want {
+			t.Fatalf("got %d resources in vpc, want %d", got, want)
+		}
+	}
+
+	other := NewIncrementalSyncer("default/us-east-1", func(typ string) time.Duration { return ttls[typ] }, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := other.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll, 2; got != want {
+		t.Fatalf("got %d full fetches in other scope, want %d", got, want)
+	}
+}
+
+func TestDaemonLock(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	if DaemonRunning("default/eu-west-1") {
+		t.Fatal("expected no daemon running")
+	}
+	release, err := LockDaemon("default/eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !DaemonRunning("default/eu-west-1") {
+		t.Fatal("expected daemon running")
+	}
+	if DaemonRunning("default/us-east-1") {
+		t.Fatal("expected no daemon running in other scope")
+	}
+	if _, err := LockDaemon("default/us-east-1"); err != ErrDaemonRunning {
+		t.Fatalf("got %v, want %v", err, ErrDaemonRunning)
+	}
+	release()
+	if DaemonRunning("default/eu-west-1") {
+		t.Fatal("expected no daemon running once released")
+	}
+}
+
+func TestGraphsLockReadsConsistentSnapshot(t *testing.T) {
+	defer setTempAwlessHome(t)()
+	NewSyncer()
+
+	g := graph.NewGraph()
+	g.AddResource(resourcetest.Instance("inst_1").Build())
+	if err := SaveLocalGraph("infra", g); err != nil {
+		t.Fatal(err)
+	}
+
+	writing, written := make(chan struct{}), make(chan struct{})
+	go func() {
+		withGraphsLock(true, func() error {
+			close(writing)
+			time.Sleep(50 * time.Millisecond)
+			g.AddResource(resourcetest.Instance("inst_2").Build())
+			_, errs := writeGraphs("", map[string]*graph.Graph{"infra": g})
+			if len(errs) > 0 {
+				t.Error(errs)
+			}
+			return nil
+		})
+		close(written)
+	}()
+
+	<-writing
+	all, err := mustLoadGraph(LoadCurrentLocalGraph("infra")).GetAllResources("instance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(all), 2; got != want {
+		t.Fatalf("got %d instances, want %d once written", got, want)
+	}
+	<-written
+}
+
+func TestEncryptedLocalGraphs(t *testing.T) {
+	defer setTempAwlessHome(t)()
+	os.Unsetenv(encrypt.PassphraseEnv)
+	if err := ioutil.WriteFile(filepath.Join(os.Getenv("__AWLESS_HOME"), "storage.key"), []byte("0123456789abcdef0123456789abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &fakeService{name: "infra", g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Prop("PublicIP", "1.2.3.4").Build())
+	s := NewSyncer(logger.DiscardLogger)
+	if _, err := s.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(localGraphPath("infra"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypt.IsEncrypted(content) || strings.Contains(string(content), "1.2.3.4") {
+		t.Fatalf("got %q, want encrypted graph", content)
+	}
+
+	g, err := LoadAllGraphs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GetResource("instance", "inst_1"); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := s.LoadRev(revs[len(revs)-1].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rev.Graph("infra").GetResource("instance", "inst_1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncryptedLocalGraphsWithoutKey(t *testing.T) {
+	defer setTempAwlessHome(t)()
+	os.Unsetenv(encrypt.PassphraseEnv)
+	keyFile := filepath.Join(os.Getenv("__AWLESS_HOME"), "storage.key")
+	if err := ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &fakeService{name: "infra", g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Prop("PublicIP", "1.2.3.4").Build())
+	if _, err := NewSyncer(logger.DiscardLogger).Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := ioutil.ReadFile(localGraphPath("infra"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(keyFile)
+	if _, err := LoadCurrentLocalGraph("infra"); err != encrypt.ErrNoKey {
+		t.Fatalf("got %v, want %v", err, encrypt.ErrNoKey)
+	}
+	if _, err := NewSyncer(logger.DiscardLogger).Sync(srv); err == nil {
+		t.Fatal("expected error writing graph without key")
+	}
+	if content, _ := ioutil.ReadFile(localGraphPath("infra")); !bytes.Equal(content, encrypted) {
+		t.Fatalf("got %q, want encrypted graph unchanged", content)
+	}
+}
+
+func TestInvalidStorageKeyWritesNoGraph(t *testing.T) {
+	defer setTempAwlessHome(t)()
+	os.Unsetenv(encrypt.PassphraseEnv)
+	os.Setenv(encrypt.KeyFileEnv, filepath.Join(os.Getenv("__AWLESS_HOME"), "missing.key"))
+	defer os.Unsetenv(encrypt.KeyFileEnv)
+
+	srv := &fakeService{name: "infra", g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Prop("PublicIP", "1.2.3.4").Build())
+	if _, err := NewSyncer(logger.DiscardLogger).Sync(srv); err == nil || !strings.Contains(err.Error(), "storage key file") {
+		t.Fatalf("got %v, want storage key error", err)
+	}
+	if fileExists(localGraphPath("infra")) {
+		t.Fatal("expected no graph written without its storage key")
+	}
+}
+
+func TestPushPullRemote(t *testing.T) {
+	shared, err := ioutil.TempDir("", "awless-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(shared)
+	backend := remote.NewDirBackend(shared)
+
+	srv := &fakeService{name: "infra", types: []string{"instance"}, g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Build())
+	ttl := func(string) time.Duration { return time.Hour }
+
+	restoreHome := setTempAwlessHome(t)
+	pusher := NewIncrementalSyncer("default/eu-west-1", ttl, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := pusher.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := pusher.Push(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed == 0 {
+		t.Fatal("expected files pushed")
+	}
+	if again, err := pusher.Push(backend); err != nil || again >= pushed {
+		t.Fatalf("got %d files pushed again (err %v), want only graphs and reference pushed again", again, err)
+	}
+	restoreHome()
+
+	defer setTempAwlessHome(t)()
+	puller := NewIncrementalSyncer("default/eu-west-1", ttl, repo.RetentionPolicy{}, logger.DiscardLogger).(*syncer)
+	if _, err := puller.Pull(backend, false, srv); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := LoadAllGraphs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GetResource("instance", "inst_1"); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := puller.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(revs), 1; got != want {
+		t.Fatalf("got %d revisions, want %d", got, want)
+	}
+	if _, err := puller.LoadRev(revs[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := puller.lastSyncs([]cloud.Service{srv})["infra"]["instance"]; !ok {
+		t.Fatal("expected pulled resource types synced")
+	}
+
+	srv.fetchedAll = 0
+	if _, err := puller.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll+len(srv.fetchedByType), 0; got != want {
+		t.Fatalf("got %d fetches, want %d after pull", got, want)
+	}
+
+	srv.g.AddResource(resourcetest.Instance("inst_2").Build())
+	if _, err := NewSyncer(logger.DiscardLogger).Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := puller.Push(backend); err != nil {
+		t.Fatal(err)
+	}
+	if revs, err = puller.List(); err != nil || len(revs) != 2 {
+		t.Fatalf("got %d revisions (err %v), want 2 revisions following the pulled one", len(revs), err)
+	}
+
+	if err := os.Remove(localGraphPath("infra")); err != nil {
+		t.Fatal(err)
+	}
+	other := NewIncrementalSyncer("prod/us-east-1", ttl, repo.RetentionPolicy{}, logger.DiscardLogger).(*syncer)
+	if count, err := other.Pull(backend, false, srv); err == nil || count != 0 {
+		t.Fatalf("got %d files pulled (err %v), want pull from other scope refused", count, err)
+	}
+	if fileExists(localGraphPath("infra")) {
+		t.Fatal("expected no graph pulled from other scope")
+	}
+}
+
+func TestPullOnlyFastForwards(t *testing.T) {
+	shared, err := ioutil.TempDir("", "awless-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(shared)
+	backend := remote.NewDirBackend(shared)
+
+	infra := &fakeService{name: "infra", types: []string{"instance"}, g: graph.NewGraph()}
+	infra.g.AddResource(resourcetest.Instance("inst_1").Build())
+	access := &fakeService{name: "access", types: []string{"user"}, g: graph.NewGraph()}
+	access.g.AddResource(resourcetest.User("usr_1").Build())
+
+	defer setTempAwlessHome(t)()
+	pusherHome := os.Getenv("__AWLESS_HOME")
+	pusher := NewIncrementalSyncer("default/eu-west-1", nil, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := pusher.Sync(infra); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pusher.Push(backend); err != nil {
+		t.Fatal(err)
+	}
+
+	defer setTempAwlessHome(t)()
+	pullerHome := os.Getenv("__AWLESS_HOME")
+	puller := NewIncrementalSyncer("default/eu-west-1", nil, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := puller.Sync(access); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := puller.Pull(backend, false); err == nil {
+		t.Fatal("expected pull discarding local revisions refused")
+	}
+	if revs, err := puller.List(); err != nil || len(revs) != 1 {
+		t.Fatalf("got %d revisions (err %v), want local revision kept", len(revs), err)
+	}
+	if fileExists(localGraphPath("infra")) || !fileExists(localGraphPath("access")) {
+		t.Fatal("expected local graphs unchanged by refused pull")
+	}
+
+	if _, err := puller.Pull(backend, true); err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(localGraphPath("infra")) || fileExists(localGraphPath("access")) {
+		t.Fatal("expected local graphs replaced by the pulled ones")
+	}
+
+	os.Setenv("__AWLESS_HOME", pusherHome)
+	infra.g.AddResource(resourcetest.Instance("inst_2").Build())
+	if _, err := pusher.Sync(infra); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pusher.Push(backend); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("__AWLESS_HOME", pullerHome)
+	if _, err := puller.Pull(backend, false); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := puller.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(revs), 2; got != want {
+		t.Fatalf("got %d revisions, want %d once fast-forwarded", got, want)
+	}
+	if _, err := mustLoadGraph(LoadCurrentLocalGraph("infra")).GetResource("instance", "inst_2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResourceHistory(t *testing.T) {
+	rev := func(id string, day int, resources ...*graph.Resource) *repo.Rev {
+		g := graph.NewGraph()
+		g.AddResource(resources...)
+		g.AddResource(resourcetest.Subnet("sub_1").Build(), resourcetest.Subnet("sub_2").Build())
+		for _, res := range resources {
+			if res.Properties["Subnet"] != nil {
+				resourcetest.AddParents(g, fmt.Sprintf("%s -> %s", res.Properties["Subnet"], res.Id()))
+			}
+		}
+		return &repo.Rev{Id: id, Date: time.Date(2017, 6, day, 0, 0, 0, 0, time.UTC), Graphs: map[string]*graph.Graph{"infra": g}}
+	}
+	revs := []*repo.Rev{
+		rev("rev4", 4),
+		rev("rev2", 2, resourcetest.Instance("inst_1").Prop("State", "running").Prop("Subnet", "sub_1").Build()),
+		rev("rev1", 1),
+		rev("rev3", 3, resourcetest.Instance("inst_1").Prop("State", "stopped").Prop("Subnet", "sub_2").Build()),
+		rev("rev3bis", 3, resourcetest.Instance("inst_1").Prop("State", "stopped").Prop("Subnet", "sub_2").Build()),
+	}
+
+	changes, err := ResourceHistory(revs, "inst_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, fmt.Sprintf("%s %s", c.Rev.Id, c.Kind))
+		for _, p := range c.Properties {
+			got = append(got, p.String())
+		}
+		for _, r := range c.Relations {
+			got = append(got, fmt.Sprintf("%s %s %t", r.Relation, r.Resource.Id(), r.Added))
+		}
+	}
+	want := []string{
+		"rev2 appeared",
+		"rev3 updated",
+		"State: running -> stopped",
+		"Subnet: sub_1 -> sub_2",
+		"parent sub_1 false",
+		"parent sub_2 true",
+		"rev4 disappeared",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestIncrementalSyncFetchesServiceWithUnreadableLocalGraph(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	ttls := map[string]time.Duration{"instance": 0, "subnet": time.Hour}
+	srv := &fakeService{name: "infra", types: []string{"instance", "subnet"}, g: graph.NewGraph()}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Build(), resourcetest.Subnet("sub_1").Build())
+	resourcetest.AddParents(srv.g, "sub_1 -> inst_1")
+
+	s := NewIncrementalSyncer("default/eu-west-1", func(typ string) time.Duration { return ttls[typ] }, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := s.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(localGraphPath("infra"), []byte("not a graph"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll, 2; got != want {
+		t.Fatalf("got %d full fetches, want %d", got, want)
+	}
+	if got, want := len(srv.fetchedByType), 0; got != want {
+		t.Fatalf("got %d fetches by type, want %d", got, want)
+	}
+	subnets, err := mustLoadGraph(LoadCurrentLocalGraph("infra")).GetAllResources("subnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(subnets), 1; got != want {
+		t.Fatalf("got %d subnets in local graph, want %d", got, want)
+	}
+}
+
+// relationsFakeService builds the subnet relations of the instances it fetches by type
+// from their Subnet property, as the aws services do from the fetched AWS objects
+type relationsFakeService struct {
+	*fakeService
+}
+
+func (s *relationsFakeService) FetchByTypeWithRelations(t string) (*graph.Graph, func(*graph.Graph) error, error) {
+	g, err := s.FetchByType(t)
+	if err != nil || t != "instance" {
+		return g, nil, err
+	}
+	instances, err := g.GetAllResources("instance")
+	if err != nil {
+		return g, nil, err
+	}
+	return g, func(merged *graph.Graph) error {
+		for _, inst := range instances {
+			if subnet, ok := inst.Properties["Subnet"].(string); ok {
+				resourcetest.AddParents(merged, fmt.Sprintf("%s -> %s", subnet, inst.Id()))
+			}
+		}
+		return nil
+	}, nil
+}
+
+func TestIncrementalSyncRebuildsRelationsOfStaleTypes(t *testing.T) {
+	defer setTempAwlessHome(t)()
+
+	ttls := map[string]time.Duration{"instance": 0, "subnet": time.Hour}
+	srv := &relationsFakeService{&fakeService{name: "infra", types: []string{"instance", "subnet"}, g: graph.NewGraph()}}
+	srv.g.AddResource(resourcetest.Instance("inst_1").Prop("Subnet", "sub_1").Build(), resourcetest.Subnet("sub_1").Build(), resourcetest.Subnet("sub_2").Build())
+	resourcetest.AddParents(srv.g, "sub_2 -> inst_1")
+
+	s := NewIncrementalSyncer("default/eu-west-1", func(typ string) time.Duration { return ttls[typ] }, repo.RetentionPolicy{}, logger.DiscardLogger)
+	if _, err := s.Sync(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.g.AddResource(resourcetest.Instance("inst_2").Prop("Subnet", "sub_1").Build())
+
+	graphs, err := s.Sync(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.fetchedAll, 1; got != want {
+		t.Fatalf("got %d full fetches, want %d", got, want)
+	}
+	if got, want := srv.fetchedByType, []string{"instance"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v fetched by type, want %v", got, want)
+	}
+
+	expected := map[string][]string{"sub_1": {"inst_1", "inst_2"}, "sub_2": nil}
+	for _, g := range []*graph.Graph{graphs["infra"], mustLoadGraph(LoadCurrentLocalGraph("infra"))} {
+		for subnet, want := range expected {
+			sub, err := g.GetResource("subnet", subnet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			children, err := g.ListResourcesIn(sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, child := range children {
+				ids = append(ids, child.Id())
+			}
+			sort.Strings(ids)
+			if got := ids; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v in %s, want %v", got, subnet, want)
+			}
+		}
+	}
+}
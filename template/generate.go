@@ -0,0 +1,237 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/graph"
+	"github.com/wallix/awless/template/internal/ast"
+)
+
+var (
+	generateIgnoredParams  = map[string]bool{"wait": true, "lock": true, "userdata": true}
+	generateParamAliases   = map[string]string{"securitygroup": "SecurityGroups", "launchconfiguration": "LaunchConfigurationName", "cooldown": "DefaultCooldown"}
+	generateInvalidVarChar = regexp.MustCompile("[^a-zA-Z0-9_]+")
+)
+
+// Generate returns the template creating the given resources in their order
+// (ex: the reverse of graph.TeardownOrder). Each created resource is declared
+// as a variable referenced by the resources created after it, and its params
+// are mapped back from its properties, or from its parents, using the create
+// definitions. Required params with no known value are left as holes.
+// Security group rules, routes and attachments come once all resources exist.
+// Resources that cannot be created through a template, or that are created
+// along with their vpc (default security groups, main route tables), are
+// returned as skipped.
+func Generate(g *graph.Graph, resources []*graph.Resource, lookup DefinitionLookupFunc) (*Template, []*graph.Resource, error) {
+	var lines, post []string
+	var skipped []*graph.Resource
+	vars := make(map[string]string)
+	taken := make(map[string]bool)
+
+	for _, res := range resources {
+		def, ok := lookup("create" + res.Type())
+		if !ok || res.CreatedWithVpc() {
+			skipped = append(skipped, res)
+			continue
+		}
+
+		name := generateVarName(res, taken)
+		var params []string
+		for _, key := range append(def.Required(), def.Extra()...) {
+			if generateIgnoredParams[key] {
+				continue
+			}
+			value, found, err := generateParamValue(g, res, key, vars)
+			if err != nil {
+				return nil, skipped, fmt.Errorf("generate: %s: %s", res, err)
+			}
+			switch {
+			case found:
+				params = append(params, fmt.Sprintf("%s=%s", key, value))
+			case containsString(def.Required(), key):
+				params = append(params, fmt.Sprintf("%s={ %s.%s }", key, name, key))
+			}
+		}
+		sort.Strings(params)
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("%s = create %s %s", name, res.Type(), strings.Join(params, " "))))
+		vars[res.Id()] = name
+
+		statements, err := generatePostStatements(g, res, name)
+		if err != nil {
+			return nil, skipped, fmt.Errorf("generate: %s: %s", res, err)
+		}
+		post = append(post, statements...)
+	}
+
+	if len(lines) == 0 {
+		return nil, skipped, fmt.Errorf("generate: no resource to create")
+	}
+
+	for _, statement := range post {
+		lines = append(lines, resolveGeneratedRefs(statement, vars))
+	}
+
+	text := strings.Join(lines, "\n")
+	tpl, err := Parse(text)
+	if err != nil {
+		return nil, skipped, fmt.Errorf("generate: \n%s\n%s", text, err)
+	}
+
+	return tpl, skipped, nil
+}
+
+func generateVarName(res *graph.Resource, taken map[string]bool) string {
+	base := res.Type()
+	if name, ok := res.Properties["Name"].(string); ok {
+		if cleaned := strings.Trim(generateInvalidVarChar.ReplaceAllString(strings.ToLower(name), "_"), "_"); cleaned != "" {
+			base = cleaned
+		}
+	}
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	taken[name] = true
+	return name
+}
+
+func generateParamValue(g *graph.Graph, res *graph.Resource, key string, vars map[string]string) (string, bool, error) {
+	if key == "count" {
+		return "1", true, nil
+	}
+	normalized := strings.Replace(key, "-", "", -1)
+	for label, value := range res.Properties {
+		if strings.ToLower(label) == normalized || generateParamAliases[key] == label {
+			v, ok := formatGeneratedValue(value, vars)
+			return v, ok, nil
+		}
+	}
+	var parents []*graph.Resource
+	if err := g.Accept(&graph.ParentsVisitor{From: res, Each: graph.VisitorCollectFunc(&parents)}); err != nil {
+		return "", false, err
+	}
+	for _, parent := range parents {
+		if parent.Type() == key {
+			v, ok := formatGeneratedValue(parent.Id(), vars)
+			return v, ok, nil
+		}
+	}
+	if key == "subnets" {
+		applied, err := g.ListResourcesAppliedOn(res)
+		if err != nil {
+			return "", false, err
+		}
+		var subnets []string
+		for _, r := range applied {
+			if r.Type() == "subnet" {
+				subnets = append(subnets, r.Id())
+			}
+		}
+		if len(subnets) > 0 {
+			v, ok := formatGeneratedValue(subnets, vars)
+			return v, ok, nil
+		}
+	}
+	return "", false, nil
+}
+
+func formatGeneratedValue(value interface{}, vars map[string]string) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if name, ok := vars[v]; ok {
+			return "$" + name, true
+		}
+		if v == "" {
+			return "", false
+		}
+		return quoteParamIfNeeded(v), true
+	case []string:
+		if len(v) == 1 {
+			return formatGeneratedValue(v[0], vars)
+		}
+		for _, s := range v {
+			if _, declared := vars[s]; declared || !ast.SimpleStringValue.MatchString(s) {
+				return "", false
+			}
+		}
+		return strings.Join(v, ","), len(v) > 0
+	case int, int64, float64, bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+func generatePostStatements(g *graph.Graph, res *graph.Resource, name string) ([]string, error) {
+	var statements []string
+	switch res.Type() {
+	case "internetgateway":
+		if vpcs, ok := res.Properties["Vpcs"].([]string); ok {
+			for _, vpc := range vpcs {
+				statements = append(statements, fmt.Sprintf("attach internetgateway id=$%s vpc=%s", name, generatedRef(vpc)))
+			}
+		}
+	case "securitygroup":
+		for _, direction := range []string{"inbound", "outbound"} {
+			rules, _ := res.Properties[strings.Title(direction)+"Rules"].([]*graph.FirewallRule)
+			for _, rule := range rules {
+				portrange := "any"
+				if !rule.PortRange.Any {
+					portrange = fmt.Sprint(rule.PortRange.FromPort)
+					if rule.PortRange.FromPort != rule.PortRange.ToPort {
+						portrange = fmt.Sprintf("%d-%d", rule.PortRange.FromPort, rule.PortRange.ToPort)
+					}
+				}
+				for _, cidr := range rule.IPRanges {
+					if direction == "outbound" && rule.Protocol == "any" && cidr.String() == "0.0.0.0/0" {
+						continue // created along with the security group
+					}
+					statements = append(statements, fmt.Sprintf("update securitygroup id=$%s %s=authorize protocol=%s cidr=%s portrange=%s", name, direction, quoteParamIfNeeded(rule.Protocol), cidr, portrange))
+				}
+			}
+		}
+	case "routetable":
+		routes, _ := res.Properties["Routes"].([]*graph.Route)
+		for _, route := range routes {
+			if route.Destination == nil {
+				continue
+			}
+			for _, target := range route.Targets {
+				if target.Type != graph.GatewayTarget || target.Ref == "local" {
+					continue
+				}
+				statements = append(statements, fmt.Sprintf("create route cidr=%s gateway=%s table=$%s", route.Destination, generatedRef(target.Ref), name))
+			}
+		}
+		applied, err := g.ListResourcesAppliedOn(res)
+		if err != nil {
+			return statements, err
+		}
+		for _, r := range applied {
+			if r.Type() == "subnet" {
+				statements = append(statements, fmt.Sprintf("attach routetable id=$%s subnet=%s", name, generatedRef(r.Id())))
+			}
+		}
+	}
+	return statements, nil
+}
+
+// generatedRef marks a resource id to resolve as a reference once all resources are declared
+func generatedRef(id string) string {
+	return fmt.Sprintf("{{%s}}", id)
+}
+
+var generatedRefRegex = regexp.MustCompile("{{([^}]+)}}")
+
+func resolveGeneratedRefs(statement string, vars map[string]string) string {
+	return generatedRefRegex.ReplaceAllStringFunc(statement, func(match string) string {
+		id := generatedRefRegex.FindStringSubmatch(match)[1]
+		if name, ok := vars[id]; ok {
+			return "$" + name
+		}
+		return quoteParamIfNeeded(id)
+	})
+}
@@ -0,0 +1,95 @@
+package template
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/wallix/awless/graph"
+)
+
+func TestGenerate(t *testing.T) {
+	defs := map[string]Definition{
+		"createvpc":             {Action: "create", Entity: "vpc", RequiredParams: []string{"cidr"}, ExtraParams: []string{"name"}},
+		"createsubnet":          {Action: "create", Entity: "subnet", RequiredParams: []string{"cidr", "vpc"}, ExtraParams: []string{"availabilityzone", "name"}},
+		"createinstance":        {Action: "create", Entity: "instance", RequiredParams: []string{"count", "image", "name", "subnet", "type"}, ExtraParams: []string{"keypair", "securitygroup", "userdata", "wait"}},
+		"createsecuritygroup":   {Action: "create", Entity: "securitygroup", RequiredParams: []string{"description", "name", "vpc"}},
+		"createinternetgateway": {Action: "create", Entity: "internetgateway"},
+		"createroutetable":      {Action: "create", Entity: "routetable", RequiredParams: []string{"vpc"}},
+		"createscalinggroup":    {Action: "create", Entity: "scalinggroup", RequiredParams: []string{"launchconfiguration", "max-size", "min-size", "name", "subnets"}, ExtraParams: []string{"cooldown"}},
+	}
+	lookup := func(key string) (Definition, bool) {
+		def, ok := defs[key]
+		return def, ok
+	}
+
+	build := func(typ, id string, props map[string]interface{}) *graph.Resource {
+		res := graph.InitResource(typ, id)
+		for k, v := range props {
+			res.Properties[k] = v
+		}
+		return res
+	}
+	_, anywhere, _ := net.ParseCIDR("0.0.0.0/0")
+	_, local, _ := net.ParseCIDR("10.0.0.0/16")
+
+	vpc := build("vpc", "vpc_1", map[string]interface{}{"CIDR": "10.0.0.0/16", "Name": "Prod VPC"})
+	igw := build("internetgateway", "igw_1", map[string]interface{}{"Vpcs": []string{"vpc_1"}})
+	defaultSg := build("securitygroup", "sg_0", map[string]interface{}{"Name": "default", "Vpc": "vpc_1"})
+	sg := build("securitygroup", "sg_1", map[string]interface{}{"Name": "ssh", "Description": "ssh access", "Vpc": "vpc_1",
+		"InboundRules":  []*graph.FirewallRule{{Protocol: "tcp", PortRange: graph.PortRange{FromPort: 22, ToPort: 22}, IPRanges: []*net.IPNet{anywhere}}},
+		"OutboundRules": []*graph.FirewallRule{{Protocol: "any", PortRange: graph.PortRange{Any: true}, IPRanges: []*net.IPNet{anywhere}}},
+	})
+	mainRt := build("routetable", "rt_0", map[string]interface{}{"Main": true, "Vpc": "vpc_1"})
+	rt := build("routetable", "rt_1", map[string]interface{}{"Main": false, "Vpc": "vpc_1", "Routes": []*graph.Route{
+		{Destination: local, Targets: []*graph.RouteTarget{{Type: graph.GatewayTarget, Ref: "local"}}},
+		{Destination: anywhere, Targets: []*graph.RouteTarget{{Type: graph.GatewayTarget, Ref: "igw_1"}}},
+	}})
+	sub := build("subnet", "sub_1", map[string]interface{}{"CIDR": "10.0.1.0/24", "Name": "public", "AvailabilityZone": "eu-west-1a"})
+	sub2 := build("subnet", "sub_2", map[string]interface{}{"CIDR": "10.0.2.0/24", "Vpc": "vpc_1"})
+	inst := build("instance", "inst_1", map[string]interface{}{"Name": "web", "Type": "t2.micro", "Subnet": "sub_1", "SecurityGroups": []string{"sg_1"}, "KeyPair": "my keypair"})
+	asg := build("scalinggroup", "asg_1", map[string]interface{}{"Name": "web", "LaunchConfigurationName": "web-lc", "MaxSize": 2, "MinSize": 1, "DefaultCooldown": 300})
+	region := build("region", "eu-west-1", nil)
+
+	g := graph.NewGraph()
+	g.AddResource(vpc, igw, defaultSg, sg, mainRt, rt, sub, sub2, inst, asg, region)
+	g.AddParentRelation(vpc, sub)
+	g.AddAppliesOnRelation(rt, sub)
+	g.AddAppliesOnRelation(asg, sub)
+	g.AddAppliesOnRelation(asg, sub2)
+
+	tpl, skipped, err := Generate(g, []*graph.Resource{region, igw, vpc, defaultSg, sg, mainRt, rt, sub, sub2, inst, asg}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"internetgateway = create internetgateway",
+		"prod_vpc = create vpc cidr=10.0.0.0/16 name='Prod VPC'",
+		"ssh = create securitygroup description='ssh access' name=ssh vpc=$prod_vpc",
+		"routetable = create routetable vpc=$prod_vpc",
+		"public = create subnet availabilityzone=eu-west-1a cidr=10.0.1.0/24 name=public vpc=$prod_vpc",
+		"subnet = create subnet cidr=10.0.2.0/24 vpc=$prod_vpc",
+		"web = create instance count=1 image={web.image} keypair='my keypair' name=web securitygroup=$ssh subnet=$public type=t2.micro",
+		"web_2 = create scalinggroup cooldown=300 launchconfiguration=web-lc max-size=2 min-size=1 name=web subnets={web_2.subnets}",
+		"attach internetgateway id=$internetgateway vpc=$prod_vpc",
+		"update securitygroup cidr=0.0.0.0/0 id=$ssh inbound=authorize portrange=22 protocol=tcp",
+		"create route cidr=0.0.0.0/0 gateway=$internetgateway table=$routetable",
+		"attach routetable id=$routetable subnet=$public",
+	}
+	if got, want := tpl.String(), strings.Join(exp, "\n"); got != want {
+		t.Fatalf("got\n%s\n\nwant\n%s", got, want)
+	}
+
+	var ids []string
+	for _, res := range skipped {
+		ids = append(ids, res.Id())
+	}
+	if got, want := strings.Join(ids, " "), "eu-west-1 sg_0 rt_0"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if _, _, err := Generate(g, []*graph.Resource{region}, lookup); err == nil {
+		t.Fatal("expected error got none")
+	}
+}
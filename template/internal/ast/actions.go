@@ -11,6 +11,7 @@ const (
 	Update Action = "update"
 
 	Check Action = "check"
+	Wait  Action = "wait"
 
 	Start Action = "start"
 	Stop  Action = "stop"
@@ -29,6 +30,7 @@ var actions = map[Action]struct{}{
 	Delete:     {},
 	Update:     {},
 	Check:      {},
+	Wait:       {},
 	Start:      {},
 	Stop:       {},
 	Attach:     {},
@@ -187,7 +187,7 @@ func isRevertible(cmd *ast.CommandNode) bool {
 		return false
 	}
 
-	if cmd.Action == "check" {
+	if cmd.Action == "check" || cmd.Action == "wait" {
 		return false
 	}
 
@@ -342,6 +342,8 @@ func TestCmdNodeIsRevertible(t *testing.T) {
 		{line: "detach routetable", revertible: false},
 		{line: "start alarm", revertible: true},
 		{line: "stop alarm", revertible: true},
+		{line: "check instance", revertible: false},
+		{line: "wait instance", result: "any", revertible: false},
 	}
 
 	for _, tc := range tcases {
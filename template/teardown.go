@@ -0,0 +1,123 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/graph"
+)
+
+// Teardown returns the template deleting the given resources in their order
+// (see graph.TeardownOrder), with the checks needed between the deletions.
+// Resources that cannot be deleted through a template, that are shared
+// such as keypairs, or that are deleted along with their vpc (default
+// security groups, main route tables) are returned as skipped.
+func Teardown(resources []*graph.Resource, lookup DefinitionLookupFunc) (*Template, []*graph.Resource, error) {
+	var lines []string
+	var skipped []*graph.Resource
+
+	for i, res := range resources {
+		notLastCommand := (i != len(resources)-1)
+		params, ok := teardownParams(res, lookup)
+		if !ok || res.CreatedWithVpc() {
+			skipped = append(skipped, res)
+			continue
+		}
+		id := quoteParamIfNeeded(res.Id())
+
+		// Prechecks
+		switch res.Type() {
+		case "securitygroup":
+			lines = append(lines, fmt.Sprintf("check securitygroup id=%s state=unused timeout=180", id))
+		case "scalinggroup":
+			lines = append(lines, fmt.Sprintf("update scalinggroup name=%s max-size=0 min-size=0", params["name"]))
+			lines = append(lines, fmt.Sprintf("check scalinggroup count=0 name=%s timeout=180", params["name"]))
+			params["force"] = "true"
+		case "volume":
+			lines = append(lines, fmt.Sprintf("check volume id=%s state=available timeout=180", id))
+		case "vpc":
+			for _, other := range resources {
+				if other.Type() != "internetgateway" {
+					continue
+				}
+				if vpcs, ok := other.Properties["Vpcs"].([]string); ok && containsString(vpcs, res.Id()) {
+					lines = append(lines, fmt.Sprintf("detach internetgateway id=%s vpc=%s", quoteParamIfNeeded(other.Id()), id))
+				}
+			}
+		}
+
+		var flat []string
+		for k, v := range params {
+			flat = append(flat, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(flat)
+		lines = append(lines, fmt.Sprintf("delete %s %s", res.Type(), strings.Join(flat, " ")))
+
+		// Postchecks
+		if notLastCommand {
+			switch res.Type() {
+			case "instance":
+				lines = append(lines, fmt.Sprintf("check instance id=%s state=terminated timeout=180", id))
+			case "database":
+				lines = append(lines, fmt.Sprintf("check database id=%s state=not-found timeout=300", id))
+			case "loadbalancer":
+				lines = append(lines, fmt.Sprintf("check loadbalancer id=%s state=not-found timeout=180", id))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, skipped, fmt.Errorf("teardown: no resource to delete")
+	}
+
+	text := strings.Join(lines, "\n")
+	tpl, err := Parse(text)
+	if err != nil {
+		return nil, skipped, fmt.Errorf("teardown: \n%s\n%s", text, err)
+	}
+
+	return tpl, skipped, nil
+}
+
+var sharedTeardownTypes = map[string]bool{"keypair": true, "image": true, "policy": true}
+
+func teardownParams(res *graph.Resource, lookup DefinitionLookupFunc) (map[string]string, bool) {
+	if sharedTeardownTypes[res.Type()] {
+		return nil, false
+	}
+	def, ok := lookup("delete" + res.Type())
+	if !ok {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	required := def.Required()
+	if len(required) == 0 {
+		required = []string{"id"}
+	}
+	for _, key := range required {
+		switch key {
+		case "id", "url":
+			params[key] = quoteParamIfNeeded(res.Id())
+		case "name", "arn":
+			value := res.Id()
+			if v, ok := res.Properties[strings.Title(key)].(string); ok && v != "" {
+				value = v
+			}
+			params[key] = quoteParamIfNeeded(value)
+		default:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func containsString(arr []string, s string) bool {
+	for _, a := range arr {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
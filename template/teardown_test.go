@@ -0,0 +1,87 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wallix/awless/graph"
+)
+
+func TestTeardown(t *testing.T) {
+	defs := map[string]Definition{
+		"deleteinstance":        {Action: "delete", Entity: "instance", RequiredParams: []string{"id"}},
+		"deletesubnet":          {Action: "delete", Entity: "subnet", RequiredParams: []string{"id"}},
+		"deletevpc":             {Action: "delete", Entity: "vpc", RequiredParams: []string{"id"}},
+		"deletesecuritygroup":   {Action: "delete", Entity: "securitygroup", RequiredParams: []string{"id"}},
+		"deleteinternetgateway": {Action: "delete", Entity: "internetgateway", RequiredParams: []string{"id"}},
+		"deleteroutetable":      {Action: "delete", Entity: "routetable", RequiredParams: []string{"id"}},
+		"deletescalinggroup":    {Action: "delete", Entity: "scalinggroup", RequiredParams: []string{"name"}, ExtraParams: []string{"force"}},
+		"deleteelasticip":       {Action: "delete", Entity: "elasticip", ExtraParams: []string{"id", "ip"}},
+		"deletekeypair":         {Action: "delete", Entity: "keypair", RequiredParams: []string{"name"}},
+		"deleterecord":          {Action: "delete", Entity: "record", RequiredParams: []string{"name", "ttl", "type", "value", "zone"}},
+	}
+	lookup := func(key string) (Definition, bool) {
+		def, ok := defs[key]
+		return def, ok
+	}
+
+	igw := graph.InitResource("internetgateway", "igw_1")
+	igw.Properties["Vpcs"] = []string{"vpc_1"}
+	group := graph.InitResource("scalinggroup", "arn:aws:autoscaling:asg")
+	group.Properties["Name"] = "my asg"
+	defaultGroup := graph.InitResource("securitygroup", "sg_default")
+	defaultGroup.Properties["Name"] = "default"
+	mainTable := graph.InitResource("routetable", "rt_main")
+	mainTable.Properties["Main"] = true
+	resources := []*graph.Resource{
+		graph.InitResource("instance", "inst_1"),
+		graph.InitResource("keypair", "kp_1"),
+		graph.InitResource("elasticip", "eipalloc-1"),
+		graph.InitResource("record", "rec_1"),
+		graph.InitResource("region", "eu-west-1"),
+		graph.InitResource("securitygroup", "sg_1"),
+		defaultGroup,
+		graph.InitResource("routetable", "rt_1"),
+		mainTable,
+		group,
+		graph.InitResource("subnet", "sub_1"),
+		graph.InitResource("vpc", "vpc_1"),
+		igw,
+	}
+
+	tpl, skipped, err := Teardown(resources, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"delete instance id=inst_1",
+		"check instance id=inst_1 state=terminated timeout=180",
+		"delete elasticip id=eipalloc-1",
+		"check securitygroup id=sg_1 state=unused timeout=180",
+		"delete securitygroup id=sg_1",
+		"delete routetable id=rt_1",
+		"update scalinggroup max-size=0 min-size=0 name='my asg'",
+		"check scalinggroup count=0 name='my asg' timeout=180",
+		"delete scalinggroup force=true name='my asg'",
+		"delete subnet id=sub_1",
+		"detach internetgateway id=igw_1 vpc=vpc_1",
+		"delete vpc id=vpc_1",
+		"delete internetgateway id=igw_1",
+	}
+	if got, want := tpl.String(), strings.Join(exp, "\n"); got != want {
+		t.Fatalf("got\n%s\n\nwant\n%s", got, want)
+	}
+
+	var ids []string
+	for _, res := range skipped {
+		ids = append(ids, res.Id())
+	}
+	if got, want := strings.Join(ids, " "), "kp_1 rec_1 eu-west-1 sg_default rt_main"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if _, _, err := Teardown(resources[3:5], lookup); err == nil {
+		t.Fatal("expected error got none")
+	}
+}
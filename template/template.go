@@ -201,6 +201,50 @@ func (s *Template) CmdNodesReverseIterator() (nodes []*ast.CommandNode) {
 	return
 }
 
+// Date returns the date of the run of the template, encoded in its id
+func (s *Template) Date() time.Time {
+	parsed, err := ulid.Parse(s.ID)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(parsed.Time())*int64(time.Millisecond))
+}
+
+// CommandsReferencing returns the commands whose params or result reference one
+// of the given resource ids or names (ex: to correlate runs with resources changes)
+func (s *Template) CommandsReferencing(refs ...string) (commands []string) {
+	isRef := func(v interface{}) bool {
+		switch vv := v.(type) {
+		case string:
+			return containsString(refs, vv)
+		case []string:
+			for _, e := range vv {
+				if containsString(refs, e) {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, e := range vv {
+				if containsString(refs, fmt.Sprint(e)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, cmd := range s.CommandNodesIterator() {
+		referencing := isRef(cmd.CmdResult)
+		for _, v := range cmd.Params {
+			referencing = referencing || isRef(v)
+		}
+		if referencing {
+			commands = append(commands, cmd.String())
+		}
+	}
+	return
+}
+
 func (s *Template) commandDeclarationNodesIterator() (nodes []*ast.DeclarationNode) {
 	for _, node := range s.declarationNodesIterator() {
 		expr := node.Expr
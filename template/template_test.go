@@ -279,3 +279,22 @@ func (r *mockDriver) Lookup(lookups ...string) (driver.DriverFn, error) {
 
 func (r *mockDriver) SetLogger(*logger.Logger) {}
 func (r *mockDriver) SetDryRun(bool)           {}
+
+func TestCommandsReferencing(t *testing.T) {
+	tpl := MustParse("create instance subnet=sub-1 name=web\nattach volume id=vol-1 instance=i-other\ndelete securitygroup id=sg-1")
+	tpl.ID = "01BJ4FBRXQ5HR3GJYZ0XFGHRP8"
+	tpl.CommandNodesIterator()[0].CmdResult = "i-1"
+
+	if got, want := tpl.CommandsReferencing("i-1"), []string{"create instance name=web subnet=sub-1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got, want := tpl.CommandsReferencing("web", "vol-1"), []string{"create instance name=web subnet=sub-1", "attach volume id=vol-1 instance=i-other"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := tpl.CommandsReferencing("sg-2"); len(got) != 0 {
+		t.Fatalf("got %v, want none", got)
+	}
+	if got, want := tpl.Date().UTC().Format("2006-01-02"), "2017-06-08"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2017 WALLIX
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wallix/awless/logger"
+	"github.com/wallix/awless/template/driver"
+)
+
+const TestFileSuffix = "_test.awls"
+
+// TestedTemplatePath returns the path of the template tested by the given test file
+// (i.e. 'create_vpc_test.awls' tests 'create_vpc.awls')
+func TestedTemplatePath(testPath string) string {
+	return strings.TrimSuffix(testPath, TestFileSuffix) + ".awls"
+}
+
+// A TestCase declares the inputs given to a template compilation
+// and the assertions on its outcome. Nil assertions are not verified.
+//
+// The revert assertion is verified against a run with a fake driver
+// returning '<entity>-<n>' for the n-th command on a given entity.
+type TestCase struct {
+	Name     string
+	Fillers  map[string]interface{}
+	Aliases  map[string]string
+	Commands []string
+	Params   map[string]interface{}
+	Revert   []string
+}
+
+var (
+	testHeaderRegex  = regexp.MustCompile(`^\[test\s+(.+)\]$`)
+	testSectionRegex = regexp.MustCompile(`^\[(fillers|aliases|commands|params|revert)\]$`)
+)
+
+// ParseTestCases parses the content of a template test file:
+//
+//	[test create public subnet]
+//	[fillers]
+//	subnet.cidr = 10.0.0.0/24
+//	[aliases]
+//	myvpc = vpc-12345
+//	[commands]
+//	subnet = create subnet cidr=10.0.0.0/24 vpc=vpc-12345
+//	update subnet id=$subnet public=true
+//	[params]
+//	subnet.cidr = 10.0.0.0/24
+//	[revert]
+//	delete subnet id=subnet-1
+//
+// Each '[test NAME]' header starts a new test case. Sections declared before
+// any header belong to a single test case named 'default'.
+func ParseTestCases(text string) ([]*TestCase, error) {
+	var cases []*TestCase
+	var current *TestCase
+	var section string
+
+	scn := bufio.NewScanner(strings.NewReader(text))
+	for lineNum := 1; scn.Scan(); lineNum++ {
+		line := strings.TrimSpace(scn.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if match := testHeaderRegex.FindStringSubmatch(line); len(match) > 1 {
+			current = &TestCase{Name: strings.TrimSpace(match[1])}
+			cases = append(cases, current)
+			section = ""
+			continue
+		}
+
+		if current == nil {
+			current = &TestCase{Name: "default"}
+			cases = append(cases, current)
+		}
+
+		if match := testSectionRegex.FindStringSubmatch(line); len(match) > 1 {
+			section = match[1]
+			switch section {
+			case "fillers":
+				current.Fillers = make(map[string]interface{})
+			case "aliases":
+				current.Aliases = make(map[string]string)
+			case "commands":
+				current.Commands = []string{}
+			case "params":
+				current.Params = make(map[string]interface{})
+			case "revert":
+				current.Revert = []string{}
+			}
+			continue
+		}
+
+		var err error
+		switch section {
+		case "fillers":
+			err = parseTestParam(line, current.Fillers)
+		case "params":
+			err = parseTestParam(line, current.Params)
+		case "aliases":
+			splits := strings.SplitN(line, "=", 2)
+			if len(splits) != 2 {
+				err = fmt.Errorf("expected 'alias = value'")
+				break
+			}
+			current.Aliases[strings.TrimPrefix(strings.TrimSpace(splits[0]), "@")] = strings.TrimSpace(splits[1])
+		case "commands":
+			current.Commands = append(current.Commands, line)
+		case "revert":
+			current.Revert = append(current.Revert, line)
+		default:
+			err = fmt.Errorf("expected a section header ([fillers], [aliases], [commands], [params] or [revert])")
+		}
+		if err != nil {
+			return cases, fmt.Errorf("template test: line %d: %s", lineNum, err)
+		}
+	}
+
+	return cases, scn.Err()
+}
+
+func parseTestParam(line string, params map[string]interface{}) error {
+	splits := strings.SplitN(line, "=", 2)
+	if len(splits) != 2 {
+		return fmt.Errorf("expected 'key = value'")
+	}
+	parsed, err := ParseParams(fmt.Sprintf("%s=%s", strings.TrimSpace(splits[0]), strings.TrimSpace(splits[1])))
+	if err != nil {
+		return err
+	}
+	for k, v := range parsed {
+		params[k] = v
+	}
+	return nil
+}
+
+// Run compiles the template with the test case inputs and verifies its assertions.
+// Failed assertions are returned as *Errors.
+func (tc *TestCase) Run(tpl *Template, lookup DefinitionLookupFunc) error {
+	env := NewEnv()
+	env.DefLookupFunc = lookup
+	env.AddFillers(tc.Fillers)
+	env.AliasFunc = func(entity, key, alias string) string {
+		return tc.Aliases[alias]
+	}
+
+	cloned := &Template{ID: tpl.ID, AST: tpl.AST.Clone()}
+	compiled, env, err := Compile(cloned, env)
+	if err != nil {
+		return err
+	}
+
+	errs := &Errors{}
+
+	if tc.Commands != nil {
+		var got []string
+		for _, st := range compiled.Statements {
+			got = append(got, st.String())
+		}
+		want, err := normalizeTestStatements(tc.Commands)
+		if err != nil {
+			return fmt.Errorf("expected commands: %s", err)
+		}
+		if diff := diffTestLines(got, want); diff != "" {
+			errs.add(fmt.Errorf("commands:\n%s", diff))
+		}
+	}
+
+	if tc.Params != nil {
+		processed := env.GetProcessedFillers()
+		for _, k := range sortedTestKeys(tc.Params) {
+			got, ok := processed[k]
+			if !ok {
+				errs.add(fmt.Errorf("params: '%s' not resolved, want '%v'", k, tc.Params[k]))
+				continue
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.Params[k]) {
+				errs.add(fmt.Errorf("params: '%s' resolved to '%v', want '%v'", k, got, tc.Params[k]))
+			}
+		}
+		for _, k := range sortedTestKeys(processed) {
+			if _, ok := tc.Params[k]; !ok {
+				errs.add(fmt.Errorf("params: unexpected resolved '%s' to '%v'", k, processed[k]))
+			}
+		}
+	}
+
+	if tc.Revert != nil {
+		ran, err := compiled.Run(&testDriver{counts: make(map[string]int)})
+		if err != nil {
+			return fmt.Errorf("fake run: %s", err)
+		}
+		var got []string
+		if IsRevertible(ran) {
+			reverted, err := ran.Revert()
+			if err != nil {
+				return err
+			}
+			for _, st := range reverted.Statements {
+				got = append(got, st.String())
+			}
+		}
+		want, err := normalizeTestStatements(tc.Revert)
+		if err != nil {
+			return fmt.Errorf("expected revert: %s", err)
+		}
+		if diff := diffTestLines(got, want); diff != "" {
+			errs.add(fmt.Errorf("revert:\n%s", diff))
+		}
+	}
+
+	if _, any := errs.Errors(); any {
+		return errs
+	}
+	return nil
+}
+
+func normalizeTestStatements(lines []string) ([]string, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	tpl, err := Parse(strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	var normalized []string
+	for _, st := range tpl.Statements {
+		normalized = append(normalized, st.String())
+	}
+	return normalized, nil
+}
+
+func diffTestLines(got, want []string) string {
+	var diff []string
+	for i := 0; i < len(got) || i < len(want); i++ {
+		switch {
+		case i >= len(got):
+			diff = append(diff, fmt.Sprintf("\tmissing: %s", want[i]))
+		case i >= len(want):
+			diff = append(diff, fmt.Sprintf("\tunexpected: %s", got[i]))
+		case got[i] != want[i]:
+			diff = append(diff, fmt.Sprintf("\tgot:  %s\n\twant: %s", got[i], want[i]))
+		}
+	}
+	return strings.Join(diff, "\n")
+}
+
+func sortedTestKeys(m map[string]interface{}) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
+
+type testDriver struct {
+	counts map[string]int
+}
+
+func (d *testDriver) Lookup(lookups ...string) (driver.DriverFn, error) {
+	if len(lookups) < 2 {
+		return nil, driver.ErrDriverFnNotFound
+	}
+	entity := lookups[1]
+	return func(map[string]interface{}) (interface{}, error) {
+		d.counts[entity]++
+		return fmt.Sprintf("%s-%d", entity, d.counts[entity]), nil
+	}, nil
+}
+
+func (d *testDriver) SetDryRun(bool)           {}
+func (d *testDriver) SetLogger(*logger.Logger) {}
@@ -0,0 +1,95 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTestCases(t *testing.T) {
+	cases, err := ParseTestCases(`# tests for subnet template
+[test public subnet]
+[fillers]
+subnet.cidr = 10.0.2.0/24
+instance.count = 2
+[aliases]
+@myvpc = vpc-1234
+[commands]
+sub = create subnet cidr=10.0.2.0/24 vpc=vpc-1234
+update subnet id=$sub public=true
+
+[test no assertions]
+[fillers]
+subnet.cidr=10.0.3.0/24
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(cases), 2; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+	expected := &TestCase{
+		Name:     "public subnet",
+		Fillers:  map[string]interface{}{"subnet.cidr": "10.0.2.0/24", "instance.count": 2},
+		Aliases:  map[string]string{"myvpc": "vpc-1234"},
+		Commands: []string{"sub = create subnet cidr=10.0.2.0/24 vpc=vpc-1234", "update subnet id=$sub public=true"},
+	}
+	if got, want := cases[0], expected; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+	if cases[1].Commands != nil || cases[1].Params != nil || cases[1].Revert != nil {
+		t.Fatalf("unexpected assertions in %#v", cases[1])
+	}
+
+	if _, err := ParseTestCases("create subnet cidr=10.0.2.0/24"); err == nil {
+		t.Fatal("expected error for command outside section")
+	}
+}
+
+func TestRunTestCase(t *testing.T) {
+	lookup := func(in string) (Definition, bool) {
+		d, ok := DefsExample[in]
+		return d, ok
+	}
+	tpl := MustParse(`sub = create subnet cidr={subnet.cidr} vpc=@myvpc
+update subnet id=$sub public=true
+create instance subnet=$sub image=ami-12345 count=1 type={instance.type}`)
+
+	tcase := &TestCase{
+		Fillers: map[string]interface{}{"subnet.cidr": "10.0.2.0/24", "instance.type": "t2.micro"},
+		Aliases: map[string]string{"myvpc": "vpc-1234"},
+		Commands: []string{
+			"sub = create subnet vpc=vpc-1234 cidr=10.0.2.0/24",
+			"update subnet id=$sub public=true",
+			"create instance count=1 image=ami-12345 subnet=$sub type=t2.micro",
+		},
+		Params: map[string]interface{}{"subnet.cidr": "10.0.2.0/24", "instance.type": "t2.micro"},
+		Revert: []string{
+			"delete instance id=instance-1",
+			"check instance id=instance-1 state=terminated timeout=180",
+			"delete subnet id=subnet-1",
+		},
+	}
+	if err := tcase.Run(tpl, lookup); err != nil {
+		t.Fatal(err)
+	}
+
+	tcase.Commands = []string{"create subnet cidr=10.0.2.0/24 vpc=vpc-1234"}
+	tcase.Params = map[string]interface{}{"subnet.cidr": "10.0.3.0/24"}
+	err := tcase.Run(tpl, lookup)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	errs, _ := err.(*Errors).Errors()
+	if got, want := len(errs), 3; got != want {
+		t.Fatalf("got %d, want %d: %s", got, want, err)
+	}
+	if !strings.Contains(errs[0].Error(), "unexpected: update subnet id=$sub public=true") {
+		t.Fatalf("unexpected commands error: %s", errs[0])
+	}
+
+	tcase = &TestCase{Fillers: map[string]interface{}{"subnet.cidr": "10.0.2.0/24"}, Aliases: map[string]string{"myvpc": "vpc-1234"}}
+	if err := tcase.Run(tpl, lookup); err == nil || !strings.Contains(err.Error(), "unresolved holes") {
+		t.Fatalf("expected unresolved holes error, got %v", err)
+	}
+}